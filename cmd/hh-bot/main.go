@@ -4,15 +4,30 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"hh-ru-auto-resume-raising/internal/bot"
 	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
 	"hh-ru-auto-resume-raising/internal/scheduler"
 	"hh-ru-auto-resume-raising/internal/storage"
 	"hh-ru-auto-resume-raising/pkg/config"
 )
 
+// runtime - клиенты hh.ru и планировщик одного пользователя бота
+type runtime struct {
+	cfg     *config.Config
+	store   *storage.Storage
+	clients map[string]*hh.Client
+	sched   *scheduler.Scheduler
+}
+
+var (
+	runtimes      []*runtime
+	runtimesMutex sync.Mutex
+)
+
 func main() {
 	log.Println("Starting HH.ru auto resume raising bot...")
 
@@ -25,58 +40,41 @@ func main() {
 		log.Fatal("Failed to initialize storage:", err)
 	}
 
-	// Создаем HH клиент
-	hhClient, err := hh.NewClient(cfg.HHLogin, cfg.HHPassword, cfg.Proxy)
-	if err != nil {
-		log.Fatal("Failed to create HH client:", err)
-	}
-
-	// Загружаем токены
-	if tokens, err := store.LoadTokens(); err == nil && tokens.XSRF != "" && tokens.HHToken != "" {
-		hhClient.SetTokens(tokens.XSRF, tokens.HHToken)
-		log.Println("Loaded existing tokens")
-	} else {
-		log.Println("No existing tokens found")
-	}
-
-	// Создаем планировщик
-	sched := scheduler.New(hhClient, cfg.Timezone)
+	// stopBot останавливает получение обновлений при завершении
+	var stopBot func()
 
-	// Загружаем расписание
-	if schedules, err := store.LoadSchedule(); err == nil {
-		for title, schedule := range schedules {
-			sched.AddResume(title, schedule.ResumeID, schedule.Hour, schedule.Minute)
+	if cfg.MultiUser {
+		// Многопользовательский режим: у каждого пользователя свои аккаунт, планировщик и хранилище
+		hub, err := bot.NewHub(cfg, store, newRuntime)
+		if err != nil {
+			log.Fatal("Failed to create bot:", err)
 		}
-		log.Printf("Loaded %d resume schedules", len(schedules))
-	}
 
-	// Создаем бота
-	telegramBot, err := bot.New(cfg, hhClient, sched, store)
-	if err != nil {
-		log.Fatal("Failed to create bot:", err)
-	}
+		// Бот перезапускается при сбое, а не роняет процесс вместе с планировщиками
+		go bot.Supervise("bot", hub.Start, hub.Alert)
+		stopBot = hub.Stop
+	} else {
+		clients, sched, _, err := newRuntime(cfg, store)
+		if err != nil {
+			log.Fatal("Failed to create HH client:", err)
+		}
 
-	// Устанавливаем обработчик уведомлений
-	sched.SetNotificationHandler(telegramBot.SendNotification)
+		// Создаем бота
+		telegramBot, err := bot.New(cfg, clients, sched, store)
+		if err != nil {
+			log.Fatal("Failed to create bot:", err)
+		}
 
-	// Запускаем планировщик
-	sched.Start()
-	defer sched.Stop()
+		// Рассылаем события планировщика в Telegram и каналы из конфигурации
+		sched.SetNotificationHandler(notify.FromConfig(cfg.Notify, telegramBot, store).Publish)
 
-	// Сохраняем токены при их обновлении (устанавливаем хук)
-	go func() {
-		for {
-			// Можно добавить периодическое сохранение состояния
-			// или реагировать на изменения
-		}
-	}()
+		// Запускаем планировщик
+		sched.Start()
 
-	// Запускаем бота в отдельной горутине
-	go func() {
-		if err := telegramBot.Start(); err != nil {
-			log.Fatal("Bot error:", err)
-		}
-	}()
+		// Запускаем бота в отдельной горутине; при сбое он перезапускается
+		go bot.Supervise("bot", telegramBot.Start, telegramBot.Alert)
+		stopBot = telegramBot.Stop
+	}
 
 	// Graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -86,16 +84,154 @@ func main() {
 	<-c
 	log.Println("Shutting down...")
 
+	// Дожидаемся обработки уже полученных сообщений
+	stopBot()
+
 	// Сохраняем текущее состояние перед выходом
-	if xsrf, hhtoken := hhClient.GetTokens(); xsrf != "" && hhtoken != "" {
-		if err := store.SaveTokens(xsrf, hhtoken); err != nil {
-			log.Printf("Failed to save tokens: %v", err)
+	runtimesMutex.Lock()
+	for _, rt := range runtimes {
+		rt.sched.Stop()
+
+		for _, account := range rt.cfg.Accounts {
+			if xsrf, hhtoken := rt.clients[account.Name].GetTokens(); xsrf != "" && hhtoken != "" {
+				if err := rt.store.SaveTokens(rt.cfg.SchedulerAccount(account.Name), xsrf, hhtoken, rt.clients[account.Name].LoginTime()); err != nil {
+					log.Printf("Failed to save tokens for account %s: %v", account.Name, err)
+				}
+			}
+		}
+
+		saveState(rt.store, rt.sched)
+	}
+	runtimesMutex.Unlock()
+
+	log.Println("Bot stopped")
+}
+
+// newRuntime создает клиенты hh.ru и планировщик для конфигурации и восстанавливает
+// сохраненное в store состояние. Планировщик запускает вызывающий код; release
+// убирает среду из сохраняемых при завершении (пользователь отключен).
+func newRuntime(cfg *config.Config, store *storage.Storage) (map[string]*hh.Client, *scheduler.Scheduler, func(), error) {
+	// Создаем HH клиенты для всех аккаунтов и загружаем их токены
+	clients := make(map[string]*hh.Client)
+	for _, account := range cfg.Accounts {
+		hhClient, err := hh.NewClient(account.Login, account.Password, account.Proxy)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if tokens, err := store.LoadTokens(cfg.SchedulerAccount(account.Name)); err == nil && tokens.XSRF != "" && tokens.HHToken != "" {
+			hhClient.SetTokens(tokens.XSRF, tokens.HHToken)
+			hhClient.SetLoginTime(tokens.LoggedInAt)
+			log.Printf("Loaded existing tokens for account %s", account.Name)
+		} else {
+			log.Printf("No existing tokens found for account %s", account.Name)
 		}
+		clients[account.Name] = hhClient
 	}
 
+	// Создаем планировщик; аккаунт по умолчанию - первый в списке
+	sched := scheduler.New(clients[cfg.Accounts[0].Name], cfg.Timezone)
+	for _, account := range cfg.Accounts[1:] {
+		sched.AddAccount(account.Name, clients[account.Name])
+	}
+	sched.SetRetryPolicy(scheduler.RetryPolicy{
+		MaxAttempts:  cfg.RetryMaxAttempts,
+		InitialDelay: cfg.RetryInitialDelay,
+		MaxDelay:     cfg.RetryMaxDelay,
+		Multiplier:   2,
+	})
+	sched.SetSessionPolicy(scheduler.SessionPolicy{
+		CheckInterval: cfg.SessionCheckInterval,
+		MaxAge:        cfg.SessionMaxAge,
+		Prewarm:       cfg.SessionPrewarm,
+	})
+
+	// Сохраняем токены, полученные при повторном входе планировщика
+	sched.SetSessionHandler(func(account string, hhClient *hh.Client) {
+		xsrf, hhtoken := hhClient.GetTokens()
+		if err := store.SaveTokens(account, xsrf, hhtoken, hhClient.LoginTime()); err != nil {
+			log.Printf("Failed to save tokens for account %q: %v", account, err)
+		}
+	})
+
+	// Записываем каждую попытку подъема в журнал истории
+	sched.SetHistoryHandler(func(attempt scheduler.RaiseAttempt) {
+		if err := store.AppendHistory(attempt); err != nil {
+			log.Printf("Failed to append raise history: %v", err)
+		}
+	})
+
+	// Загружаем расписание
+	if schedules, err := store.LoadSchedule(); err == nil {
+		for title, schedule := range schedules {
+			sched.Restore(title, schedule)
+		}
+		log.Printf("Loaded %d resume schedules", len(schedules))
+	}
+
+	// Загружаем разовые подъемы
+	if oneOffs, err := store.LoadOneOffs(); err == nil {
+		for _, oneOff := range oneOffs {
+			sched.RestoreOneOff(oneOff)
+		}
+		log.Printf("Loaded %d one-off raises", len(oneOffs))
+	}
+
+	// Восстанавливаем глобальную паузу (режим отпуска) и настройки уведомлений
+	if settings, err := store.LoadSettings(); err == nil {
+		if settings.Paused {
+			sched.PauseAll(settings.PausedUntil)
+			log.Println("Scheduler is paused")
+		}
+		if settings.Notifications != nil {
+			sched.SetNotifySettings(*settings.Notifications)
+		}
+	}
+
+	// Сохраняем состояние, когда планировщик сам его меняет
+	sched.SetSaveHandler(func() {
+		saveState(store, sched)
+	})
+
+	rt := &runtime{cfg: cfg, store: store, clients: clients, sched: sched}
+	runtimesMutex.Lock()
+	runtimes = append(runtimes, rt)
+	runtimesMutex.Unlock()
+
+	release := func() {
+		runtimesMutex.Lock()
+		defer runtimesMutex.Unlock()
+		for i := range runtimes {
+			if runtimes[i] == rt {
+				runtimes = append(runtimes[:i], runtimes[i+1:]...)
+				return
+			}
+		}
+	}
+	return clients, sched, release, nil
+}
+
+var saveMutex sync.Mutex
+
+// saveState сохраняет расписание, разовые подъемы, состояние паузы и настройки уведомлений
+func saveState(store *storage.Storage, sched *scheduler.Scheduler) {
+	saveMutex.Lock()
+	defer saveMutex.Unlock()
+
 	if err := store.SaveSchedule(sched.GetAll()); err != nil {
 		log.Printf("Failed to save schedule: %v", err)
 	}
 
-	log.Println("Bot stopped")
-}
\ No newline at end of file
+	if err := store.SaveOneOffs(sched.GetOneOffs()); err != nil {
+		log.Printf("Failed to save one-off raises: %v", err)
+	}
+
+	err := store.UpdateSettings(func(settings *storage.Settings) {
+		settings.Paused, settings.PausedUntil = sched.GetPause()
+		notifySettings := sched.GetNotifySettings()
+		settings.Notifications = &notifySettings
+	})
+	if err != nil {
+		log.Printf("Failed to save settings: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+// telegram-fake запускает имитацию Telegram Bot API для проверки бота без Telegram.
+// Бот запускается с TELEGRAM_API_URL, указывающим на имитацию, а сценарий разговора
+// управляется запросами /fake/... (см. internal/telegramfake).
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"hh-ru-auto-resume-raising/internal/telegramfake"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:8081", "address of the fake Bot API server")
+	flag.Parse()
+
+	server, err := telegramfake.Start(*listen)
+	if err != nil {
+		log.Fatal("Failed to start fake Telegram server:", err)
+	}
+	log.Printf("Fake Telegram Bot API is listening on %s", server.URL())
+	log.Printf("Run the bot with TELEGRAM_API_URL=%s TELEGRAM_TOKEN=%s", server.URL(), telegramfake.Token)
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+
+	if err := server.Close(); err != nil {
+		log.Printf("Failed to stop fake Telegram server: %v", err)
+	}
+}
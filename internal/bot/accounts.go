@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+	"hh-ru-auto-resume-raising/internal/storage"
+)
+
+// Кэш резюме избавляет экраны бота от запроса к hh.ru на каждое нажатие
+const (
+	resumeCacheTTL      = 5 * time.Minute
+	resumeCacheErrorTTL = 30 * time.Second
+	// Кэш обновляется в фоне, пока ботом пользовались в последние resumeRefreshIdle
+	resumeRefreshInterval = time.Minute
+	resumeRefreshIdle     = 30 * time.Minute
+)
+
+// activeAccountName возвращает имя активного аккаунта из конфигурации
+func (b *Bot) activeAccountName() string {
+	b.accountMutex.RLock()
+	defer b.accountMutex.RUnlock()
+	return b.activeAccount
+}
+
+// client возвращает клиент hh.ru активного аккаунта
+func (b *Bot) client() *hh.Client {
+	return b.clients[b.activeAccountName()]
+}
+
+// resumeCache возвращает кэш резюме активного аккаунта
+func (b *Bot) resumeCache() *hh.ResumeCache {
+	return b.resumeCaches[b.activeAccountName()]
+}
+
+// resumes возвращает резюме активного аккаунта из кэша
+func (b *Bot) resumes() ([]hh.Resume, error) {
+	return b.resumeCache().Resumes()
+}
+
+// authorized сообщает, действует ли сессия активного аккаунта (по кэшу резюме)
+func (b *Bot) authorized() bool {
+	return b.resumeCache().Authorized()
+}
+
+// account возвращает имя активного аккаунта в терминах планировщика и хранилища
+func (b *Bot) account() string {
+	return b.config.SchedulerAccount(b.activeAccountName())
+}
+
+// accountName возвращает имя аккаунта в конфигурации по имени в терминах планировщика
+func (b *Bot) accountName(account string) string {
+	if account == "" {
+		return b.config.Accounts[0].Name
+	}
+	return account
+}
+
+// scheduleKey возвращает ключ расписания резюме активного аккаунта
+func (b *Bot) scheduleKey(title string) string {
+	return scheduler.ScheduleKey(b.account(), title)
+}
+
+// accountSchedules возвращает расписания резюме аккаунта
+func (b *Bot) accountSchedules(account string) map[string]scheduler.ResumeSchedule {
+	result := make(map[string]scheduler.ResumeSchedule)
+	for title, schedule := range b.scheduler.GetAll() {
+		if schedule.Account == account {
+			result[title] = schedule
+		}
+	}
+	return result
+}
+
+// handleAccounts показывает аккаунты hh.ru, их статус и позволяет выбрать активный
+func (b *Bot) handleAccounts(chatID int64, editMessageID ...int) {
+	text := fmt.Sprintf("👥 <b>Аккаунты HeadHunter (%d)</b>\n\n", len(b.config.Accounts))
+
+	active := b.activeAccountName()
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, account := range b.config.Accounts {
+		marker := "▫️"
+		if account.Name == active {
+			marker = "👉"
+		}
+
+		authStatus := "❌ не авторизован"
+		if b.resumeCaches[account.Name].Authorized() {
+			authStatus = "✅ авторизован"
+		}
+
+		schedules := b.accountSchedules(b.config.SchedulerAccount(account.Name))
+		text += fmt.Sprintf("%s <b>%s</b> - <code>%s</code>\n", marker, account.Name, account.Login)
+		text += fmt.Sprintf("   🔐 %s\n", authStatus)
+		text += fmt.Sprintf("   📅 Автоподъемов: %d", len(schedules))
+		if paused := countPaused(schedules); paused > 0 {
+			text += fmt.Sprintf(" (на паузе: %d)", paused)
+		}
+		text += "\n\n"
+
+		label := account.Name
+		if account.Name == active {
+			label = "✅ " + label
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "account:"+account.Name),
+		))
+	}
+	text += "💡 <i>Резюме, автоподъем и авторизация в меню относятся к активному аккаунту. "
+	text += "Расписания всех аккаунтов работают одновременно</i>"
+
+	b.sendOrEdit(chatID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...), editMessageID...)
+}
+
+// handleAccountCallback делает выбранный аккаунт активным
+func (b *Bot) handleAccountCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	name := strings.TrimPrefix(callback.Data, "account:")
+
+	if _, exists := b.clients[name]; !exists {
+		msg := tgbotapi.NewMessage(chatID, "Аккаунт не найден")
+		b.send(msg)
+		return
+	}
+
+	if name != b.activeAccountName() {
+		b.accountMutex.Lock()
+		b.activeAccount = name
+		b.accountMutex.Unlock()
+		// Незавершенный ввод относился к прежнему аккаунту
+		b.dialogs.Clear(chatID)
+		b.saveActiveAccount(name)
+	}
+
+	b.handleAccounts(chatID, callback.Message.MessageID)
+	b.sendMainMenu(chatID)
+}
+
+func (b *Bot) saveActiveAccount(name string) {
+	err := b.storage.UpdateSettings(func(settings *storage.Settings) {
+		settings.ActiveAccount = name
+	})
+	if err != nil {
+		log.Printf("Failed to save settings: %v", err)
+	}
+}
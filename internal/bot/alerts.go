@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// alertPauseDuration - на сколько кнопка уведомления приостанавливает автоподъем резюме
+const alertPauseDuration = 24 * time.Hour
+
+// actionable сообщает, нужны ли событию кнопки: неудачный подъем по расписанию
+// или тревожный ответ hh.ru
+func actionable(event notify.Event) bool {
+	if event.ResumeID == "" {
+		return false
+	}
+	switch event.Type {
+	case notify.EventRaiseRetry, notify.EventRaiseFailed:
+		return true
+	case notify.EventResumeStatusChanged:
+		return event.Severity >= notify.SeverityWarning
+	}
+	return false
+}
+
+// alertKeyboard возвращает кнопки уведомления о проблеме с резюме
+func alertKeyboard(resumeID string) [][]tgbotapi.InlineKeyboardButton {
+	return [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Повторить сейчас", "alert_raise:"+resumeID),
+			tgbotapi.NewInlineKeyboardButtonData("⏸ Пауза на сутки", "alert_pause:"+resumeID),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔐 Переавторизоваться", "alert_relogin:"+resumeID),
+			tgbotapi.NewInlineKeyboardButtonURL("🌐 Открыть резюме", "https://hh.ru/resume/"+resumeID),
+		),
+	}
+}
+
+// splitAlerts отделяет события с кнопками и группирует их по резюме в порядке появления
+func splitAlerts(events []notify.Event) (alerts [][]notify.Event, rest []notify.Event) {
+	index := make(map[string]int)
+	for _, event := range events {
+		if !actionable(event) {
+			rest = append(rest, event)
+			continue
+		}
+		i, exists := index[event.ResumeID]
+		if !exists {
+			i = len(alerts)
+			index[event.ResumeID] = i
+			alerts = append(alerts, nil)
+		}
+		alerts[i] = append(alerts[i], event)
+	}
+	return alerts, rest
+}
+
+// handleAlertCallback выполняет действие кнопки из уведомления о проблеме с резюме
+func (b *Bot) handleAlertCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	action, resumeID, _ := strings.Cut(callback.Data, ":")
+
+	key, schedule, found := b.scheduleByResumeID(resumeID)
+	if !found {
+		b.send(newHTMLMessage(chatID, "Резюме больше нет в расписании"))
+		return
+	}
+
+	switch action {
+	case "alert_raise":
+		text := fmt.Sprintf("🚀 <b>Поднимаем резюме...</b>\n\n<code>%s</code>", key)
+		placeholder, err := b.send(newHTMLMessage(chatID, text))
+		if err != nil {
+			return
+		}
+
+		text = fmt.Sprintf("📄 <b>%s</b>\n%s", key, b.raiseText(schedule.Account, key, resumeID))
+		b.showResult(chatID, placeholder.MessageID, text)
+	case "alert_pause":
+		until := time.Now().Add(alertPauseDuration)
+		if b.scheduler.PauseResume(key, until) {
+			b.saveState()
+		}
+		b.send(newHTMLMessage(chatID, fmt.Sprintf("⏸ Автоподъем <code>%s</code> приостановлен %s", key, pauseStatusText(until))))
+	case "alert_relogin":
+		b.reloginAccount(chatID, schedule.Account)
+	}
+}
+
+// reloginAccount входит в аккаунт заново и сообщает результат
+func (b *Bot) reloginAccount(chatID int64, account string) {
+	name := b.accountName(account)
+	placeholder, err := b.send(newHTMLMessage(chatID, fmt.Sprintf("🔄 <b>Входим в аккаунт %s...</b>", name)))
+	if err != nil {
+		return
+	}
+
+	err = b.scheduler.Relogin(account)
+	b.resumeCaches[name].Invalidate()
+
+	text := fmt.Sprintf("✅ <b>Вход в аккаунт %s выполнен</b>", name)
+	if err != nil {
+		log.Printf("Manual re-login for account %q failed: %v", account, err)
+		text = fmt.Sprintf("❌ <b>Не удалось войти в аккаунт %s</b>\n\n%s", name, escapeHTML(err.Error()))
+	}
+	b.showResult(chatID, placeholder.MessageID, text)
+}
+
+// scheduleByResumeID ищет расписание резюме любого аккаунта по ID резюме
+func (b *Bot) scheduleByResumeID(resumeID string) (string, scheduler.ResumeSchedule, bool) {
+	for key, schedule := range b.scheduler.GetAll() {
+		if schedule.ResumeID == resumeID {
+			return key, schedule, true
+		}
+	}
+	return "", scheduler.ResumeSchedule{}, false
+}
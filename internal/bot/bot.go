@@ -1,135 +1,226 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/digest"
 	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/outbox"
 	"hh-ru-auto-resume-raising/internal/scheduler"
 	"hh-ru-auto-resume-raising/internal/storage"
 	"hh-ru-auto-resume-raising/pkg/config"
 )
 
-type UserState struct {
-	State string
-	Data  map[string]string
-}
-
 type Bot struct {
-	api        *tgbotapi.BotAPI
-	config     *config.Config
-	hhClient   *hh.Client
-	scheduler  *scheduler.Scheduler
-	storage    *storage.Storage
-	userStates map[int64]*UserState
+	api           *outbox.Outbox
+	config        *config.Config
+	clients       map[string]*hh.Client
+	resumeCaches  map[string]*hh.ResumeCache
+	activeAccount string
+	accountMutex  sync.RWMutex
+	scheduler     *scheduler.Scheduler
+	storage       *storage.Storage
+	dialogs       *dialog.Manager
+	loop          *updateLoop
+
+	// Уведомления Telegram: пакет событий текущей минуты и события, отложенные до конца тихих часов
+	notifyMutex    sync.Mutex
+	notifyBatch    []notify.Event
+	notifyTimer    *time.Timer
+	notifyDeferred []notify.Event
+	notifyStop     chan struct{}
+
+	// Расписание и шаблон сводок
+	digest      digest.Settings
+	digestMutex sync.Mutex
+
+	// Живой статус: закрепленное сообщение в каждом чате, которое редактируется вместо
+	// отправки уведомлений; тексты - последние отправленные, чтобы не править без изменений
+	dashboard       storage.Dashboard
+	dashboardTexts  map[int64]string
+	dashboardEvents []notify.Event
+	dashboardTimer  *time.Timer
+	dashboardMutex  sync.Mutex
 }
 
-func New(cfg *config.Config, hhClient *hh.Client, sched *scheduler.Scheduler, store *storage.Storage) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
+// New создает бота; clients - клиенты hh.ru по именам аккаунтов из конфигурации
+func New(cfg *config.Config, clients map[string]*hh.Client, sched *scheduler.Scheduler, store *storage.Storage) (*Bot, error) {
+	api, err := newBotAPI(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create bot: %w", err)
 	}
+	b := newBot(outbox.New(api, store), cfg, clients, sched, store)
+	b.loop = newUpdateLoop(api, cfg.Telegram.Webhook, newDispatcher(b.handleUpdate, b.reportPanic))
+	return b, nil
+}
+
+// newBot создает бота поверх готового подключения к Telegram API.
+// В многопользовательском режиме так создается бот каждого пользователя.
+func newBot(api *outbox.Outbox, cfg *config.Config, clients map[string]*hh.Client, sched *scheduler.Scheduler, store *storage.Storage) *Bot {
+	// Восстанавливаем выбранный аккаунт, если он все еще есть в конфигурации, настройки сводок
+	// и сообщения живого статуса
+	activeAccount := cfg.Accounts[0].Name
+	var digestSettings digest.Settings
+	var dashboard storage.Dashboard
+	if settings, err := store.LoadSettings(); err == nil {
+		if _, exists := clients[settings.ActiveAccount]; exists {
+			activeAccount = settings.ActiveAccount
+		}
+		if settings.Digest != nil {
+			digestSettings = *settings.Digest
+		}
+		if settings.Dashboard != nil {
+			dashboard = *settings.Dashboard
+		}
+	}
+
+	deferred, err := store.LoadDeferredNotifications()
+	if err != nil {
+		log.Printf("Failed to load deferred notifications: %v", err)
+	}
+
+	resumeCaches := make(map[string]*hh.ResumeCache, len(clients))
+	for name, client := range clients {
+		resumeCaches[name] = hh.NewResumeCache(client, resumeCacheTTL, resumeCacheErrorTTL)
+	}
 
 	return &Bot{
-		api:        api,
-		config:     cfg,
-		hhClient:   hhClient,
-		scheduler:  sched,
-		storage:    store,
-		userStates: make(map[int64]*UserState),
-	}, nil
+		api:           api,
+		config:        cfg,
+		clients:       clients,
+		resumeCaches:  resumeCaches,
+		activeAccount: activeAccount,
+		scheduler:     sched,
+		storage:       store,
+		dialogs:       newDialogs(store),
+
+		notifyDeferred: deferred,
+		digest:         digestSettings,
+		dashboard:      dashboard,
+		dashboardTexts: make(map[int64]string),
+	}
 }
 
 func (b *Bot) Start() error {
-	log.Printf("Authorized on account %s", b.api.Self.UserName)
+	log.Printf("Authorized on account %s", b.api.Username())
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := b.api.GetUpdatesChan(u)
+	registerDefaultCommands(b.api)
+	b.registerCommands()
+	b.startBackground()
 
-	for update := range updates {
-		if update.Message != nil {
-			b.handleMessage(update.Message)
-		} else if update.CallbackQuery != nil {
-			b.handleCallbackQuery(update.CallbackQuery)
-		}
+	return b.loop.run()
+}
+
+// Stop прекращает получение обновлений и ждет обработки уже полученных
+func (b *Bot) Stop() {
+	b.loop.stop()
+	b.stopBackground()
+}
+
+// startBackground запускает фоновые задачи бота: сброс просроченных диалогов,
+// обновление кэша резюме, сводку после тихих часов и сводки по расписанию
+func (b *Bot) startBackground() {
+	b.startDialogExpiry()
+	b.startNotifications()
+	for _, cache := range b.resumeCaches {
+		cache.StartRefresher(resumeRefreshInterval, resumeRefreshIdle)
+	}
+}
+
+func (b *Bot) stopBackground() {
+	b.dialogs.Stop()
+	b.stopNotifications()
+	for _, cache := range b.resumeCaches {
+		cache.Stop()
+	}
+}
+
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil {
+		b.handleMessage(update.Message)
+	} else if update.CallbackQuery != nil {
+		b.handleCallbackQuery(update.CallbackQuery)
+	}
+}
+
+// reportPanic сообщает пользователю о сбое, а администратору - подробности
+func (b *Bot) reportPanic(chatID int64, recovered interface{}, _ []byte) {
+	if chatID != 0 {
+		b.enqueue(tgbotapi.NewMessage(chatID, "❌ Что-то пошло не так, попробуйте еще раз."))
+	}
+	b.Alert(panicReport(chatID, recovered))
+}
+
+// Alert отправляет служебное сообщение администратору через очередь
+func (b *Bot) Alert(text string) {
+	b.enqueue(newHTMLMessage(b.config.AdminTG, text))
+}
+
+// send отправляет ответ пользователю сразу. Ответ не ставится в очередь: если Telegram
+// недоступен, ошибка записывается в журнал, а сообщение видно в /outbox.
+func (b *Bot) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	message, err := b.api.Send(c)
+	if err != nil {
+		log.Printf("Failed to send Telegram message: %v", err)
+	}
+	return message, err
+}
+
+// request выполняет запрос к Telegram без результата (ответ на кнопку, удаление сообщения)
+func (b *Bot) request(c tgbotapi.Chattable) {
+	if _, err := b.api.Request(c); err != nil {
+		log.Printf("Telegram request failed: %v", err)
 	}
+}
 
-	return nil
+// enqueue ставит в очередь сообщение, которое должно дойти, даже если Telegram сейчас недоступен
+func (b *Bot) enqueue(message tgbotapi.MessageConfig) {
+	if err := b.api.Enqueue(message); err != nil {
+		log.Printf("Failed to queue message for chat %d: %v", message.ChatID, err)
+	}
 }
 
 // getAuthStatus возвращает текст кнопки авторизации в зависимости от текущего статуса
 func (b *Bot) getAuthStatus() string {
-	// Проверяем авторизацию через попытку получить резюме
-	_, err := b.hhClient.GetResumes()
-	if err != nil {
+	if !b.authorized() {
 		return "🔐 Войти в HeadHunter"
 	}
 	return "✅ Авторизован"
 }
 
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
-	if message.From.ID != b.config.AdminTG {
+	role := b.config.RoleOf(message.From.ID)
+	if role == config.RoleNone {
 		return
 	}
 
-	userID := message.Chat.ID
-
-	// Проверяем, есть ли активное состояние у пользователя
-	if state, exists := b.userStates[userID]; exists {
-		b.handleState(message, state)
-		return
+	// Проверяем, есть ли у пользователя незавершенный диалог
+	current, err := b.dialogs.Get(message.Chat.ID)
+	switch {
+	case errors.Is(err, dialog.ErrExpired):
+		b.sendDialogExpired(message.Chat.ID)
+	case err == nil:
+		if b.handleDialog(message, current) {
+			return
+		}
 	}
 
-	// Обычная обработка команд
-	switch message.Text {
-	case "/start":
-		b.sendMainMenu(message.Chat.ID)
-	case "⚙ Настройки":
-		b.handleSettingsMenu(message.Chat.ID)
-	case "ℹ️ Помощь":
-		b.handleHelp(message.Chat.ID)
-	case "🔔 Уведомления":
-		b.handleToggleNotifications(message.Chat.ID)
-	case "👤 Профиль":
-		b.handleProfile(message.Chat.ID)
-	case "↩️ Главное меню":
-		b.sendMainMenu(message.Chat.ID)
-	case "📜 Мои резюме":
-		b.handleListResumes(message.Chat.ID)
-	case "📅 Расписание":
-		b.handleShowSchedule(message.Chat.ID)
-	case "➕ Настроить подъем":
-		b.handleAddResumeWithMessage(message)
-	case "❌ Удалить из расписания":
-		b.handleDeleteResumeWithMessage(message)
-	case "🔐 Войти в HeadHunter", "✅ Авторизован":
-		b.handleAuth(message.Chat.ID)
-	case "🔄 Обновить данные":
-		b.handleUpdateResumes(message.Chat.ID)
-	// Поддержка старых команд для обратной совместимости
-	case "🔔 Вкл/выкл уведомления":
-		b.handleToggleNotifications(message.Chat.ID)
-	case "📜 Список резюме":
-		b.handleListResumes(message.Chat.ID)
-	case "➕ Добавить/обновить":
-		b.handleAddResumeWithMessage(message)
-	case "❌ Удалить":
-		b.handleDeleteResumeWithMessage(message)
-	case "🚀️ Авторизоваться":
-		b.handleAuth(message.Chat.ID)
-	case "📝 Обновить список резюме":
-		b.handleUpdateResumes(message.Chat.ID)
-	default:
-		b.sendMainMenu(message.Chat.ID)
-	}
+	// Команды и кнопки меню
+	b.routeCommand(message, role)
 }
 
 func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
-	if callback.From.ID != b.config.AdminTG {
+	if !b.config.RoleOf(callback.From.ID).Allows(callbackRole(callback.Data)) {
+		b.answerForbidden(callback)
 		return
 	}
 
@@ -141,7 +232,61 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 	case callback.Data == "schedule":
 		b.handleShowSchedule(callback.Message.Chat.ID)
 	case callback.Data == "toggle_notifications":
-		b.handleToggleNotifications(callback.Message.Chat.ID)
+		b.handleNotificationSettings(callback.Message.Chat.ID)
+	case strings.HasPrefix(callback.Data, "notify_"):
+		b.handleNotifyCallback(callback)
+	case strings.HasPrefix(callback.Data, "digest"):
+		b.handleDigestCallback(callback)
+	case callback.Data == "history":
+		b.handleHistory(callback.Message.Chat.ID, callback.Message.MessageID)
+	case strings.HasPrefix(callback.Data, "history:"):
+		b.handleHistoryCallback(callback)
+	case strings.HasPrefix(callback.Data, "dialog:"):
+		b.handleDialogCallback(callback)
+	case strings.HasPrefix(callback.Data, "account:"):
+		b.handleAccountCallback(callback)
+	case callback.Data == "vacation":
+		b.handleVacation(callback)
+	case callback.Data == "unpause_all":
+		b.handleUnpauseAll(callback)
+	case callback.Data == "cancel_vacation":
+		b.handleCancelVacation(callback)
+	case strings.HasPrefix(callback.Data, "pause_all:"):
+		b.handlePauseAllCallback(callback)
+	case strings.HasPrefix(callback.Data, "pause:"):
+		b.handlePauseCallback(callback)
+	case strings.HasPrefix(callback.Data, "unpause:"):
+		b.handleUnpauseCallback(callback)
+	case callback.Data == "raise_all":
+		b.handleRaiseAll(callback)
+	case strings.HasPrefix(callback.Data, "raise_now:"):
+		b.handleRaiseNowCallback(callback)
+	case strings.HasPrefix(callback.Data, "alert_"):
+		b.handleAlertCallback(callback)
+	case callback.Data == "oneoff":
+		b.handleOneOff(callback)
+	case callback.Data == "cancel_oneoff":
+		b.handleCancelOneOff(callback)
+	case strings.HasPrefix(callback.Data, "oneoff_resume:"):
+		b.handleOneOffResumeCallback(callback)
+	case strings.HasPrefix(callback.Data, "oneoff_delete:"):
+		b.handleOneOffDeleteCallback(callback)
+	case callback.Data == "schedule_edit":
+		b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+	case strings.HasPrefix(callback.Data, "edit:"):
+		b.handleEditCallback(callback)
+	case strings.HasPrefix(callback.Data, "edit_time:"):
+		b.handleEditTimeCallback(callback)
+	case strings.HasPrefix(callback.Data, "edit_windows:"):
+		b.handleEditWindowsCallback(callback)
+	case strings.HasPrefix(callback.Data, "edit_interval:"):
+		b.handleEditIntervalCallback(callback)
+	case strings.HasPrefix(callback.Data, "edit_jitter:"):
+		b.handleEditJitterCallback(callback)
+	case strings.HasPrefix(callback.Data, "edit_retry:"):
+		b.handleEditRetryCallback(callback)
+	case strings.HasPrefix(callback.Data, "tp:"):
+		b.handleTimePickerCallback(callback)
 	case callback.Data == "cancel_add_resume":
 		b.handleCancelAddResume(callback)
 	case callback.Data == "cancel_delete_resume":
@@ -152,61 +297,74 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		b.handleDeleteResumeCallback(callback)
 	}
 
-	b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+	b.request(tgbotapi.NewCallback(callback.ID, ""))
 }
 
 func (b *Bot) sendMainMenu(chatID int64) {
 	// Проверяем статус авторизации для динамической адаптации кнопок
 	authStatus := b.getAuthStatus()
 	
-	var keyboard tgbotapi.ReplyKeyboardMarkup
+	role := b.roleOf(chatID)
+	var rows [][]tgbotapi.KeyboardButton
 	
 	// Если не авторизован - показываем упрощенное меню с фокусом на авторизацию
 	if authStatus == "🔐 Войти в HeadHunter" {
-		keyboard = tgbotapi.NewReplyKeyboard(
-			// Ряд 1: Приоритет авторизации
-			tgbotapi.NewKeyboardButtonRow(
+		// Ряд 1: Приоритет авторизации (только владелец вводит учетные данные)
+		if role.Allows(config.RoleOwner) {
+			rows = append(rows, tgbotapi.NewKeyboardButtonRow(
 				tgbotapi.NewKeyboardButton("🔐 Войти в HeadHunter"),
-			),
-			// Ряд 2: Базовая информация
-			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton("⚙ Настройки"),
-				tgbotapi.NewKeyboardButton("ℹ️ Помощь"),
-			),
-		)
+			))
+		}
+		// Ряд 2: Базовая информация
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("⚙ Настройки"),
+			tgbotapi.NewKeyboardButton("ℹ️ Помощь"),
+		))
 	} else {
-		// Полное меню для авторизованных пользователей
-		keyboard = tgbotapi.NewReplyKeyboard(
-			// Ряд 1: Статус авторизации (успешно)
-			tgbotapi.NewKeyboardButtonRow(
+		// Полное меню для авторизованных пользователей, урезанное по роли
+		// Ряд 1: Статус авторизации (успешно)
+		if role.Allows(config.RoleOwner) {
+			rows = append(rows, tgbotapi.NewKeyboardButtonRow(
 				tgbotapi.NewKeyboardButton("✅ Авторизован"),
-			),
-			// Ряд 2: Основные операции с резюме (наиболее частые действия)
-			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton("📜 Мои резюме"),
-				tgbotapi.NewKeyboardButton("📅 Расписание"),
-			),
-			// Ряд 3: Управление автоподъемом (основная функциональность)
-			tgbotapi.NewKeyboardButtonRow(
+			))
+		}
+		// Ряд 2: Основные операции с резюме (наиболее частые действия)
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("📜 Мои резюме"),
+			tgbotapi.NewKeyboardButton("📅 Расписание"),
+		))
+		// Ряд 3: Управление автоподъемом (основная функциональность)
+		if role.Allows(config.RoleOperator) {
+			rows = append(rows, tgbotapi.NewKeyboardButtonRow(
 				tgbotapi.NewKeyboardButton("➕ Настроить подъем"),
 				tgbotapi.NewKeyboardButton("❌ Удалить из расписания"),
-			),
-			// Ряд 4: Системные функции (реже используемые)
-			tgbotapi.NewKeyboardButtonRow(
-				tgbotapi.NewKeyboardButton("⚙ Настройки"),
-				tgbotapi.NewKeyboardButton("🔄 Обновить данные"),
-			),
-		)
+			))
+		}
+		// Ряд 4: Системные функции (реже используемые)
+		rows = append(rows, tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("⚙ Настройки"),
+			tgbotapi.NewKeyboardButton("🔄 Обновить данные"),
+		))
 	}
 	
+	keyboard := tgbotapi.NewReplyKeyboard(rows...)
 	keyboard.ResizeKeyboard = true
 
 	// Контекстное приветственное сообщение
 	text := "🎯 <b>HeadHunter Auto Resume</b>\n\n"
 	text += "Автоматический подъем резюме каждые 4 часа\n"
+	if len(b.config.Accounts) > 1 {
+		text += fmt.Sprintf("👥 Аккаунт: <b>%s</b>\n", b.activeAccountName())
+	}
 	
 	// Добавляем контекстную информацию в зависимости от состояния
-	if authStatus == "🔐 Войти в HeadHunter" {
+	if role != config.RoleOwner {
+		text += fmt.Sprintf("🔑 Ваша роль: <b>%s</b>\n", roleName(role))
+	}
+
+	if authStatus == "🔐 Войти в HeadHunter" && !role.Allows(config.RoleOwner) {
+		text += "\n⚠️ <i>Аккаунт HeadHunter не авторизован. Авторизоваться может только владелец бота</i>"
+	} else if authStatus == "🔐 Войти в HeadHunter" {
 		text += "\n🚀 <b>Добро пожаловать!</b>\n"
 		text += "⚠️ <i>Для начала работы необходимо войти в ваш аккаунт HeadHunter</i>\n\n"
 		text += "📋 <b>После авторизации вы сможете:</b>\n"
@@ -217,55 +375,51 @@ func (b *Bot) sendMainMenu(chatID int64) {
 		schedules := b.scheduler.GetAll()
 		text += "\n✅ <b>Система готова к работе</b>\n"
 		
-		if len(schedules) == 0 {
+		if paused, until := b.scheduler.GetPause(); paused {
+			text += fmt.Sprintf("🏖 <i>Режим отпуска: автоподъем приостановлен %s</i>", pauseStatusText(until))
+		} else if len(schedules) == 0 {
 			text += "💡 <i>Рекомендуем настроить автоподъем для ваших резюме</i>\n"
 			text += "📌 Нажмите \"➕ Настроить подъем\" для начала"
 		} else {
-			text += fmt.Sprintf("🔥 <i>Активно автоподъемов: %d</i>\n", len(schedules))
+			text += fmt.Sprintf("🔥 <i>Активно автоподъемов: %d</i>\n", len(schedules)-countPaused(schedules))
+			if paused := countPaused(schedules); paused > 0 {
+				text += fmt.Sprintf("⏸ <i>На паузе: %d</i>\n", paused)
+			}
 			text += "📈 Ваши резюме регулярно обновляются"
 		}
 		
 		// Добавляем статус уведомлений
-		if b.scheduler.GetNotificationsEnabled() {
-			text += "\n🔔 Уведомления включены"
-		} else {
-			text += "\n🔕 Уведомления отключены"
-		}
+		text += "\n🔔 Уведомления: " + b.notificationsStatus()
 	}
 	
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleAuth(chatID int64) {
 	// Если уже авторизован, показываем статус
-	if _, err := b.hhClient.GetResumes(); err == nil {
+	if b.authorized() {
 		text := "✅ <b>Вы уже авторизованы</b>\n\nПодключение к HeadHunter активно. Можете настраивать автоподъем резюме."
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	// Показываем процесс авторизации
 	processingMsg := tgbotapi.NewMessage(chatID, "🔄 <b>Авторизация...</b>\n\nПодключаемся к HeadHunter...")
 	processingMsg.ParseMode = "HTML"
-	b.api.Send(processingMsg)
+	b.send(processingMsg)
 
-	err := b.hhClient.Login()
+	// Вход через планировщик: он сохраняет токены и публикует события
+	// session.login_failed и session.restored, как при автоматическом входе
+	err := b.scheduler.Relogin(b.account())
+	b.resumeCache().Invalidate()
 	var text string
 	if err == nil {
 		text = "✅ <b>Авторизация успешна!</b>\n\nТеперь вы можете:\n• Просматривать свои резюме\n• Настраивать автоподъем\n• Управлять расписанием"
-		// Сохраняем токены после успешной авторизации
-		if xsrf, hhtoken := b.hhClient.GetTokens(); xsrf != "" && hhtoken != "" {
-			if saveErr := b.storage.SaveTokens(xsrf, hhtoken); saveErr != nil {
-				log.Printf("Failed to save tokens: %v", saveErr)
-			} else {
-				log.Println("Tokens saved successfully")
-			}
-		}
 		// Обновляем главное меню для показа нового статуса
 		b.sendMainMenu(chatID)
 		return
@@ -275,7 +429,7 @@ func (b *Bot) handleAuth(chatID int64) {
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleProfile(chatID int64) {
@@ -288,51 +442,47 @@ func (b *Bot) handleProfile(chatID int64) {
 
 	// Проверяем статус авторизации для более детальной информации
 	authStatus := "❌ Не авторизован"
-	if _, err := b.hhClient.GetResumes(); err == nil {
+	if b.authorized() {
 		authStatus = "✅ Активна"
 	}
 
 	text := "👤 <b>Профиль пользователя</b>\n\n"
 	text += fmt.Sprintf("🔐 Статус авторизации: <b>%s</b>\n", authStatus)
-	text += fmt.Sprintf("👨‍💼 Логин HeadHunter: <code>%s</code>\n", b.config.HHLogin)
+	account, _ := b.config.FindAccount(b.activeAccountName())
+	if len(b.config.Accounts) > 1 {
+		text += fmt.Sprintf("👥 Аккаунт: <b>%s</b>\n", account.Name)
+	}
+	text += fmt.Sprintf("👨‍💼 Логин HeadHunter: <code>%s</code>\n", account.Login)
 	text += "🔒 Пароль: <code>***</code>\n"
 	
 	proxyText := "не используется"
-	if b.config.Proxy != "None" && b.config.Proxy != "" {
-		proxyText = b.config.Proxy
+	if account.Proxy != "None" && account.Proxy != "" {
+		proxyText = account.Proxy
 	}
 	text += fmt.Sprintf("🌐 Прокси: <code>%s</code>\n", proxyText)
 	
-	notificationsText := "отключены"
-	if b.scheduler.GetNotificationsEnabled() {
-		notificationsText = "включены"
-	}
-	text += fmt.Sprintf("🔔 Уведомления: <b>%s</b>\n", notificationsText)
+	text += fmt.Sprintf("🔔 Уведомления: <b>%s</b>\n", b.notificationsStatus())
 	
 	// Добавляем информацию о расписаниях
-	schedules := b.scheduler.GetAll()
-	text += fmt.Sprintf("📅 Активных расписаний: <b>%d</b>\n", len(schedules))
+	text += fmt.Sprintf("📅 Активных расписаний: <b>%d</b>\n", len(b.accountSchedules(b.account())))
 	
 	text += "\n💡 <i>Настройки системы можно изменить через конфигурационный файл</i>"
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleListResumes(chatID int64) {
-	resumes, err := b.hhClient.GetResumes()
+	resumes, err := b.resumes()
 	if err != nil {
 		text := "❌ <b>Не удалось загрузить резюме</b>\n\n"
-		if _, authErr := b.hhClient.GetResumes(); authErr != nil {
-			text += "Необходимо авторизоваться в HeadHunter.\n\n💡 Нажмите кнопку \"🔐 Войти в HeadHunter\""
-		} else {
-			text += err.Error() + "\n\n💡 Попробуйте \"🔄 Обновить данные\""
-		}
+		text += escapeHTML(err.Error()) + "\n\n"
+		text += "💡 Войдите в HeadHunter кнопкой \"🔐 Войти в HeadHunter\" или попробуйте \"🔄 Обновить данные\""
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
@@ -342,7 +492,7 @@ func (b *Bot) handleListResumes(chatID int64) {
 			"💡 Используйте кнопку \"🔄 Обновить данные\""
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
@@ -354,8 +504,8 @@ func (b *Bot) handleListResumes(chatID int64) {
 		text += fmt.Sprintf("%d. <code>%s</code>", i+1, resume.Title)
 		
 		// Проверяем, есть ли расписание для этого резюме
-		if schedule, exists := schedules[resume.Title]; exists {
-			text += fmt.Sprintf("\n   ⏰ Автоподъем: %02d:%02d", schedule.Hour, schedule.Minute)
+		if schedule, exists := schedules[b.scheduleKey(resume.Title)]; exists {
+			text += fmt.Sprintf("\n   ⏰ Автоподъем: %s", formatTimes(schedule.Settings()))
 			text += fmt.Sprintf("\n   🕐 Следующий: %s", schedule.NextRun.Format("02.01 15:04"))
 		} else {
 			text += "\n   ➕ Автоподъем не настроен"
@@ -372,23 +522,25 @@ func (b *Bot) handleListResumes(chatID int64) {
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
-	b.api.Send(msg)
+	msg.ReplyMarkup = b.inlineKeyboardFor(chatID, raiseNowKeyboard(resumes).InlineKeyboard...)
+	b.send(msg)
 }
 
 func (b *Bot) handleUpdateResumes(chatID int64) {
 	// Показываем процесс обновления
 	processingMsg := tgbotapi.NewMessage(chatID, "🔄 <b>Обновляем данные...</b>\n\nЗагружаем актуальную информацию с HeadHunter...")
 	processingMsg.ParseMode = "HTML"
-	b.api.Send(processingMsg)
+	b.send(processingMsg)
 
-	resumes, err := b.hhClient.GetResumes()
+	// Пользователь явно просит свежие данные - запрашиваем hh.ru в обход кэша
+	resumes, err := b.resumeCache().Refresh()
 	if err != nil {
 		text := "❌ <b>Ошибка обновления данных</b>\n\n"
 		text += "Необходимо авторизоваться.\n\n"
 		text += "💡 Нажмите кнопку \"🔐 Войти в HeadHunter\""
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
@@ -399,7 +551,7 @@ func (b *Bot) handleUpdateResumes(chatID int64) {
 		
 		activeSchedules := 0
 		for _, resume := range resumes {
-			if _, exists := schedules[resume.Title]; exists {
+			if _, exists := schedules[b.scheduleKey(resume.Title)]; exists {
 				activeSchedules++
 			}
 		}
@@ -413,14 +565,14 @@ func (b *Bot) handleUpdateResumes(chatID int64) {
 		
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 	} else {
 		text := "⚠️ <b>Резюме не найдены</b>\n\n"
 		text += "Создайте резюме на hh.ru и повторите обновление.\n\n"
 		text += "🔗 Перейдите на hh.ru → Мои резюме"
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 	}
 }
 
@@ -434,10 +586,10 @@ func (b *Bot) handleDeleteResumeWithMessage(message *tgbotapi.Message) {
 
 func (b *Bot) handleAddResume(chatID int64, originalMessageID ...int) {
 	// Получаем список резюме
-	resumes, err := b.hhClient.GetResumes()
+	resumes, err := b.resumes()
 	if err != nil || len(resumes) == 0 {
 		msg := tgbotapi.NewMessage(chatID, "Обновите список резюме.")
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
@@ -451,7 +603,7 @@ func (b *Bot) handleAddResume(chatID int64, originalMessageID ...int) {
 		buttonText := resume.Title
 		
 		// Проверяем, есть ли уже расписание для этого резюме
-		if schedule, exists := schedules[resume.Title]; exists {
+		if schedule, exists := schedules[b.scheduleKey(resume.Title)]; exists {
 			buttonText += fmt.Sprintf(" ⏰ %02d:%02d", schedule.Hour, schedule.Minute)
 		} else {
 			buttonText += " ➕"
@@ -479,11 +631,10 @@ func (b *Bot) handleAddResume(chatID int64, originalMessageID ...int) {
 	msg.ReplyMarkup = markup
 	
 	// Сохраняем ID отправленного сообщения для возможности удаления при отмене
-	sentMsg, _ := b.api.Send(msg)
-	if b.userStates == nil {
-		b.userStates = make(map[int64]*UserState)
+	sentMsg, err := b.send(msg)
+	if err != nil {
+		return
 	}
-	
 	data := map[string]string{
 		"resume_list_message_id": fmt.Sprintf("%d", sentMsg.MessageID),
 	}
@@ -493,10 +644,7 @@ func (b *Bot) handleAddResume(chatID int64, originalMessageID ...int) {
 		data["original_message_id"] = fmt.Sprintf("%d", originalMessageID[0])
 	}
 	
-	b.userStates[chatID] = &UserState{
-		State: "showing_resume_list",
-		Data:  data,
-	}
+	b.dialogs.Start(chatID, stateResumeList, data)
 }
 
 func (b *Bot) handleDeleteResume(chatID int64, originalMessageID ...int) {
@@ -508,7 +656,7 @@ func (b *Bot) handleDeleteResume(chatID int64, originalMessageID ...int) {
 		text += "💡 Сначала настройте автоподъем с помощью кнопки \"➕ Настроить подъем\""
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
@@ -540,11 +688,10 @@ func (b *Bot) handleDeleteResume(chatID int64, originalMessageID ...int) {
 	msg.ReplyMarkup = markup
 	
 	// Сохраняем ID отправленного сообщения для возможности удаления при отмене
-	sentMsg, _ := b.api.Send(msg)
-	if b.userStates == nil {
-		b.userStates = make(map[int64]*UserState)
+	sentMsg, err := b.send(msg)
+	if err != nil {
+		return
 	}
-	
 	data := map[string]string{
 		"delete_list_message_id": fmt.Sprintf("%d", sentMsg.MessageID),
 	}
@@ -554,116 +701,47 @@ func (b *Bot) handleDeleteResume(chatID int64, originalMessageID ...int) {
 		data["original_message_id"] = fmt.Sprintf("%d", originalMessageID[0])
 	}
 	
-	b.userStates[chatID] = &UserState{
-		State: "showing_delete_list",
-		Data:  data,
-	}
+	b.dialogs.Start(chatID, stateDeleteList, data)
 }
 
-func (b *Bot) handleState(message *tgbotapi.Message, state *UserState) {
-	userID := message.Chat.ID
-
-	switch state.State {
-	case "add_resume_time":
-		b.handleAddResumeTime(message, state)
-	default:
-		// Неизвестное состояние, сбрасываем
-		delete(b.userStates, userID)
-		b.sendMainMenu(userID)
-	}
-}
-
-
-func (b *Bot) handleAddResumeTime(message *tgbotapi.Message, state *UserState) {
-	userID := message.Chat.ID
-	timeStr := message.Text
-
-	// Парсим время
-	if !strings.Contains(timeStr, ":") {
-		msg := tgbotapi.NewMessage(userID, "Ошибка при вводе времени, используйте формат 10:30.")
-		b.api.Send(msg)
-		return
-	}
-
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 {
-		msg := tgbotapi.NewMessage(userID, "Ошибка при вводе времени, используйте формат 10:30.")
-		b.api.Send(msg)
-		return
-	}
-
-	hour, err1 := strconv.Atoi(parts[0])
-	minute, err2 := strconv.Atoi(parts[1])
-	
-	if err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
-		msg := tgbotapi.NewMessage(userID, "Ошибка при вводе времени, используйте формат 10:30.")
-		b.api.Send(msg)
-		return
-	}
-
-	// Добавляем резюме в расписание
-	title := state.Data["title"]
-	resumeID := state.Data["resumeID"]
-	
-	b.scheduler.AddResume(title, resumeID, hour, minute)
-	
-	// Сохраняем расписание
-	if err := b.storage.SaveSchedule(b.scheduler.GetAll()); err != nil {
-		log.Printf("Failed to save schedule: %v", err)
-	} else {
-		log.Println("Schedule saved successfully")
-	}
-	
-	// Рассчитываем следующие времена подъема
-	nextTimes := []string{}
-	baseTime := fmt.Sprintf("%02d:%02d", hour, minute)
-	for i := 0; i < 4; i++ {
-		nextHour := (hour + i*4) % 24
-		nextTimes = append(nextTimes, fmt.Sprintf("%02d:%02d", nextHour, minute))
-	}
-	
-	text := fmt.Sprintf("✅ <b>Автоподъем настроен!</b>\n\n")
-	text += fmt.Sprintf("Резюме: <code>%s</code>\n", title)
-	text += fmt.Sprintf("⏰ Первый подъем: <b>%s</b>\n\n", baseTime)
-	text += "🔄 <b>Расписание на день:</b>\n"
-	for _, time := range nextTimes {
-		text += fmt.Sprintf("• %s\n", time)
-	}
-	text += "\n💡 <i>Автоподъем активен! Проверить статус можно в разделе \"📅 Расписание\"</i>"
-	
-	msg := tgbotapi.NewMessage(userID, text)
-	msg.ParseMode = "HTML"
-	b.api.Send(msg)
-
-	// Завершаем состояние
-	delete(b.userStates, userID)
-}
-
-
-func (b *Bot) handleShowSchedule(chatID int64) {
+func (b *Bot) handleShowSchedule(chatID int64, editMessageID ...int) {
 	schedules := b.scheduler.GetAll()
-	if len(schedules) == 0 {
+	if len(schedules) == 0 && len(b.scheduler.GetOneOffs()) == 0 {
 		text := "📅 <b>Расписание пусто</b>\n\n"
 		text += "Автоподъем резюме не настроен.\n\n"
 		text += "💡 Используйте кнопку \"➕ Настроить подъем\" для добавления резюме в автоподъем."
 		msg := tgbotapi.NewMessage(chatID, text)
 		msg.ParseMode = "HTML"
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
-	notificationsStatus := "включены"
-	if !b.scheduler.GetNotificationsEnabled() {
-		notificationsStatus = "отключены"
+	text := fmt.Sprintf("📅 <b>Расписание автоподъема (%d)</b>\n\n", len(schedules))
+	text += fmt.Sprintf("🔔 Уведомления: %s\n", b.notificationsStatus())
+	if paused, until := b.scheduler.GetPause(); paused {
+		text += fmt.Sprintf("🏖 <b>Режим отпуска: %s</b>\n", pauseStatusText(until))
 	}
+	text += "\n"
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
 
-	text := fmt.Sprintf("📅 <b>Расписание автоподъема (%d)</b>\n\n", len(schedules))
-	text += fmt.Sprintf("🔔 Уведомления: %s\n\n", notificationsStatus)
-	
 	i := 1
-	for title, schedule := range schedules {
+	for _, title := range sortedTitles(schedules) {
+		schedule := schedules[title]
 		text += fmt.Sprintf("<b>%d.</b> <code>%s</code>\n", i, title)
-		text += fmt.Sprintf("   ⏰ Время: <b>%02d:%02d</b>\n", schedule.Hour, schedule.Minute)
+		if schedule.Paused {
+			text += fmt.Sprintf("   ⏸ На паузе: <b>%s</b>\n", pauseStatusText(schedule.PausedUntil))
+		}
+		text += fmt.Sprintf("   ⏰ Время: <b>%s</b>\n", formatTimes(schedule.Settings()))
+		if len(schedule.Times) == 0 && schedule.GetInterval() != scheduler.DefaultInterval {
+			text += fmt.Sprintf("   🔁 Интервал: <b>%s</b>\n", formatInterval(schedule.GetInterval()))
+		}
+		for _, window := range schedule.Windows {
+			text += fmt.Sprintf("   🪟 Окно: <b>%s</b>\n", window)
+		}
+		if schedule.Jitter > 0 {
+			text += fmt.Sprintf("   🎲 Разброс: <b>до %d мин</b>\n", int(schedule.Jitter.Minutes()))
+		}
 		text += fmt.Sprintf("   🕐 Следующий запуск: <i>%s</i>\n", 
 			schedule.NextRun.Format("02.01 15:04"))
 		
@@ -671,48 +749,33 @@ func (b *Bot) handleShowSchedule(chatID int64) {
 			text += fmt.Sprintf("   ✅ Последний: <i>%s</i>\n", 
 				schedule.LastRun.Format("02.01 15:04"))
 		}
+
+		if schedule.Attempt > 0 {
+			text += fmt.Sprintf("   🔁 Повтор %d/%d: <i>%s</i>\n",
+				schedule.Attempt+1, b.scheduler.RetryPolicyFor(schedule).MaxAttempts, schedule.RetryAt.Format("02.01 15:04"))
+		}
 		
 		if i < len(schedules) {
 			text += "\n"
 		}
 		i++
+
+		keyboard = append(keyboard, scheduleButtonRow(title, schedule))
 	}
 	
-	text += "\n💡 <i>Резюме поднимаются автоматически каждые 4 часа</i>"
-
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "HTML"
-	b.api.Send(msg)
-}
-
-func (b *Bot) handleToggleNotifications(chatID int64) {
-	keyboard := tgbotapi.NewReplyKeyboard(
-		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton("↩️ Главное меню"),
-		),
-	)
-	keyboard.ResizeKeyboard = true
-
-	enabled := b.scheduler.ToggleNotifications()
-	var text string
-	if enabled {
-		text = "🔔 <b>Уведомления включены</b>\n\n"
-		text += "✅ Вы будете получать сообщения о результатах автоподъема резюме\n\n"
-		text += "📬 <b>Вы будете уведомлены о:</b>\n"
-		text += "• Успешном подъеме резюме\n"
-		text += "• Ошибках при подъеме\n"
-		text += "• Проблемах авторизации\n"
-		text += "• Изменениях в расписании"
-	} else {
-		text = "🔕 <b>Уведомления отключены</b>\n\n"
-		text += "❌ Автоподъем продолжит работать в фоновом режиме без уведомлений\n\n"
-		text += "💡 <i>Включить уведомления можно в любой момент через настройки</i>"
+	if oneOffs := b.scheduler.GetOneOffs(); len(oneOffs) > 0 {
+		text += "\n🎯 <b>Разовые подъемы:</b>\n"
+		for _, oneOff := range oneOffs {
+			text += fmt.Sprintf("• <i>%s</i> <code>%s</code>\n", oneOff.At.Format("02.01 15:04"), oneOff.Title)
+			keyboard = append(keyboard, oneOffButtonRow(oneOff))
+		}
 	}
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	text += "\n💡 <i>Резюме поднимаются автоматически по расписанию (по умолчанию каждые 4 часа). ✏️ - изменить расписание</i>"
+
+	paused, _ := b.scheduler.GetPause()
+	keyboard = append(keyboard, vacationButtonRow(paused))
+	b.sendOrEdit(chatID, text, b.inlineKeyboardFor(chatID, keyboard...), editMessageID...)
 }
 
 func (b *Bot) handleCancelAddResume(callback *tgbotapi.CallbackQuery) {
@@ -720,20 +783,20 @@ func (b *Bot) handleCancelAddResume(callback *tgbotapi.CallbackQuery) {
 	
 	// Удаляем сообщение с кнопками
 	deleteMsg := tgbotapi.NewDeleteMessage(chatID, callback.Message.MessageID)
-	b.api.Request(deleteMsg)
+	b.request(deleteMsg)
 	
 	// Удаляем оригинальное сообщение "➕ Добавить/обновить" если есть
-	if state, exists := b.userStates[chatID]; exists {
-		if originalMsgID := state.Data["original_message_id"]; originalMsgID != "" {
+	if current, err := b.dialogs.Get(chatID); err == nil {
+		if originalMsgID := current.Data["original_message_id"]; originalMsgID != "" {
 			if msgID, err := strconv.Atoi(originalMsgID); err == nil {
 				deleteOriginal := tgbotapi.NewDeleteMessage(chatID, msgID)
-				b.api.Request(deleteOriginal)
+				b.request(deleteOriginal)
 			}
 		}
 	}
 	
 	// Очищаем состояние пользователя
-	delete(b.userStates, chatID)
+	b.dialogs.Clear(chatID)
 }
 
 func (b *Bot) handleAddResumeCallback(callback *tgbotapi.CallbackQuery) {
@@ -741,10 +804,10 @@ func (b *Bot) handleAddResumeCallback(callback *tgbotapi.CallbackQuery) {
 	resumeID := strings.TrimPrefix(callback.Data, "add_resume:")
 	
 	// Найдем резюме по ID чтобы получить название
-	resumes, err := b.hhClient.GetResumes()
+	resumes, err := b.resumes()
 	if err != nil {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Ошибка получения списка резюме")
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 	
@@ -758,37 +821,29 @@ func (b *Bot) handleAddResumeCallback(callback *tgbotapi.CallbackQuery) {
 	
 	if resumeTitle == "" {
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, "Резюме не найдено")
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 	
-	// Удаляем сообщение с кнопками
-	deleteMsg := tgbotapi.NewDeleteMessage(callback.Message.Chat.ID, callback.Message.MessageID)
-	b.api.Request(deleteMsg)
-	
-	// Устанавливаем состояние ожидания времени
-	b.userStates[callback.Message.Chat.ID] = &UserState{
-		State: "add_resume_time",
-		Data: map[string]string{
-			"title":    resumeTitle,
-			"resumeID": resumeID,
-		},
+	// Уже настроенное расписание редактируем на месте, чтобы не потерять историю
+	key := b.scheduleKey(resumeTitle)
+	if _, exists := b.scheduler.GetAll()[key]; exists {
+		b.dialogs.Clear(callback.Message.Chat.ID)
+		b.handleEditSchedule(callback.Message.Chat.ID, key, callback.Message.MessageID)
+		return
 	}
-	
-	text := fmt.Sprintf("⏰ <b>Настройка автоподъема</b>\n\n")
-	text += fmt.Sprintf("Резюме: <code>%s</code>\n\n", resumeTitle)
-	text += "📋 <b>Введите время первого подъема</b> (формат ЧЧ:ММ)\n"
-	text += "Например: <code>09:00</code> или <code>14:30</code>\n\n"
-	text += "🔄 Далее резюме будет подниматься <b>каждые 4 часа</b>\n"
-	text += "💡 Рекомендуемое время: 09:00 (подъемы в 9:00, 13:00, 17:00, 21:00)"
-	
-	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
-	msg.ParseMode = "HTML"
-	b.api.Send(msg)
+
+	// Показываем выбор времени на месте списка резюме
+	b.startTimePicker(callback.Message.Chat.ID, callback.Message.MessageID, timePicker{
+		Account:  b.account(),
+		Title:    key,
+		ResumeID: resumeID,
+		Settings: scheduler.ScheduleSettings{Hour: 9, Minute: 0},
+	})
 }
 
 func (b *Bot) handleSettingsMenu(chatID int64) {
-	keyboard := tgbotapi.NewReplyKeyboard(
+	keyboard := b.replyKeyboardFor(chatID,
 		// Ряд 1: Настройки профиля и уведомлений
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton("👤 Профиль"),
@@ -797,6 +852,10 @@ func (b *Bot) handleSettingsMenu(chatID int64) {
 		// Ряд 2: Системные функции
 		tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton("🔄 Обновить данные"),
+			tgbotapi.NewKeyboardButton("📊 История"),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton("👥 Аккаунты"),
 			tgbotapi.NewKeyboardButton("ℹ️ Помощь"),
 		),
 		// Ряд 3: Возврат в главное меню
@@ -807,10 +866,7 @@ func (b *Bot) handleSettingsMenu(chatID int64) {
 	keyboard.ResizeKeyboard = true
 
 	// Получаем текущие настройки для отображения
-	notificationsStatus := "включены"
-	if !b.scheduler.GetNotificationsEnabled() {
-		notificationsStatus = "отключены"
-	}
+	notificationsStatus := b.notificationsStatus()
 	
 	schedules := b.scheduler.GetAll()
 	
@@ -819,7 +875,7 @@ func (b *Bot) handleSettingsMenu(chatID int64) {
 	text += fmt.Sprintf("📋 Резюме в автоподъеме: <b>%d</b>\n", len(schedules))
 	
 	// Проверяем статус авторизации
-	if _, err := b.hhClient.GetResumes(); err == nil {
+	if b.authorized() {
 		text += "🔐 Авторизация: <b>✅ Активна</b>\n"
 	} else {
 		text += "🔐 Авторизация: <b>❌ Требуется</b>\n"
@@ -830,7 +886,7 @@ func (b *Bot) handleSettingsMenu(chatID int64) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleHelp(chatID int64) {
@@ -846,16 +902,30 @@ func (b *Bot) handleHelp(chatID int64) {
 	text += "• <b>Авторизация</b> - подключение к вашему аккаунту HeadHunter\n"
 	text += "• <b>Мои резюме</b> - просмотр всех ваших резюме\n"
 	text += "• <b>Настроить подъем</b> - автоматический подъем каждые 4 часа\n"
-	text += "• <b>Расписание</b> - управление временем подъема резюме\n\n"
+	text += "• <b>Расписание</b> - управление временем подъема резюме\n"
+	text += "• <b>Поднять сейчас</b> - немедленный или разовый подъем из списка резюме\n"
+	text += "• <b>Аккаунты</b> - выбор активного аккаунта hh.ru, если их несколько (в настройках)\n\n"
 	
 	text += "⏰ <b>Как работает автоподъем:</b>\n"
 	text += "1. Выберите резюме для автоподъема\n"
-	text += "2. Укажите время первого подъема (например, 09:00)\n"
+	text += "2. Выберите время первого подъема, интервал и дни недели на клавиатуре\n"
+	text += "   или напишите фразой: <i>по будням в 9 и 14</i>, <i>каждые 6 часов с 8 до 22</i>\n"
 	text += "3. Система будет поднимать резюме каждые 4 часа\n"
 	text += "   Пример: 09:00 → 13:00 → 17:00 → 21:00\n\n"
 	
 	text += "🔔 <b>Уведомления:</b>\n"
 	text += "Получайте сообщения о результатах автоподъема\n\n"
+
+	text += "📊 <b>История:</b>\n"
+	text += "Все попытки подъема сохраняются в журнал, статистика доступна в разделе \"📊 История\" или по команде /history\n\n"
+
+	text += "⌨️ <b>Команды:</b>\n"
+	text += commandsHelp(b.roleOf(chatID)) + "\n"
+
+	text += "🔑 <b>Роли:</b>\n"
+	text += "• <b>Владелец</b> - авторизация, аккаунты и все остальное\n"
+	text += "• <b>Оператор</b> - расписание, подъем резюме и уведомления\n"
+	text += "• <b>Наблюдатель</b> - только статус, расписание и история\n\n"
 	
 	text += "⚠️ <b>Важно:</b>\n"
 	text += "• Резюме поднимается максимум раз в 4 часа (ограничение HH)\n"
@@ -865,7 +935,7 @@ func (b *Bot) handleHelp(chatID int64) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleDeleteResumeCallback(callback *tgbotapi.CallbackQuery) {
@@ -874,7 +944,7 @@ func (b *Bot) handleDeleteResumeCallback(callback *tgbotapi.CallbackQuery) {
 	
 	// Удаляем сообщение с кнопками
 	deleteMsg := tgbotapi.NewDeleteMessage(callback.Message.Chat.ID, callback.Message.MessageID)
-	b.api.Request(deleteMsg)
+	b.request(deleteMsg)
 	
 	// Удаляем резюме из расписания
 	removed := b.scheduler.RemoveResume(resumeTitle)
@@ -887,11 +957,7 @@ func (b *Bot) handleDeleteResumeCallback(callback *tgbotapi.CallbackQuery) {
 		text += "При необходимости можете настроить заново."
 		
 		// Сохраняем расписание после удаления
-		if err := b.storage.SaveSchedule(b.scheduler.GetAll()); err != nil {
-			log.Printf("Failed to save schedule: %v", err)
-		} else {
-			log.Println("Schedule saved successfully after deletion")
-		}
+		b.saveState()
 	} else {
 		text = "❌ <b>Ошибка удаления</b>\n\n"
 		text += fmt.Sprintf("Резюме \"%s\" не найдено в расписании.", resumeTitle)
@@ -899,21 +965,21 @@ func (b *Bot) handleDeleteResumeCallback(callback *tgbotapi.CallbackQuery) {
 
 	// Удаляем оригинальное сообщение "❌ Удалить из расписания" если есть
 	chatID := callback.Message.Chat.ID
-	if state, exists := b.userStates[chatID]; exists {
-		if originalMsgID := state.Data["original_message_id"]; originalMsgID != "" {
+	if current, err := b.dialogs.Get(chatID); err == nil {
+		if originalMsgID := current.Data["original_message_id"]; originalMsgID != "" {
 			if msgID, err := strconv.Atoi(originalMsgID); err == nil {
 				deleteOriginal := tgbotapi.NewDeleteMessage(chatID, msgID)
-				b.api.Request(deleteOriginal)
+				b.request(deleteOriginal)
 			}
 		}
 	}
 	
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
-	b.api.Send(msg)
+	b.send(msg)
 	
 	// Очищаем состояние пользователя
-	delete(b.userStates, chatID)
+	b.dialogs.Clear(chatID)
 }
 
 func (b *Bot) handleCancelDeleteResume(callback *tgbotapi.CallbackQuery) {
@@ -921,26 +987,18 @@ func (b *Bot) handleCancelDeleteResume(callback *tgbotapi.CallbackQuery) {
 	
 	// Удаляем сообщение с кнопками
 	deleteMsg := tgbotapi.NewDeleteMessage(chatID, callback.Message.MessageID)
-	b.api.Request(deleteMsg)
+	b.request(deleteMsg)
 	
 	// Удаляем оригинальное сообщение "❌ Удалить из расписания" если есть
-	if state, exists := b.userStates[chatID]; exists {
-		if originalMsgID := state.Data["original_message_id"]; originalMsgID != "" {
+	if current, err := b.dialogs.Get(chatID); err == nil {
+		if originalMsgID := current.Data["original_message_id"]; originalMsgID != "" {
 			if msgID, err := strconv.Atoi(originalMsgID); err == nil {
 				deleteOriginal := tgbotapi.NewDeleteMessage(chatID, msgID)
-				b.api.Request(deleteOriginal)
+				b.request(deleteOriginal)
 			}
 		}
 	}
 	
 	// Очищаем состояние пользователя
-	delete(b.userStates, chatID)
+	b.dialogs.Clear(chatID)
 }
-
-func (b *Bot) SendNotification(message string) {
-	if b.config.AdminTG != 0 {
-		msg := tgbotapi.NewMessage(b.config.AdminTG, message)
-		msg.ParseMode = "HTML"
-		b.api.Send(msg)
-	}
-}
\ No newline at end of file
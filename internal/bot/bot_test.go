@@ -0,0 +1,311 @@
+package bot
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+	"hh-ru-auto-resume-raising/internal/storage"
+	"hh-ru-auto-resume-raising/internal/telegramfake"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+const (
+	testOwner      int64 = 1001
+	testHHLogin          = "user@example.com"
+	testHHPassword       = "secret"
+	waitTimeout          = 5 * time.Second
+)
+
+var testResumes = []hh.Resume{
+	{ID: "a1b2c3", Title: "Go-разработчик"},
+	{ID: "d4e5f6", Title: "Тимлид"},
+}
+
+// fakeHH - имитация hh.ru: анонимные куки, вход по паролю, список резюме и подъем
+type fakeHH struct {
+	password string
+	resumes  []hh.Resume
+}
+
+func (f *fakeHH) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authorized := strings.Contains(r.Header.Get("Cookie"), "hhtoken=session;")
+
+	switch {
+	case r.Method == http.MethodHead && r.URL.Path == "/":
+		w.Header().Add("Set-Cookie", "_xsrf=anonymous; Path=/")
+		w.Header().Add("Set-Cookie", "hhtoken=anonymous; Path=/")
+	case r.Method == http.MethodPost && r.URL.Path == "/account/login":
+		if r.FormValue("password") == f.password {
+			w.Header().Add("Set-Cookie", "_xsrf=xsrf; Path=/")
+			w.Header().Add("Set-Cookie", "hhtoken=session; Path=/")
+		}
+	case r.URL.Path == "/applicant/resumes":
+		if !authorized {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		for _, resume := range f.resumes {
+			fmt.Fprintf(w, `<div data-qa="resume" data-qa-title="%s"><a href="/resume/%s">%s</a></div>`, resume.Title, resume.ID, resume.Title)
+		}
+	case r.URL.Path == "/applicant/resumes/touch":
+		if !authorized {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// hhTransport направляет запросы к hh.ru на имитацию, остальные (к имитации Telegram) - как есть
+type hhTransport struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (t hhTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == "hh.ru" {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	}
+	return t.next.RoundTrip(req)
+}
+
+// testBot - бот, запущенный против имитаций Telegram и hh.ru во временном каталоге
+type testBot struct {
+	bot      *Bot
+	telegram *telegramfake.Server
+	sched    *scheduler.Scheduler
+	store    *storage.Storage
+	events   chan notify.Event // события планировщика
+}
+
+// startTestBot запускает бота владельца; hhPassword - пароль hh.ru из HH_PASSWORD
+func startTestBot(t *testing.T, hhPassword string) *testBot {
+	t.Helper()
+
+	// Хранилище пишет в config/ относительно рабочего каталога
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	hhServer := httptest.NewServer(&fakeHH{password: testHHPassword, resumes: testResumes})
+	t.Cleanup(hhServer.Close)
+	target, _ := url.Parse(hhServer.URL)
+	transport := http.DefaultTransport
+	http.DefaultTransport = hhTransport{target: target, next: transport}
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	telegram, err := telegramfake.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { telegram.Close() })
+
+	t.Setenv("TELEGRAM_TOKEN", telegramfake.Token)
+	t.Setenv("TELEGRAM_API_URL", telegram.URL())
+	t.Setenv("ADMIN_TG", strconv.FormatInt(testOwner, 10))
+	t.Setenv("HH_LOGIN", testHHLogin)
+	t.Setenv("HH_PASSWORD", hhPassword)
+	t.Setenv("TZ", "UTC")
+	cfg := config.Load()
+
+	store := storage.New()
+	if err := store.Init(); err != nil {
+		t.Fatal(err)
+	}
+	client, err := hh.NewClient(cfg.Accounts[0].Login, cfg.Accounts[0].Password, cfg.Accounts[0].Proxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched := scheduler.New(client, cfg.Timezone)
+	sched.SetSessionHandler(func(account string, hhClient *hh.Client) {
+		xsrf, hhtoken := hhClient.GetTokens()
+		if err := store.SaveTokens(account, xsrf, hhtoken, hhClient.LoginTime()); err != nil {
+			t.Errorf("save tokens: %v", err)
+		}
+	})
+	sched.SetSaveHandler(func() {
+		if err := store.SaveSchedule(sched.GetAll()); err != nil {
+			t.Errorf("save schedule: %v", err)
+		}
+		err := store.UpdateSettings(func(settings *storage.Settings) {
+			notifySettings := sched.GetNotifySettings()
+			settings.Notifications = &notifySettings
+		})
+		if err != nil {
+			t.Errorf("save settings: %v", err)
+		}
+	})
+
+	b, err := New(cfg, map[string]*hh.Client{cfg.Accounts[0].Name: client}, sched, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan notify.Event, 100)
+	sched.SetNotificationHandler(func(event notify.Event) { events <- event })
+	go b.Start()
+	t.Cleanup(b.Stop)
+
+	return &testBot{bot: b, telegram: telegram, sched: sched, store: store, events: events}
+}
+
+// send отправляет сообщение владельца и ждет ответ бота с текстом want
+func (tb *testBot) send(t *testing.T, text, want string) telegramfake.Message {
+	t.Helper()
+	tb.telegram.SendText(testOwner, text)
+	return tb.wait(t, want)
+}
+
+// press нажимает кнопку владельца и ждет ответ бота с текстом want
+func (tb *testBot) press(t *testing.T, button, want string) telegramfake.Message {
+	t.Helper()
+	if _, err := tb.telegram.PressButton(testOwner, button); err != nil {
+		t.Fatal(err)
+	}
+	return tb.wait(t, want)
+}
+
+func (tb *testBot) wait(t *testing.T, want string) telegramfake.Message {
+	t.Helper()
+	message, err := tb.telegram.WaitText(testOwner, waitTimeout, want)
+	if err != nil {
+		t.Fatalf("waiting for %q: %v", want, err)
+	}
+	return message
+}
+
+func (tb *testBot) login(t *testing.T) {
+	t.Helper()
+	tb.send(t, "/login", "Система готова к работе")
+}
+
+func TestLogin(t *testing.T) {
+	tb := startTestBot(t, testHHPassword)
+
+	menu := tb.send(t, "/start", "Добро пожаловать")
+	if menu.ReplyKeyboard == nil || menu.ReplyKeyboard.Keyboard[0][0].Text != "🔐 Войти в HeadHunter" {
+		t.Fatalf("menu before login has no login button: %+v", menu.ReplyKeyboard)
+	}
+
+	tb.login(t)
+	tokens, err := tb.store.LoadTokens("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.HHToken != "session" {
+		t.Errorf("saved hhtoken = %q, want %q", tokens.HHToken, "session")
+	}
+
+	tb.send(t, "/login", "Вы уже авторизованы")
+}
+
+func TestLoginPublishesSessionEvents(t *testing.T) {
+	tb := startTestBot(t, "wrong")
+
+	tb.send(t, "/login", "Ошибка авторизации")
+	select {
+	case event := <-tb.events:
+		if event.Type != notify.EventLoginFailed || event.Error == "" {
+			t.Errorf("event = %+v, want %s with error", event, notify.EventLoginFailed)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("no %s event after manual login", notify.EventLoginFailed)
+	}
+}
+
+func TestAddSchedule(t *testing.T) {
+	tb := startTestBot(t, testHHPassword)
+	tb.login(t)
+
+	tb.send(t, "/add", "Выберите резюме")
+	tb.press(t, "Тимлид", "Настройка автоподъема")
+	card := tb.press(t, "Готово", "Автоподъем настроен")
+	if _, ok := card.Button("Изменить"); !ok {
+		t.Errorf("confirmation card has no edit button: %v", card.Buttons())
+	}
+
+	schedule, exists := tb.sched.GetAll()["Тимлид"]
+	if !exists {
+		t.Fatal("schedule was not added")
+	}
+	if schedule.ResumeID != "d4e5f6" || schedule.Hour != 9 || schedule.Minute != 0 {
+		t.Errorf("schedule = %s %02d:%02d, want d4e5f6 09:00", schedule.ResumeID, schedule.Hour, schedule.Minute)
+	}
+
+	saved, err := tb.store.LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := saved["Тимлид"]; !exists {
+		t.Error("schedule was not saved")
+	}
+}
+
+func TestDeleteSchedule(t *testing.T) {
+	tb := startTestBot(t, testHHPassword)
+	tb.sched.AddResume("", "Go-разработчик", "a1b2c3", scheduler.ScheduleSettings{Hour: 9})
+	tb.sched.AddResume("", "Тимлид", "d4e5f6", scheduler.ScheduleSettings{Hour: 10})
+
+	tb.send(t, "/delete", "Активных автоподъемов: 2")
+	tb.press(t, "Тимлид", "Удалено из автоподъема")
+
+	schedules := tb.sched.GetAll()
+	if _, exists := schedules["Тимлид"]; exists || len(schedules) != 1 {
+		t.Errorf("schedules after delete: %v", schedules)
+	}
+	saved, err := tb.store.LoadSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := saved["Тимлид"]; exists || len(saved) != 1 {
+		t.Errorf("saved schedules after delete: %v", saved)
+	}
+}
+
+func TestToggleNotifications(t *testing.T) {
+	tb := startTestBot(t, testHHPassword)
+
+	tests := []struct {
+		button string
+		want   notify.Level
+	}{
+		{"только ошибки", notify.LevelErrors},
+		{"выключены", notify.LevelNone},
+		{"все", notify.LevelAll},
+	}
+
+	tb.send(t, "/notifications", "Общий уровень")
+	for _, tt := range tests {
+		settings := tb.press(t, tt.button, "Общий уровень: <b>"+tt.button+"</b>")
+		if _, ok := settings.Button("✅ " + tt.button); !ok {
+			t.Errorf("%s: selected level is not checked: %v", tt.button, settings.Buttons())
+		}
+		if level := tb.sched.GetNotifySettings().GlobalLevel(); level != tt.want {
+			t.Errorf("%s: level = %q, want %q", tt.button, level, tt.want)
+		}
+
+		saved, err := tb.store.LoadSettings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.Notifications == nil || saved.Notifications.GlobalLevel() != tt.want {
+			t.Errorf("%s: saved settings = %+v, want level %q", tt.button, saved.Notifications, tt.want)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/outbox"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+// command - команда бота. Вызывается как /name с аргументами или кнопкой
+// меню из buttons; и то и другое проходит через routeCommand.
+type command struct {
+	name        string
+	args        string
+	description string
+	role        config.Role
+	buttons     []string
+	handler     func(b *Bot, message *tgbotapi.Message, args string)
+}
+
+// commands - все команды в порядке показа в меню Telegram и справке.
+// Устаревшие подписи кнопок оставлены для обратной совместимости.
+// Заполняется в init, чтобы разорвать цикл инициализации: обработчики строят
+// клавиатуры меню, а те проверяют роли по этому списку.
+var commands []command
+
+func init() {
+	commands = []command{
+		{
+			name:        "start",
+			description: "Главное меню",
+			role:        config.RoleViewer,
+			buttons:     []string{"↩️ Главное меню"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.sendMainMenu(m.Chat.ID) },
+		},
+		{
+			name:        "status",
+			description: "Статус авторизации и автоподъема",
+			role:        config.RoleViewer,
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleStatus(m.Chat.ID) },
+		},
+		{
+			name:        "resumes",
+			description: "Мои резюме",
+			role:        config.RoleViewer,
+			buttons:     []string{"📜 Мои резюме", "📜 Список резюме"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleListResumes(m.Chat.ID) },
+		},
+		{
+			name:        "schedule",
+			description: "Расписание автоподъема",
+			role:        config.RoleViewer,
+			buttons:     []string{"📅 Расписание"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleShowSchedule(m.Chat.ID) },
+		},
+		{
+			name:        "raise",
+			args:        "<резюме>",
+			description: "Поднять резюме сейчас: /raise <часть названия> или /raise all",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, args string) { b.handleRaiseCommand(m.Chat.ID, args) },
+		},
+		{
+			name:        "add",
+			description: "Настроить автоподъем резюме",
+			role:        config.RoleOperator,
+			buttons:     []string{"➕ Настроить подъем", "➕ Добавить/обновить"},
+			handler:     (*Bot).handleAddResumeCommand,
+		},
+		{
+			name:        "delete",
+			description: "Удалить резюме из расписания",
+			role:        config.RoleOperator,
+			buttons:     []string{"❌ Удалить из расписания", "❌ Удалить"},
+			handler:     (*Bot).handleDeleteResumeCommand,
+		},
+		{
+			name:        "pause",
+			args:        "[срок]",
+			description: "Режим отпуска: /pause 7, /pause 25.12 или /pause 0 - бессрочно",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, args string) { b.handlePauseCommand(m.Chat.ID, args) },
+		},
+		{
+			name:        "unpause",
+			description: "Выключить режим отпуска",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleUnpauseCommand(m.Chat.ID) },
+		},
+		{
+			name:        "history",
+			description: "История подъемов",
+			role:        config.RoleViewer,
+			buttons:     []string{"📊 История"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleHistory(m.Chat.ID) },
+		},
+		{
+			name:        "webhooks",
+			description: "История доставки вебхуков",
+			role:        config.RoleViewer,
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleWebhooks(m.Chat.ID) },
+		},
+		{
+			name:        "outbox",
+			description: "Очередь отправки и неотправленные сообщения Telegram",
+			role:        config.RoleViewer,
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleOutbox(m.Chat.ID) },
+		},
+		{
+			name:        "update",
+			description: "Обновить данные с hh.ru",
+			role:        config.RoleViewer,
+			buttons:     []string{"🔄 Обновить данные", "📝 Обновить список резюме"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleUpdateResumes(m.Chat.ID) },
+		},
+		{
+			name:        "notifications",
+			description: "Настройка уведомлений: уровни по событиям и резюме, тихие часы",
+			role:        config.RoleOperator,
+			buttons:     []string{"🔔 Уведомления", "🔔 Вкл/выкл уведомления"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleNotificationSettings(m.Chat.ID) },
+		},
+		{
+			name:        "digest",
+			args:        "[daily|weekly время]",
+			description: "Сводки по расписанию: /digest daily 09:00, /digest weekly пн 10:00",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, args string) { b.handleDigestCommand(m.Chat.ID, args) },
+		},
+		{
+			name:        "quiet",
+			args:        "[ЧЧ:ММ-ЧЧ:ММ]",
+			description: "Тихие часы: /quiet 23:00-08:00 или /quiet off",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, args string) { b.handleQuietCommand(m.Chat.ID, args) },
+		},
+		{
+			name:        "dashboard",
+			args:        "[on|off]",
+			description: "Живой статус: одно закрепленное сообщение вместо уведомлений",
+			role:        config.RoleOperator,
+			handler:     func(b *Bot, m *tgbotapi.Message, args string) { b.handleDashboardCommand(m.Chat.ID, args) },
+		},
+		{
+			name:        "login",
+			description: "Войти в HeadHunter",
+			role:        config.RoleOwner,
+			buttons:     []string{"🔐 Войти в HeadHunter", "✅ Авторизован", "🚀️ Авторизоваться"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleAuth(m.Chat.ID) },
+		},
+		{
+			name:        "profile",
+			description: "Профиль HeadHunter",
+			role:        config.RoleOwner,
+			buttons:     []string{"👤 Профиль"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleProfile(m.Chat.ID) },
+		},
+		{
+			name:        "accounts",
+			description: "Аккаунты hh.ru",
+			role:        config.RoleOwner,
+			buttons:     []string{"👥 Аккаунты"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleAccounts(m.Chat.ID) },
+		},
+		{
+			name:        "settings",
+			description: "Настройки",
+			role:        config.RoleViewer,
+			buttons:     []string{"⚙ Настройки"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleSettingsMenu(m.Chat.ID) },
+		},
+		{
+			name:        "cancel",
+			description: "Отменить текущий ввод",
+			role:        config.RoleViewer,
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.cancelDialog(m.Chat.ID) },
+		},
+		{
+			name:        "help",
+			description: "Справка",
+			role:        config.RoleViewer,
+			buttons:     []string{"ℹ️ Помощь"},
+			handler:     func(b *Bot, m *tgbotapi.Message, _ string) { b.handleHelp(m.Chat.ID) },
+		},
+	}
+}
+
+// resolveCommand находит команду по тексту сообщения: /name [аргументы]
+// (в том числе /name@bot в группах) или подпись кнопки меню
+func resolveCommand(message *tgbotapi.Message) (command, string, bool) {
+	if message.IsCommand() {
+		name := strings.ToLower(message.Command())
+		for _, cmd := range commands {
+			if cmd.name == name {
+				return cmd, strings.TrimSpace(message.CommandArguments()), true
+			}
+		}
+		return command{}, "", false
+	}
+
+	if cmd, ok := commandByButton(message.Text); ok {
+		return cmd, "", true
+	}
+	return command{}, "", false
+}
+
+func commandByButton(text string) (command, bool) {
+	for _, cmd := range commands {
+		for _, button := range cmd.buttons {
+			if button == text {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// routeCommand выполняет команду из сообщения с проверкой роли.
+// Неизвестный текст возвращает в главное меню.
+func (b *Bot) routeCommand(message *tgbotapi.Message, role config.Role) {
+	cmd, args, ok := resolveCommand(message)
+	if !ok {
+		b.sendMainMenu(message.Chat.ID)
+		return
+	}
+
+	if !role.Allows(cmd.role) {
+		b.sendForbidden(message.Chat.ID, cmd.role)
+		return
+	}
+	cmd.handler(b, message, args)
+}
+
+// botCommands возвращает команды для меню Telegram, доступные роли
+func botCommands(role config.Role) []tgbotapi.BotCommand {
+	var result []tgbotapi.BotCommand
+	for _, cmd := range commands {
+		if role.Allows(cmd.role) {
+			result = append(result, tgbotapi.BotCommand{Command: cmd.name, Description: cmd.description})
+		}
+	}
+	return result
+}
+
+// registerCommands регистрирует меню команд (setMyCommands) для каждого
+// пользователя бота с учетом его роли
+func (b *Bot) registerCommands() {
+	for _, userID := range b.config.AdminIDs() {
+		b.setChatCommands(userID, botCommands(b.config.RoleOf(userID)))
+	}
+}
+
+// registerDefaultCommands задает меню команд для всех остальных чатов
+func registerDefaultCommands(api *outbox.Outbox) {
+	defaults := []tgbotapi.BotCommand{
+		{Command: "start", Description: "Главное меню"},
+		{Command: "help", Description: "Справка"},
+	}
+	if _, err := api.Request(tgbotapi.NewSetMyCommands(defaults...)); err != nil {
+		log.Printf("Failed to register default commands: %v", err)
+	}
+}
+
+func (b *Bot) setChatCommands(chatID int64, commands []tgbotapi.BotCommand) {
+	scope := tgbotapi.NewBotCommandScopeChat(chatID)
+	if _, err := b.api.Request(tgbotapi.NewSetMyCommandsWithScope(scope, commands...)); err != nil {
+		log.Printf("Failed to register commands for %d: %v", chatID, err)
+	}
+}
+
+// commandsHelp возвращает список команд, доступных роли, для справки
+func commandsHelp(role config.Role) string {
+	text := ""
+	for _, cmd := range commands {
+		if !role.Allows(cmd.role) {
+			continue
+		}
+		usage := "/" + cmd.name
+		if cmd.args != "" {
+			usage += " " + escapeHTML(cmd.args)
+		}
+		text += fmt.Sprintf("%s - %s\n", usage, escapeHTML(cmd.description))
+	}
+	return text
+}
+
+func (b *Bot) handleAddResumeCommand(message *tgbotapi.Message, _ string) {
+	b.handleAddResume(message.Chat.ID, message.MessageID)
+}
+
+func (b *Bot) handleDeleteResumeCommand(message *tgbotapi.Message, _ string) {
+	b.handleDeleteResume(message.Chat.ID, message.MessageID)
+}
+
+// handleStatus кратко показывает состояние бота: аккаунт, авторизацию,
+// автоподъем и ближайший запуск
+func (b *Bot) handleStatus(chatID int64) {
+	text := "📟 <b>Статус</b>\n\n"
+	if len(b.config.Accounts) > 1 {
+		text += fmt.Sprintf("👥 Аккаунт: <b>%s</b>\n", b.activeAccountName())
+	}
+
+	if b.authorized() {
+		text += "🔐 Авторизация: <b>✅ Активна</b>\n"
+	} else {
+		text += "🔐 Авторизация: <b>❌ Требуется</b>\n"
+	}
+
+	schedules := b.accountSchedules(b.account())
+	paused := countPaused(schedules)
+	text += fmt.Sprintf("📅 Автоподъемов: <b>%d</b>", len(schedules))
+	if paused > 0 {
+		text += fmt.Sprintf(" (на паузе: %d)", paused)
+	}
+	text += "\n"
+
+	if globalPaused, until := b.scheduler.GetPause(); globalPaused {
+		text += fmt.Sprintf("🏖 Режим отпуска: <b>%s</b>\n", pauseStatusText(until))
+	} else {
+		var next time.Time
+		var nextTitle string
+		for title, schedule := range schedules {
+			if schedule.Paused {
+				continue
+			}
+			if next.IsZero() || schedule.NextRun.Before(next) {
+				next, nextTitle = schedule.NextRun, title
+			}
+		}
+		if !next.IsZero() {
+			text += fmt.Sprintf("🕐 Следующий подъем: <b>%s</b> <code>%s</code>\n", next.Format("02.01 15:04"), nextTitle)
+		}
+	}
+
+	text += "🔔 Уведомления: " + b.notificationsStatus()
+
+	b.send(newHTMLMessage(chatID, text))
+}
+
+// handleRaiseCommand поднимает резюме по части названия или ID.
+// Без аргумента или при нескольких совпадениях предлагает выбрать резюме кнопкой.
+func (b *Bot) handleRaiseCommand(chatID int64, args string) {
+	resumes, err := b.resumes()
+	if err != nil || len(resumes) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "Обновите список резюме.")
+		b.send(msg)
+		return
+	}
+
+	if strings.EqualFold(args, "all") || strings.EqualFold(args, "все") {
+		b.raiseAll(chatID)
+		return
+	}
+
+	matches := resumes
+	text := "🚀 <b>Выберите резюме для подъема</b>"
+	if args != "" {
+		matches = matchResumes(resumes, args)
+		switch len(matches) {
+		case 0:
+			matches = resumes
+			text = fmt.Sprintf("🔍 Резюме «%s» не найдено. Выберите из списка:", escapeHTML(args))
+		case 1:
+			b.raiseResume(chatID, matches[0])
+			return
+		default:
+			text = fmt.Sprintf("🔍 Под «%s» подходит несколько резюме. Выберите одно:", escapeHTML(args))
+		}
+	}
+
+	msg := newHTMLMessage(chatID, text)
+	msg.ReplyMarkup = raiseNowKeyboard(matches)
+	b.send(msg)
+}
+
+// matchResumes ищет резюме по точному ID или по вхождению в название без учета регистра
+func matchResumes(resumes []hh.Resume, query string) []hh.Resume {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matches []hh.Resume
+	for _, resume := range resumes {
+		if resume.ID == query {
+			return []hh.Resume{resume}
+		}
+		if strings.Contains(strings.ToLower(resume.Title), query) {
+			matches = append(matches, resume)
+		}
+	}
+	return matches
+}
+
+// handlePauseCommand включает режим отпуска на указанный срок
+// или, без аргумента, спрашивает срок
+func (b *Bot) handlePauseCommand(chatID int64, args string) {
+	if args == "" {
+		b.askVacation(chatID)
+		return
+	}
+
+	until, err := parseVacationUntil(args, time.Now())
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при вводе даты, используйте формат 25.12, 25.12.2025 или число дней.")
+		b.send(msg)
+		return
+	}
+	b.pauseAll(chatID, until)
+}
+
+func (b *Bot) handleUnpauseCommand(chatID int64) {
+	b.unpauseAll()
+	b.send(newHTMLMessage(chatID, "▶️ <b>Режим отпуска выключен</b>\n\nАвтоподъем возобновлен"))
+}
@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/storage"
+)
+
+const (
+	// dashboardRefreshDelay - сколько копить события перед обновлением статуса:
+	// подъемы одной минуты дают одну правку сообщения
+	dashboardRefreshDelay = 3 * time.Second
+	// dashboardEventLimit - сколько последних событий показывать в статусе
+	dashboardEventLimit = 5
+)
+
+func (b *Bot) dashboardEnabled() bool {
+	b.dashboardMutex.Lock()
+	defer b.dashboardMutex.Unlock()
+	return b.dashboard.Enabled
+}
+
+// setDashboard включает или выключает живой статус. При выключении сообщения
+// статуса открепляются и дальше не обновляются.
+func (b *Bot) setDashboard(enabled bool) {
+	b.dashboardMutex.Lock()
+	if !enabled {
+		for chatID, messageID := range b.dashboard.Messages {
+			b.request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: messageID})
+		}
+		b.dashboard.Messages = nil
+		b.dashboardTexts = make(map[int64]string)
+	}
+	b.dashboard.Enabled = enabled
+	b.saveDashboard()
+	b.dashboardMutex.Unlock()
+
+	if enabled {
+		b.refreshDashboard()
+	}
+}
+
+// saveDashboard сохраняет режим и ID сообщений статуса. Вызывается под dashboardMutex.
+func (b *Bot) saveDashboard() {
+	saved := storage.Dashboard{Enabled: b.dashboard.Enabled}
+	if len(b.dashboard.Messages) > 0 {
+		saved.Messages = make(map[int64]int, len(b.dashboard.Messages))
+		for chatID, messageID := range b.dashboard.Messages {
+			saved.Messages[chatID] = messageID
+		}
+	}
+	err := b.storage.UpdateSettings(func(settings *storage.Settings) {
+		settings.Dashboard = &saved
+	})
+	if err != nil {
+		log.Printf("Failed to save dashboard settings: %v", err)
+	}
+}
+
+// noteDashboardEvent запоминает событие для статуса и вскоре обновляет его
+func (b *Bot) noteDashboardEvent(event notify.Event) {
+	b.dashboardMutex.Lock()
+	defer b.dashboardMutex.Unlock()
+
+	b.dashboardEvents = append(b.dashboardEvents, event)
+	if len(b.dashboardEvents) > dashboardEventLimit {
+		b.dashboardEvents = b.dashboardEvents[len(b.dashboardEvents)-dashboardEventLimit:]
+	}
+	if b.dashboardTimer == nil {
+		b.dashboardTimer = time.AfterFunc(dashboardRefreshDelay, b.refreshDashboard)
+	}
+}
+
+// refreshDashboard редактирует сообщения статуса во всех чатах. Если сообщения еще нет
+// или его удалили, присылается и закрепляется новое.
+func (b *Bot) refreshDashboard() {
+	b.dashboardMutex.Lock()
+	defer b.dashboardMutex.Unlock()
+
+	b.dashboardTimer = nil
+	if !b.dashboard.Enabled {
+		return
+	}
+
+	text := b.dashboardText()
+	changed := false
+	for _, chatID := range b.config.AdminIDs() {
+		if b.dashboardTexts[chatID] == text {
+			continue
+		}
+
+		if messageID, exists := b.dashboard.Messages[chatID]; exists {
+			edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+			edit.ParseMode = "HTML"
+			_, err := b.api.Send(edit)
+			if err == nil || strings.Contains(err.Error(), "message is not modified") {
+				b.dashboardTexts[chatID] = text
+				continue
+			}
+			log.Printf("Failed to edit dashboard in chat %d, posting a new one: %v", chatID, err)
+		}
+
+		// Статус не ставится в очередь: к повторной отправке он устареет, а при следующем
+		// обновлении бот попробует снова
+		sent, err := b.api.Send(newHTMLMessage(chatID, text))
+		if err != nil {
+			log.Printf("Failed to post dashboard in chat %d: %v", chatID, err)
+			continue
+		}
+		pin := tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: sent.MessageID, DisableNotification: true}
+		if _, err := b.api.Request(pin); err != nil {
+			log.Printf("Failed to pin dashboard in chat %d: %v", chatID, err)
+		}
+
+		if b.dashboard.Messages == nil {
+			b.dashboard.Messages = make(map[int64]int)
+		}
+		b.dashboard.Messages[chatID] = sent.MessageID
+		b.dashboardTexts[chatID] = text
+		changed = true
+	}
+	if changed {
+		b.saveDashboard()
+	}
+}
+
+// dashboardText собирает статус: сессии аккаунтов, резюме с последним результатом
+// и следующим подъемом, последние события. Вызывается под dashboardMutex.
+func (b *Bot) dashboardText() string {
+	now := time.Now()
+	text := "📌 <b>Статус автоподъема</b>\n"
+	text += fmt.Sprintf("<i>обновлено %s</i>\n\n", now.Format("02.01 15:04"))
+
+	for _, account := range b.config.Accounts {
+		label := "🔐 Сессия"
+		if len(b.config.Accounts) > 1 {
+			label = "🔐 " + escapeHTML(account.Name)
+		}
+		text += fmt.Sprintf("%s: %s\n", label, b.sessionStatusText(account.Name))
+	}
+	if paused, until := b.scheduler.GetPause(); paused {
+		text += fmt.Sprintf("🏖 <b>Режим отпуска: %s</b>\n", pauseStatusText(until))
+	}
+
+	schedules := b.scheduler.GetAll()
+	if len(schedules) == 0 {
+		text += "\nВ расписании пока нет резюме.\n"
+	}
+	for _, title := range sortedTitles(schedules) {
+		schedule := schedules[title]
+		text += fmt.Sprintf("\n<code>%s</code>\n", escapeHTML(title))
+		if !schedule.LastRun.IsZero() {
+			text += fmt.Sprintf("   ✅ Поднято: %s\n", schedule.LastRun.Format("02.01 15:04"))
+		}
+		if status := schedule.LastStatus; status != 0 && status != 200 && status != 409 {
+			text += fmt.Sprintf("   ⚠️ Последний ответ: %s\n", notify.StatusText(status))
+		}
+		switch {
+		case schedule.Paused:
+			text += fmt.Sprintf("   ⏸ На паузе: <b>%s</b>\n", pauseStatusText(schedule.PausedUntil))
+		case schedule.Attempt > 0:
+			text += fmt.Sprintf("   🔁 Повтор %d/%d: <b>%s</b>\n",
+				schedule.Attempt+1, b.scheduler.RetryPolicyFor(schedule).MaxAttempts, schedule.RetryAt.Format("02.01 15:04"))
+		default:
+			text += fmt.Sprintf("   🕐 Следующий: <b>%s</b>\n", schedule.NextRun.Format("02.01 15:04"))
+		}
+	}
+
+	if len(b.dashboardEvents) > 0 {
+		text += "\n🔔 <b>Последние события</b>\n"
+		for i := len(b.dashboardEvents) - 1; i >= 0; i-- {
+			event := b.dashboardEvents[i]
+			message := notify.Render(event)
+			line := escapeHTML(message.Title)
+			if len(message.Lines) > 0 {
+				line += ": " + escapeHTML(message.Lines[0])
+			}
+			text += fmt.Sprintf("• <i>%s</i> %s\n", event.Time.Format("15:04"), line)
+		}
+	}
+	return text
+}
+
+// sessionStatusText описывает сессию аккаунта по наблюдениям планировщика
+func (b *Bot) sessionStatusText(name string) string {
+	status := b.scheduler.SessionStatus(b.config.SchedulerAccount(name))
+	switch {
+	case status.Failed:
+		return "❌ <b>вход не удался</b>"
+	case status.Expired:
+		return "⌛ истекла, выполняется вход"
+	case status.CheckedAt.IsZero():
+		return "⏳ еще не проверялась"
+	}
+	return fmt.Sprintf("✅ активна (проверена в %s)", status.CheckedAt.Format("15:04"))
+}
+
+// handleDashboardCommand включает и выключает живой статус: /dashboard on или /dashboard off
+func (b *Bot) handleDashboardCommand(chatID int64, args string) {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "on", "вкл":
+		b.setDashboard(true)
+		b.send(newHTMLMessage(chatID, "📌 <b>Живой статус включен</b>\n\nСтатус закреплен в чате и обновляется после каждого события и раз в минуту. Отдельными сообщениями приходят только ошибки.\n\nВыключить: /dashboard off"))
+	case "off", "выкл", "0":
+		b.setDashboard(false)
+		b.send(newHTMLMessage(chatID, "📌 Живой статус выключен, уведомления снова приходят отдельными сообщениями"))
+	default:
+		b.send(newHTMLMessage(chatID, "Пример: <code>/dashboard on</code> или <code>/dashboard off</code>"))
+	}
+}
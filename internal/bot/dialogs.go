@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/storage"
+)
+
+// Шаги диалогов бота
+const (
+	stateResumeList    dialog.State = "showing_resume_list"
+	stateDeleteList    dialog.State = "showing_delete_list"
+	stateTimePicker    dialog.State = "time_picker"
+	stateVacationUntil dialog.State = "vacation_until"
+	stateOneOffTime    dialog.State = "oneoff_time"
+	stateEditWindows   dialog.State = "edit_windows"
+
+	stateDigestTemplate dialog.State = "digest_template"
+)
+
+// dialogTimeout - сколько шаг ждет ответа, если для него не задано иное
+const dialogTimeout = 15 * time.Minute
+
+// dialogStep описывает поведение шага диалога
+type dialogStep struct {
+	timeout time.Duration
+	// text обрабатывает ответ пользователя; nil - шаг ждет нажатия кнопки,
+	// а любой текст завершает диалог и обрабатывается как обычное сообщение
+	text func(b *Bot, message *tgbotapi.Message, current dialog.Dialog)
+	// back показывает предыдущий экран вместо сообщения messageID (0 - новым сообщением);
+	// nil - "назад" работает как отмена
+	back func(b *Bot, chatID int64, messageID int, current dialog.Dialog)
+}
+
+// dialogSteps заполняется в init: обработчики шагов сами начинают и завершают диалоги
+var dialogSteps map[dialog.State]dialogStep
+
+func init() {
+	dialogSteps = map[dialog.State]dialogStep{
+		stateResumeList: {timeout: 30 * time.Minute},
+		stateDeleteList: {timeout: 30 * time.Minute},
+		stateTimePicker: {
+			timeout: 30 * time.Minute,
+			text:    (*Bot).handleTimePickerText,
+			back:    (*Bot).timePickerBack,
+		},
+		stateVacationUntil: {
+			timeout: 10 * time.Minute,
+			text:    func(b *Bot, message *tgbotapi.Message, _ dialog.Dialog) { b.handleVacationUntil(message) },
+			back: func(b *Bot, chatID int64, messageID int, _ dialog.Dialog) {
+				b.handleShowSchedule(chatID, editID(messageID)...)
+			},
+		},
+		stateOneOffTime: {
+			timeout: 10 * time.Minute,
+			text:    (*Bot).handleOneOffTime,
+			back: func(b *Bot, chatID int64, messageID int, _ dialog.Dialog) {
+				b.sendOneOffList(chatID, editID(messageID)...)
+			},
+		},
+		stateEditWindows: {
+			timeout: 10 * time.Minute,
+			text:    (*Bot).handleEditWindows,
+			back: func(b *Bot, chatID int64, _ int, current dialog.Dialog) {
+				b.finishEdit(chatID, current)
+			},
+		},
+		stateDigestTemplate: {
+			timeout: 30 * time.Minute,
+			text:    func(b *Bot, message *tgbotapi.Message, _ dialog.Dialog) { b.handleDigestTemplate(message) },
+			back: func(b *Bot, chatID int64, messageID int, _ dialog.Dialog) {
+				b.handleDigest(chatID, editID(messageID)...)
+			},
+		},
+	}
+}
+
+// newDialogs создает хранилище диалогов бота и восстанавливает незавершенные диалоги
+func newDialogs(store *storage.Storage) *dialog.Manager {
+	timeouts := make(map[dialog.State]time.Duration)
+	for state, step := range dialogSteps {
+		timeouts[state] = step.timeout
+	}
+
+	dialogs := dialog.New(dialogTimeout, timeouts)
+	if saved, err := store.LoadDialogs(); err != nil {
+		log.Printf("Failed to load dialogs: %v", err)
+	} else {
+		dialogs.Restore(saved)
+	}
+	dialogs.SetSaveHandler(func(snapshot map[int64]dialog.Dialog) {
+		if err := store.SaveDialogs(snapshot); err != nil {
+			log.Printf("Failed to save dialogs: %v", err)
+		}
+	})
+	return dialogs
+}
+
+// startDialogExpiry сообщает пользователю о диалогах, сброшенных по таймауту
+func (b *Bot) startDialogExpiry() {
+	b.dialogs.StartExpiry(time.Minute, func(chatID int64, _ dialog.Dialog) {
+		b.sendDialogExpired(chatID)
+	})
+}
+
+func (b *Bot) sendDialogExpired(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, "⌛ Время ввода истекло, действие отменено.")
+	b.send(msg)
+}
+
+// handleDialog передает сообщение активному шагу диалога.
+// Возвращает false, если сообщение нужно обработать как обычную команду.
+func (b *Bot) handleDialog(message *tgbotapi.Message, current dialog.Dialog) bool {
+	chatID := message.Chat.ID
+
+	switch text := strings.ToLower(strings.TrimSpace(message.Text)); {
+	case text == "/cancel" || text == "отмена":
+		b.cancelDialog(chatID)
+		return true
+	case text == "/back" || text == "назад":
+		b.dialogBack(chatID, 0)
+		return true
+	case message.IsCommand():
+		// Команда прерывает незавершенный ввод
+		b.dialogs.Clear(chatID)
+		return false
+	}
+
+	step := dialogSteps[current.State]
+	if step.text == nil {
+		b.dialogs.Clear(chatID)
+		return false
+	}
+	step.text(b, message, current)
+	return true
+}
+
+// handleDialogCallback обрабатывает общие кнопки диалогов "назад" и "отмена"
+func (b *Bot) handleDialogCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+
+	switch strings.TrimPrefix(callback.Data, "dialog:") {
+	case "back":
+		b.dialogBack(chatID, callback.Message.MessageID)
+	case "cancel":
+		b.request(tgbotapi.NewDeleteMessage(chatID, callback.Message.MessageID))
+		b.cancelDialog(chatID)
+	}
+}
+
+// cancelDialog завершает диалог и возвращает в главное меню
+func (b *Bot) cancelDialog(chatID int64) {
+	if _, exists := b.dialogs.Clear(chatID); exists {
+		msg := tgbotapi.NewMessage(chatID, "❌ Действие отменено")
+		b.send(msg)
+	}
+	b.sendMainMenu(chatID)
+}
+
+// dialogBack возвращает пользователя на экран, с которого начался шаг
+func (b *Bot) dialogBack(chatID int64, messageID int) {
+	current, err := b.dialogs.Get(chatID)
+	if err != nil {
+		b.sendDialogExpired(chatID)
+		return
+	}
+
+	step := dialogSteps[current.State]
+	if step.back == nil {
+		b.cancelDialog(chatID)
+		return
+	}
+	b.dialogs.Clear(chatID)
+	step.back(b, chatID, messageID, current)
+}
+
+// dialogButtonRow - кнопки "назад" и "отмена" для шагов с вводом текста
+func dialogButtonRow() []tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "dialog:back"),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "dialog:cancel"),
+	)
+}
+
+// dialogMessageID возвращает ID сообщения, сохраненный в данных шага
+func dialogMessageID(current dialog.Dialog, key string) (int, bool) {
+	messageID, err := strconv.Atoi(current.Data[key])
+	return messageID, err == nil
+}
+
+// editID превращает ID сообщения в необязательный аргумент sendOrEdit (0 - отправить новое)
+func editID(messageID int) []int {
+	if messageID == 0 {
+		return nil
+	}
+	return []int{messageID}
+}
@@ -0,0 +1,276 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/digest"
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/storage"
+)
+
+// Расписания сводок, которые включаются кнопкой; другие задаются командой /digest
+const (
+	digestDailyPreset  = "09:00"
+	digestWeeklyPreset = "пн 10:00"
+)
+
+// digestPeriods - периоды сводок в порядке отправки
+var digestPeriods = []digest.Period{digest.Daily, digest.Weekly}
+
+func (b *Bot) digestSettings() digest.Settings {
+	b.digestMutex.Lock()
+	defer b.digestMutex.Unlock()
+	return b.digest.Clone()
+}
+
+// updateDigest изменяет настройки сводок и сохраняет их
+func (b *Bot) updateDigest(change func(settings *digest.Settings)) {
+	b.digestMutex.Lock()
+	defer b.digestMutex.Unlock()
+
+	change(&b.digest)
+	saved := b.digest.Clone()
+	err := b.storage.UpdateSettings(func(settings *storage.Settings) {
+		settings.Digest = &saved
+	})
+	if err != nil {
+		log.Printf("Failed to save digest settings: %v", err)
+	}
+}
+
+// sendDueDigests отправляет сводки, время которых наступило
+func (b *Bot) sendDueDigests() {
+	now := time.Now()
+	for _, period := range digestPeriods {
+		at, due := b.digestSettings().Due(period, now)
+		if !due {
+			continue
+		}
+
+		text, stats := b.buildDigest(period, at)
+		b.updateDigest(func(settings *digest.Settings) {
+			settings.MarkSent(period, at, stats)
+		})
+		b.sendNotifications([]string{text}, false)
+	}
+}
+
+// buildDigest собирает сводку за период, закончившийся в to, и возвращает
+// ее текст и текущие счетчики резюме
+func (b *Bot) buildDigest(period digest.Period, to time.Time) (string, map[string]hh.ResumeStats) {
+	settings := b.digestSettings()
+
+	history, err := b.storage.LoadHistory("", 0)
+	if err != nil {
+		log.Printf("Failed to load raise history for digest: %v", err)
+	}
+	paused, _ := b.scheduler.GetPause()
+	stats := b.resumeStats()
+
+	data := digest.Build(period, to, digest.Source{
+		Schedules: b.scheduler.GetAll(),
+		History:   history,
+		Logins:    b.scheduler.LoginCount(to.Add(-period.Duration())),
+		Paused:    paused,
+		Stats:     stats,
+		Previous:  settings.Stats[period],
+	})
+
+	text, err := digest.Render(settings.Template, data)
+	if err != nil {
+		// Шаблон проверяется при сохранении, но сводка не должна потеряться
+		log.Printf("Failed to render digest template: %v", err)
+		text, _ = digest.Render("", data)
+	}
+	return text, stats
+}
+
+// resumeStats возвращает счетчики резюме всех аккаунтов по ID резюме, если hh.ru их показывает
+func (b *Bot) resumeStats() map[string]hh.ResumeStats {
+	stats := make(map[string]hh.ResumeStats)
+	for _, cache := range b.resumeCaches {
+		resumes, err := cache.Resumes()
+		if err != nil {
+			continue
+		}
+		for _, resume := range resumes {
+			if resume.Stats != nil {
+				stats[resume.ID] = *resume.Stats
+			}
+		}
+	}
+	return stats
+}
+
+// handleDigest показывает расписание сводок и шаблон
+func (b *Bot) handleDigest(chatID int64, editMessageID ...int) {
+	settings := b.digestSettings()
+
+	text := "📰 <b>Сводки</b>\n\n"
+	text += "Сводка собирает подъемы и ошибки по резюме, просмотры и приглашения (если hh.ru их показывает), повторные входы и ближайшие подъемы.\n\n"
+	text += fmt.Sprintf("☀️ Ежедневная: <b>%s</b>\n", digestScheduleText(settings.Daily))
+	text += fmt.Sprintf("📅 Еженедельная: <b>%s</b>\n", digestScheduleText(settings.Weekly))
+	if settings.Template == "" {
+		text += "📝 Шаблон: <b>стандартный</b>\n"
+	} else {
+		text += "📝 Шаблон: <b>свой</b>\n"
+	}
+	text += "\n💡 <i>Другое время: /digest daily 08:30, /digest weekly пт 18:00; выключить: /digest daily off</i>"
+
+	dailyButton := tgbotapi.NewInlineKeyboardButtonData("☀️ Ежедневно в "+digestDailyPreset, "digest_daily:"+digestDailyPreset)
+	if settings.Daily != "" {
+		dailyButton = tgbotapi.NewInlineKeyboardButtonData("☀️ Выключить ежедневную", "digest_daily:off")
+	}
+	weeklyButton := tgbotapi.NewInlineKeyboardButtonData("📅 По понедельникам в 10:00", "digest_weekly:"+digestWeeklyPreset)
+	if settings.Weekly != "" {
+		weeklyButton = tgbotapi.NewInlineKeyboardButtonData("📅 Выключить еженедельную", "digest_weekly:off")
+	}
+
+	templateRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить шаблон", "digest_template"),
+	)
+	if settings.Template != "" {
+		templateRow = append(templateRow, tgbotapi.NewInlineKeyboardButtonData("♻️ Стандартный шаблон", "digest_template:reset"))
+	}
+
+	markup := b.inlineKeyboardFor(chatID,
+		tgbotapi.NewInlineKeyboardRow(dailyButton),
+		tgbotapi.NewInlineKeyboardRow(weeklyButton),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📨 Сводка за сутки", "digest_send:"+string(digest.Daily)),
+			tgbotapi.NewInlineKeyboardButtonData("📨 За неделю", "digest_send:"+string(digest.Weekly)),
+		),
+		templateRow,
+	)
+	b.sendOrEdit(chatID, text, markup, editMessageID...)
+}
+
+// handleDigestCommand настраивает сводки: /digest daily 09:00, /digest weekly пн 10:00, /digest daily off
+func (b *Bot) handleDigestCommand(chatID int64, args string) {
+	kind, value, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch strings.ToLower(kind) {
+	case "":
+		b.handleDigest(chatID)
+		return
+	case "daily", "день", "ежедневно":
+		if err := b.setDigestSchedule(digest.Daily, value); err != nil {
+			b.send(newHTMLMessage(chatID, "❌ "+escapeHTML(err.Error())))
+			return
+		}
+	case "weekly", "неделя", "еженедельно":
+		if err := b.setDigestSchedule(digest.Weekly, value); err != nil {
+			b.send(newHTMLMessage(chatID, "❌ "+escapeHTML(err.Error())))
+			return
+		}
+	default:
+		b.send(newHTMLMessage(chatID, "Пример: <code>/digest daily 09:00</code>, <code>/digest weekly пн 10:00</code> или <code>/digest daily off</code>"))
+		return
+	}
+	b.handleDigest(chatID)
+}
+
+// setDigestSchedule задает расписание сводки периода; off - выключает ее.
+// Сводка за уже наступившее сегодня время не отправляется.
+func (b *Bot) setDigestSchedule(period digest.Period, value string) error {
+	value = strings.TrimSpace(value)
+	schedule := ""
+	switch strings.ToLower(value) {
+	case "off", "выкл", "0":
+	default:
+		if period == digest.Weekly {
+			weekday, minute, err := digest.ParseWeekly(value)
+			if err != nil {
+				return err
+			}
+			schedule = digest.FormatWeekly(weekday, minute)
+		} else {
+			minute, err := digest.ParseDaily(value)
+			if err != nil {
+				return err
+			}
+			schedule = fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+		}
+	}
+
+	b.updateDigest(func(settings *digest.Settings) {
+		if period == digest.Weekly {
+			settings.Weekly = schedule
+		} else {
+			settings.Daily = schedule
+		}
+		settings.MarkSent(period, time.Now(), nil)
+	})
+	return nil
+}
+
+// handleDigestCallback обрабатывает кнопки экрана сводок
+func (b *Bot) handleDigestCallback(callback *tgbotapi.CallbackQuery) {
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	action, value, _ := strings.Cut(callback.Data, ":")
+
+	switch action {
+	case "digest":
+		b.handleDigest(chatID, messageID)
+	case "digest_daily":
+		b.setDigestSchedule(digest.Daily, value)
+		b.handleDigest(chatID, messageID)
+	case "digest_weekly":
+		b.setDigestSchedule(digest.Weekly, value)
+		b.handleDigest(chatID, messageID)
+	case "digest_send":
+		text, _ := b.buildDigest(digest.Period(value), time.Now())
+		b.send(newHTMLMessage(chatID, text))
+	case "digest_template":
+		if value == "reset" {
+			b.updateDigest(func(settings *digest.Settings) { settings.Template = "" })
+			b.handleDigest(chatID, messageID)
+			return
+		}
+		b.askDigestTemplate(chatID)
+	}
+}
+
+// askDigestTemplate показывает текущий шаблон и ждет новый
+func (b *Bot) askDigestTemplate(chatID int64) {
+	current := b.digestSettings().Template
+	if current == "" {
+		current = digest.DefaultTemplate
+	}
+	b.dialogs.Start(chatID, stateDigestTemplate, nil)
+
+	text := "✏️ <b>Шаблон сводки</b>\n\n"
+	text += "Пришлите новый шаблон одним сообщением. Это шаблон Go (html/template) с разметкой HTML Telegram.\n\n"
+	text += "<b>Поля:</b> .Title, .From, .To, .Raised, .Failed, .Relogins, .Paused, "
+	text += ".Resumes (.Title, .Raised, .Failed, .LastRun, .NextRun, .Paused, .HasStats, .Views, .Invitations, .Gained), "
+	text += ".Upcoming (.Title, .At)\n"
+	text += "<b>Функции:</b> date, datetime, clock\n\n"
+	text += "Текущий шаблон:\n<pre>" + escapeHTML(current) + "</pre>"
+	b.send(newHTMLMessage(chatID, text))
+}
+
+// handleDigestTemplate проверяет присланный шаблон, сохраняет его и показывает пример сводки
+func (b *Bot) handleDigestTemplate(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	if err := digest.Validate(message.Text); err != nil {
+		b.send(newHTMLMessage(chatID, "❌ "+escapeHTML(err.Error())+"\n\nИсправьте шаблон и пришлите снова или напишите <code>отмена</code>."))
+		return
+	}
+
+	b.dialogs.Clear(chatID)
+	b.updateDigest(func(settings *digest.Settings) { settings.Template = message.Text })
+	b.send(newHTMLMessage(chatID, "✅ Шаблон сохранен. Так выглядит сводка за сутки:"))
+	text, _ := b.buildDigest(digest.Daily, time.Now())
+	b.send(newHTMLMessage(chatID, text))
+}
+
+func digestScheduleText(schedule string) string {
+	if schedule == "" {
+		return "выключена"
+	}
+	return schedule
+}
@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+// maxConcurrentUpdates - сколько обновлений из разных чатов обрабатывается одновременно
+const maxConcurrentUpdates = 16
+
+// PanicHandler получает панику обработчика обновления чата chatID
+type PanicHandler func(chatID int64, recovered interface{}, stack []byte)
+
+// dispatcher обрабатывает обновления параллельно, сохраняя порядок внутри каждого чата:
+// медленный запрос к hh.ru в одном чате не задерживает остальные.
+// У чата с необработанными обновлениями есть ровно одна горутина, которая
+// разбирает его очередь и завершается, когда очередь пуста.
+type dispatcher struct {
+	handle  func(update tgbotapi.Update)
+	onPanic PanicHandler
+	queues  map[int64][]tgbotapi.Update
+	slots   chan struct{}
+	closed  bool
+	mutex   sync.Mutex
+	wg      sync.WaitGroup
+}
+
+func newDispatcher(handle func(update tgbotapi.Update), onPanic PanicHandler) *dispatcher {
+	return &dispatcher{
+		handle:  handle,
+		onPanic: onPanic,
+		queues:  make(map[int64][]tgbotapi.Update),
+		slots:   make(chan struct{}, maxConcurrentUpdates),
+	}
+}
+
+// Dispatch ставит обновление в очередь его чата. После Shutdown обновления отбрасываются.
+func (d *dispatcher) Dispatch(update tgbotapi.Update) {
+	chatID := updateChatID(update)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		log.Printf("Dropping update %d: bot is stopping", update.UpdateID)
+		return
+	}
+
+	queue, running := d.queues[chatID]
+	d.queues[chatID] = append(queue, update)
+	if !running {
+		d.wg.Add(1)
+		go d.run(chatID)
+	}
+}
+
+// Shutdown перестает принимать обновления и ждет обработки уже поставленных в очередь
+func (d *dispatcher) Shutdown() {
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *dispatcher) run(chatID int64) {
+	defer d.wg.Done()
+
+	for {
+		d.mutex.Lock()
+		queue := d.queues[chatID]
+		if len(queue) == 0 {
+			delete(d.queues, chatID)
+			d.mutex.Unlock()
+			return
+		}
+		update := queue[0]
+		d.queues[chatID] = queue[1:]
+		d.mutex.Unlock()
+
+		d.slots <- struct{}{}
+		d.process(chatID, update)
+		<-d.slots
+	}
+}
+
+// process обрабатывает обновление; паника обработчика не роняет бота
+func (d *dispatcher) process(chatID int64, update tgbotapi.Update) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			stack := debug.Stack()
+			log.Printf("Panic while handling update %d in chat %d: %v\n%s", update.UpdateID, chatID, recovered, stack)
+			if d.onPanic != nil {
+				d.onPanic(chatID, recovered, stack)
+			}
+		}
+	}()
+
+	d.handle(update)
+}
+
+// updateLoop получает обновления через вебхук или long polling и передает их диспетчеру
+type updateLoop struct {
+	api        *tgbotapi.BotAPI
+	dispatcher *dispatcher
+	updates    tgbotapi.UpdatesChannel
+	stopped    chan struct{}
+	stopOnce   sync.Once
+
+	webhook       config.TelegramWebhook
+	receiver      *webhookReceiver
+	receiverMutex sync.Mutex
+}
+
+func newUpdateLoop(api *tgbotapi.BotAPI, webhook config.TelegramWebhook, dispatcher *dispatcher) *updateLoop {
+	return &updateLoop{
+		api:        api,
+		dispatcher: dispatcher,
+		stopped:    make(chan struct{}),
+		webhook:    webhook,
+	}
+}
+
+// run работает до остановки через stop (возвращает ErrStopped) или до закрытия канала обновлений.
+// При перезапуске после сбоя продолжает читать тот же канал, чтобы не запускать второй опрос.
+func (l *updateLoop) run() error {
+	if l.updates == nil {
+		l.updates = l.receive()
+	}
+
+	for {
+		select {
+		case <-l.stopped:
+			l.dispatcher.Shutdown()
+			return ErrStopped
+		case update, ok := <-l.updates:
+			if !ok {
+				l.updates = nil
+				return errors.New("updates channel closed")
+			}
+			l.dispatcher.Dispatch(update)
+		}
+	}
+}
+
+// receive начинает получать обновления: через вебхук, если он настроен, иначе long polling.
+// Если вебхук запустить не удалось, бот продолжает работать через long polling.
+func (l *updateLoop) receive() tgbotapi.UpdatesChannel {
+	if l.webhook.URL != "" {
+		receiver, err := startWebhook(l.api, l.webhook)
+		if err == nil {
+			l.receiverMutex.Lock()
+			l.receiver = receiver
+			l.receiverMutex.Unlock()
+			return receiver.updates
+		}
+		log.Printf("Failed to start webhook, falling back to long polling: %v", err)
+	}
+
+	// Пока вебхук зарегистрирован, Telegram не отдает обновления через getUpdates:
+	// удаляем оставшийся от прошлого запуска
+	if _, err := l.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("Failed to delete webhook: %v", err)
+	}
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	return l.api.GetUpdatesChan(u)
+}
+
+// stop прекращает получение обновлений и ждет обработки полученных
+func (l *updateLoop) stop() {
+	l.stopOnce.Do(func() {
+		close(l.stopped)
+		l.receiverMutex.Lock()
+		receiver := l.receiver
+		l.receiverMutex.Unlock()
+		if receiver != nil {
+			receiver.stop()
+		} else {
+			l.api.StopReceivingUpdates()
+		}
+	})
+	l.dispatcher.Shutdown()
+}
+
+// updateChatID возвращает чат, к которому относится обновление
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	}
+	return 0
+}
+
+// panicReport - текст сообщения администратору о панике обработчика
+func panicReport(chatID int64, recovered interface{}) string {
+	text := "⚠️ <b>Ошибка при обработке сообщения</b>\n\n"
+	text += fmt.Sprintf("Чат: <code>%d</code>\n", chatID)
+	text += fmt.Sprintf("Ошибка: <code>%s</code>\n\n", escapeHTML(fmt.Sprint(recovered)))
+	text += "<i>Подробности в логах бота</i>"
+	return text
+}
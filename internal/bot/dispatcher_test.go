@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func chatUpdate(updateID int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{
+		UpdateID: updateID,
+		Message:  &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}},
+	}
+}
+
+func TestDispatcherKeepsChatOrder(t *testing.T) {
+	const chats, perChat = 5, 20
+
+	var mutex sync.Mutex
+	handled := make(map[int64][]int)
+	d := newDispatcher(func(update tgbotapi.Update) {
+		// Разная длительность обработки не должна менять порядок внутри чата
+		time.Sleep(time.Duration(update.UpdateID%3) * time.Millisecond)
+		chatID := update.Message.Chat.ID
+		mutex.Lock()
+		handled[chatID] = append(handled[chatID], update.UpdateID)
+		mutex.Unlock()
+	}, nil)
+
+	for i := 0; i < perChat; i++ {
+		for chatID := int64(1); chatID <= chats; chatID++ {
+			d.Dispatch(chatUpdate(int(chatID)*1000+i, chatID))
+		}
+	}
+	d.Shutdown()
+
+	for chatID := int64(1); chatID <= chats; chatID++ {
+		got := handled[chatID]
+		if len(got) != perChat {
+			t.Fatalf("chat %d: handled %d updates, want %d", chatID, len(got), perChat)
+		}
+		for i, updateID := range got {
+			if want := int(chatID)*1000 + i; updateID != want {
+				t.Fatalf("chat %d: update #%d = %d, want %d", chatID, i, updateID, want)
+			}
+		}
+	}
+}
+
+func TestDispatcherSlowChatDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	done := make(chan int64, 1)
+	d := newDispatcher(func(update tgbotapi.Update) {
+		if update.Message.Chat.ID == 1 {
+			<-release
+			return
+		}
+		done <- update.Message.Chat.ID
+	}, nil)
+	defer d.Shutdown()
+	defer close(release)
+
+	d.Dispatch(chatUpdate(1, 1))
+	d.Dispatch(chatUpdate(2, 2))
+
+	select {
+	case chatID := <-done:
+		if chatID != 2 {
+			t.Errorf("handled chat %d, want 2", chatID)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatal("update of another chat waited for the slow one")
+	}
+}
+
+func TestDispatcherRecoversPanic(t *testing.T) {
+	type report struct {
+		chatID    int64
+		recovered interface{}
+	}
+	reports := make(chan report, 1)
+	var handled []int
+
+	d := newDispatcher(func(update tgbotapi.Update) {
+		if update.UpdateID == 1 {
+			panic("boom")
+		}
+		handled = append(handled, update.UpdateID)
+	}, func(chatID int64, recovered interface{}, stack []byte) {
+		if len(stack) == 0 {
+			t.Error("panic reported without stack")
+		}
+		reports <- report{chatID, recovered}
+	})
+
+	d.Dispatch(chatUpdate(1, 42))
+	d.Dispatch(chatUpdate(2, 42))
+	d.Shutdown()
+
+	select {
+	case got := <-reports:
+		if got.chatID != 42 || got.recovered != "boom" {
+			t.Errorf("panic report = %d, %v, want 42, boom", got.chatID, got.recovered)
+		}
+	default:
+		t.Fatal("panic was not reported")
+	}
+	// Следующее обновление того же чата обрабатывается после паники
+	if len(handled) != 1 || handled[0] != 2 {
+		t.Errorf("handled after panic = %v, want [2]", handled)
+	}
+}
+
+func TestDispatcherDropsAfterShutdown(t *testing.T) {
+	calls := 0
+	d := newDispatcher(func(tgbotapi.Update) { calls++ }, nil)
+	d.Shutdown()
+
+	d.Dispatch(chatUpdate(1, 42))
+	d.Shutdown()
+	if calls != 0 {
+		t.Errorf("handled %d updates after shutdown, want 0", calls)
+	}
+}
+
+func TestPanicReportedToUserAndAdmin(t *testing.T) {
+	tb := startTestBot(t, testHHPassword)
+	// Ждем, пока бот запустится, чтобы тест не завершился посреди Start
+	tb.send(t, "/start", "Добро пожаловать")
+	const chatID int64 = 2002
+
+	d := newDispatcher(func(tgbotapi.Update) { panic("boom <b>") }, tb.bot.reportPanic)
+	d.Dispatch(chatUpdate(1, chatID))
+	d.Shutdown()
+
+	if _, err := tb.telegram.WaitText(chatID, waitTimeout, "Что-то пошло не так"); err != nil {
+		t.Fatalf("waiting for user notice: %v", err)
+	}
+	alert := tb.wait(t, "Ошибка при обработке сообщения")
+	for _, want := range []string{"2002", "boom &lt;b&gt;"} {
+		if !strings.Contains(alert.Text, want) {
+			t.Errorf("admin alert %q does not contain %q", alert.Text, want)
+		}
+	}
+}
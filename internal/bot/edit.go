@@ -0,0 +1,295 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+var (
+	editIntervalHours = []int{4, 6, 8, 12, 24}
+	editJitterMinutes = []int{0, 5, 15, 30}
+	editRetryAttempts = []int{0, 1, 3, 5, 10} // 0 - общая политика RETRY_*
+)
+
+// handleEditSchedule показывает карточку редактирования расписания резюме
+func (b *Bot) handleEditSchedule(chatID int64, title string, editMessageID ...int) {
+	schedule, exists := b.scheduler.GetAll()[title]
+	if !exists {
+		msg := tgbotapi.NewMessage(chatID, "Резюме не найдено в расписании")
+		b.send(msg)
+		return
+	}
+
+	text := "✏️ <b>Редактирование расписания</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n\n", title)
+	text += formatScheduleSettings(schedule.Settings())
+	text += formatRetryPolicy(b.scheduler.RetryPolicyFor(schedule), schedule.Retry == nil)
+	text += fmt.Sprintf("🕐 Следующий запуск: <i>%s</i>\n", schedule.NextRun.Format("02.01 15:04"))
+	if !schedule.LastRun.IsZero() {
+		text += fmt.Sprintf("✅ Последний: <i>%s</i>\n", schedule.LastRun.Format("02.01 15:04"))
+	}
+	text += "\n💡 <i>История подъемов сохраняется, внепланового подъема не будет</i>"
+
+	var intervalRow, jitterRow, retryRow []tgbotapi.InlineKeyboardButton
+	for _, hours := range editIntervalHours {
+		label := fmt.Sprintf("%d ч", hours)
+		if len(schedule.Times) == 0 && schedule.GetInterval() == time.Duration(hours)*time.Hour {
+			label = "• " + label
+		}
+		intervalRow = append(intervalRow,
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("edit_interval:%d:%s", hours, schedule.ResumeID)))
+	}
+	for _, minutes := range editJitterMinutes {
+		label := fmt.Sprintf("до %d мин", minutes)
+		if minutes == 0 {
+			label = "без разброса"
+		}
+		if schedule.Jitter == time.Duration(minutes)*time.Minute {
+			label = "• " + label
+		}
+		jitterRow = append(jitterRow,
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("edit_jitter:%d:%s", minutes, schedule.ResumeID)))
+	}
+	for _, attempts := range editRetryAttempts {
+		label := fmt.Sprintf("%d попыт.", attempts)
+		selected := schedule.Retry != nil && schedule.Retry.MaxAttempts == attempts
+		if attempts == 0 {
+			label = "общие"
+			selected = schedule.Retry == nil
+		}
+		if selected {
+			label = "• " + label
+		}
+		retryRow = append(retryRow,
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("edit_retry:%d:%s", attempts, schedule.ResumeID)))
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Время и дни", "edit_time:"+schedule.ResumeID),
+			tgbotapi.NewInlineKeyboardButtonData("🪟 Окна", "edit_windows:"+schedule.ResumeID),
+		),
+		intervalRow,
+		jitterRow,
+		retryRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ К расписанию", "schedule_edit"),
+		),
+	)
+	b.sendOrEdit(chatID, text, markup, editMessageID...)
+}
+
+func (b *Bot) handleEditCallback(callback *tgbotapi.CallbackQuery) {
+	// Если резюме уже нет в расписании, карточка сообщит об этом
+	title, _, _ := b.scheduleByResumeID(strings.TrimPrefix(callback.Data, "edit:"))
+	b.handleEditSchedule(callback.Message.Chat.ID, title, callback.Message.MessageID)
+}
+
+// handleEditIntervalCallback меняет интервал подъема
+func (b *Bot) handleEditIntervalCallback(callback *tgbotapi.CallbackQuery) {
+	hours, title, ok := b.splitEditValue(callback.Data, "edit_interval:")
+	if !ok {
+		return
+	}
+	b.updateSchedule(title, func(settings *scheduler.ScheduleSettings) {
+		// Интервал заменяет фиксированное время подъемов
+		settings.Interval = time.Duration(hours) * time.Hour
+		settings.Times = nil
+	})
+	b.handleEditSchedule(callback.Message.Chat.ID, title, callback.Message.MessageID)
+}
+
+// handleEditJitterCallback меняет случайный разброс времени подъема
+func (b *Bot) handleEditJitterCallback(callback *tgbotapi.CallbackQuery) {
+	minutes, title, ok := b.splitEditValue(callback.Data, "edit_jitter:")
+	if !ok {
+		return
+	}
+	b.updateSchedule(title, func(settings *scheduler.ScheduleSettings) {
+		settings.Jitter = time.Duration(minutes) * time.Minute
+	})
+	b.handleEditSchedule(callback.Message.Chat.ID, title, callback.Message.MessageID)
+}
+
+// handleEditRetryCallback задает число попыток подъема резюме; 0 - общая политика
+func (b *Bot) handleEditRetryCallback(callback *tgbotapi.CallbackQuery) {
+	attempts, title, ok := b.splitEditValue(callback.Data, "edit_retry:")
+	if !ok {
+		return
+	}
+
+	var policy *scheduler.RetryPolicy
+	if attempts > 0 {
+		// Задержки остаются общими, меняется только число попыток
+		custom := b.scheduler.GetRetryPolicy()
+		custom.MaxAttempts = attempts
+		policy = &custom
+	}
+	if b.scheduler.SetResumeRetryPolicy(title, policy) {
+		b.saveState()
+	}
+	b.handleEditSchedule(callback.Message.Chat.ID, title, callback.Message.MessageID)
+}
+
+// handleEditTimeCallback открывает выбор времени, интервала и дней недели
+func (b *Bot) handleEditTimeCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	title, schedule, exists := b.scheduleByResumeID(strings.TrimPrefix(callback.Data, "edit_time:"))
+	if !exists {
+		return
+	}
+	b.startTimePicker(chatID, callback.Message.MessageID, timePicker{
+		Account:  schedule.Account,
+		Title:    title,
+		ResumeID: schedule.ResumeID,
+		Edit:     true,
+		Settings: schedule.Settings(),
+	})
+}
+
+// handleEditWindowsCallback запрашивает окна, в которые разрешен подъем
+func (b *Bot) handleEditWindowsCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	title, _, exists := b.scheduleByResumeID(strings.TrimPrefix(callback.Data, "edit_windows:"))
+	if !exists {
+		return
+	}
+
+	b.dialogs.Start(chatID, stateEditWindows, map[string]string{
+		"title":           title,
+		"edit_message_id": strconv.Itoa(callback.Message.MessageID),
+	})
+
+	text := fmt.Sprintf("🪟 <b>Окна подъема</b>\n\nРезюме: <code>%s</code>\n\n", title)
+	text += "📋 Введите одно или несколько окон через запятую,\n"
+	text += "например <code>08:00-12:00, 14:00-22:00</code>\n\n"
+	text += "<code>-</code> - поднимать в любое время суток"
+	msg := newHTMLMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(dialogButtonRow())
+	b.send(msg)
+}
+
+// handleEditWindows обрабатывает введенные окна подъема
+func (b *Bot) handleEditWindows(message *tgbotapi.Message, state dialog.Dialog) {
+	chatID := message.Chat.ID
+
+	var windows []scheduler.TimeWindow
+	if text := strings.TrimSpace(message.Text); text != "-" {
+		for _, part := range strings.Split(text, ",") {
+			window, err := scheduler.ParseTimeWindow(part)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatID, "Ошибка при вводе окна, используйте формат 08:00-22:00.")
+				b.send(msg)
+				return
+			}
+			windows = append(windows, window)
+		}
+	}
+
+	title := state.Data["title"]
+	b.updateSchedule(title, func(settings *scheduler.ScheduleSettings) {
+		settings.Windows = windows
+	})
+	b.finishEdit(chatID, state)
+}
+
+// finishEdit завершает ввод значения и обновляет карточку редактирования
+func (b *Bot) finishEdit(chatID int64, state dialog.Dialog) {
+	b.dialogs.Clear(chatID)
+
+	if messageID, err := strconv.Atoi(state.Data["edit_message_id"]); err == nil {
+		b.handleEditSchedule(chatID, state.Data["title"], messageID)
+		return
+	}
+	b.handleEditSchedule(chatID, state.Data["title"])
+}
+
+// updateSchedule применяет изменение к настройкам расписания и сохраняет его
+func (b *Bot) updateSchedule(title string, change func(settings *scheduler.ScheduleSettings)) bool {
+	schedule, exists := b.scheduler.GetAll()[title]
+	if !exists {
+		return false
+	}
+
+	settings := schedule.Settings()
+	change(&settings)
+	if !b.scheduler.UpdateResume(title, settings) {
+		return false
+	}
+	b.saveState()
+	return true
+}
+
+// splitEditValue разбирает callback вида prefix<число>:<ID резюме> и возвращает число
+// и ключ расписания резюме. В данных кнопки ID, потому что название может не поместиться
+// в 64 байта callback_data.
+func (b *Bot) splitEditValue(data, prefix string) (int, string, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(data, prefix), ":", 2)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+	value, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", false
+	}
+	title, _, exists := b.scheduleByResumeID(parts[1])
+	return value, title, exists
+}
+
+// formatScheduleSettings описывает параметры расписания для карточек
+func formatScheduleSettings(settings scheduler.ScheduleSettings) string {
+	text := fmt.Sprintf("⏰ Время: <b>%s</b>\n", formatTimes(settings))
+	if len(settings.Times) == 0 {
+		text += fmt.Sprintf("🔁 Интервал: <b>%s</b>\n", formatInterval(settings.GetInterval()))
+	}
+	text += fmt.Sprintf("📆 Дни: <b>%s</b>\n", formatWeekdays(settings.Weekdays))
+
+	if len(settings.Windows) > 0 {
+		windows := make([]string, len(settings.Windows))
+		for i, window := range settings.Windows {
+			windows[i] = window.String()
+		}
+		text += fmt.Sprintf("🪟 Окна: <b>%s</b>\n", strings.Join(windows, ", "))
+	} else {
+		text += "🪟 Окна: <b>круглосуточно</b>\n"
+	}
+
+	if settings.Jitter > 0 {
+		text += fmt.Sprintf("🎲 Разброс: <b>до %d мин</b>\n", int(settings.Jitter.Minutes()))
+	} else {
+		text += "🎲 Разброс: <b>нет</b>\n"
+	}
+	return text
+}
+
+// formatRetryPolicy описывает политику повторов резюме для карточки редактирования
+func formatRetryPolicy(policy scheduler.RetryPolicy, shared bool) string {
+	text := fmt.Sprintf("♻️ Повторы: <b>до %d попыток</b>", policy.MaxAttempts)
+	if shared {
+		text += " (общая настройка)"
+	}
+	return text + "\n"
+}
+
+// formatTimes перечисляет фиксированное время подъемов или время первого подъема
+func formatTimes(settings scheduler.ScheduleSettings) string {
+	times := settings.TimesOfDay()
+	parts := make([]string, len(times))
+	for i, minute := range times {
+		parts[i] = fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatInterval(interval time.Duration) string {
+	if interval%time.Hour == 0 {
+		return fmt.Sprintf("каждые %d ч", int(interval.Hours()))
+	}
+	return fmt.Sprintf("каждые %d мин", int(interval.Minutes()))
+}
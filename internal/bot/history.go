@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// historyDetailLimit - сколько последних попыток показывать по одному резюме
+const historyDetailLimit = 15
+
+// historySummary - сводка попыток подъема по одному резюме
+type historySummary struct {
+	ResumeID  string
+	Title     string
+	Total     int
+	Succeeded int
+	Relogins  int
+	Last      scheduler.RaiseAttempt
+}
+
+func (h historySummary) successRate() int {
+	if h.Total == 0 {
+		return 0
+	}
+	return h.Succeeded * 100 / h.Total
+}
+
+func summarizeHistory(attempts []scheduler.RaiseAttempt) []historySummary {
+	byResume := make(map[string]*historySummary)
+	for _, attempt := range attempts {
+		summary, exists := byResume[attempt.ResumeID]
+		if !exists {
+			summary = &historySummary{ResumeID: attempt.ResumeID}
+			byResume[attempt.ResumeID] = summary
+		}
+		summary.Title = attempt.Title
+		summary.Total++
+		if attempt.Succeeded() {
+			summary.Succeeded++
+		}
+		if attempt.Relogin {
+			summary.Relogins++
+		}
+		summary.Last = attempt
+	}
+
+	result := make([]historySummary, 0, len(byResume))
+	for _, summary := range byResume {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Title < result[j].Title
+	})
+	return result
+}
+
+// handleHistory показывает сводку успешности подъемов по всем резюме
+func (b *Bot) handleHistory(chatID int64, editMessageID ...int) {
+	attempts, err := b.storage.LoadHistory("", 0)
+	if err != nil {
+		log.Printf("Failed to load raise history: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "❌ Не удалось загрузить историю подъемов")
+		b.send(msg)
+		return
+	}
+
+	summaries := summarizeHistory(attempts)
+	if len(summaries) == 0 {
+		text := "📊 <b>История подъемов пуста</b>\n\n"
+		text += "Здесь появятся результаты автоподъема после первого запуска по расписанию."
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "HTML"
+		b.send(msg)
+		return
+	}
+
+	total, succeeded := 0, 0
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+
+	text := "📊 <b>История подъемов</b>\n\n"
+	for _, summary := range summaries {
+		total += summary.Total
+		succeeded += summary.Succeeded
+
+		text += fmt.Sprintf("<code>%s</code>\n", summary.Title)
+		text += fmt.Sprintf("   %s Успешно: <b>%d/%d</b> (%d%%)\n",
+			successRateIcon(summary.successRate()), summary.Succeeded, summary.Total, summary.successRate())
+		if summary.Relogins > 0 {
+			text += fmt.Sprintf("   🔐 Переавторизаций: %d\n", summary.Relogins)
+		}
+		text += fmt.Sprintf("   🕐 Последняя: <i>%s</i> %s\n\n",
+			summary.Last.Time.Format("02.01 15:04"), attemptIcon(summary.Last))
+
+		button := tgbotapi.NewInlineKeyboardButtonData(
+			"📄 "+summary.Title,
+			fmt.Sprintf("history:%s", summary.ResumeID),
+		)
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+	}
+
+	text += fmt.Sprintf("📈 Всего: <b>%d/%d</b> (%d%%)\n", succeeded, total, succeeded*100/total)
+	text += "\n💡 <i>Выберите резюме для просмотра попыток</i>"
+
+	b.sendOrEdit(chatID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...), editMessageID...)
+}
+
+// handleHistoryCallback показывает последние попытки подъема одного резюме
+func (b *Bot) handleHistoryCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	resumeID := strings.TrimPrefix(callback.Data, "history:")
+
+	attempts, err := b.storage.LoadHistory(resumeID, historyDetailLimit)
+	if err != nil || len(attempts) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "История по этому резюме не найдена")
+		b.send(msg)
+		return
+	}
+
+	text := fmt.Sprintf("📊 <b>%s</b>\n\n", attempts[len(attempts)-1].Title)
+	text += fmt.Sprintf("Последние попытки (%d):\n\n", len(attempts))
+	for i := len(attempts) - 1; i >= 0; i-- {
+		text += formatAttempt(attempts[i]) + "\n"
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", "history"),
+		),
+	)
+	b.sendOrEdit(chatID, text, markup, callback.Message.MessageID)
+}
+
+func formatAttempt(attempt scheduler.RaiseAttempt) string {
+	line := fmt.Sprintf("%s <i>%s</i>", attemptIcon(attempt), attempt.Time.Format("02.01 15:04"))
+	if attempt.Status != 0 {
+		line += fmt.Sprintf(" · %d", attempt.Status)
+	}
+	line += fmt.Sprintf(" · %d мс", attempt.Latency.Round(time.Millisecond).Milliseconds())
+	if attempt.Attempt > 1 {
+		line += fmt.Sprintf(" · попытка %d", attempt.Attempt)
+	}
+	if attempt.Relogin {
+		line += " · 🔐"
+	}
+	if attempt.Error != "" {
+		line += fmt.Sprintf("\n   <code>%s</code>", escapeHTML(attempt.Error))
+	}
+	return line
+}
+
+func attemptIcon(attempt scheduler.RaiseAttempt) string {
+	switch {
+	case attempt.Succeeded() && attempt.Status == 409:
+		return "⏳"
+	case attempt.Succeeded():
+		return "✅"
+	default:
+		return "❌"
+	}
+}
+
+func successRateIcon(rate int) string {
+	switch {
+	case rate >= 90:
+		return "🟢"
+	case rate >= 50:
+		return "🟡"
+	default:
+		return "🔴"
+	}
+}
+
+// sendOrEdit редактирует сообщение, если передан его ID, иначе отправляет новое
+func (b *Bot) sendOrEdit(chatID int64, text string, markup tgbotapi.InlineKeyboardMarkup, editMessageID ...int) {
+	if len(editMessageID) > 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, editMessageID[0], text, markup)
+		edit.ParseMode = "HTML"
+		b.send(edit)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = markup
+	b.send(msg)
+}
+
+// showResult заменяет сообщение-заглушку ("Поднимаем...") результатом. Если заглушку
+// не удалось изменить, результат ставится в очередь новым сообщением, чтобы
+// пользователь получил его, когда Telegram станет доступен.
+func (b *Bot) showResult(chatID int64, placeholderID int, text string) {
+	edit := tgbotapi.NewEditMessageText(chatID, placeholderID, text)
+	edit.ParseMode = "HTML"
+	if _, err := b.api.Send(edit); err == nil {
+		return
+	}
+	b.enqueue(newHTMLMessage(chatID, text))
+}
+
+func escapeHTML(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,600 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/outbox"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+	"hh-ru-auto-resume-raising/internal/storage"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+// inviteTTL - срок действия кода приглашения
+const inviteTTL = 7 * 24 * time.Hour
+
+// TenantFactory создает клиенты hh.ru и планировщик для конфигурации пользователя
+// и восстанавливает их состояние из его хранилища. Планировщик запускает Hub.
+// release освобождает созданное, когда пользователя отключают.
+type TenantFactory func(cfg *config.Config, store *storage.Storage) (clients map[string]*hh.Client, sched *scheduler.Scheduler, release func(), err error)
+
+// Hub - многопользовательский режим. Каждый разрешенный пользователь Telegram получает
+// своего бота со своим аккаунтом hh.ru, планировщиком, уведомлениями и хранилищем
+// (config/users/<id>). Администратор работает с аккаунтами из переменных окружения
+// и общим хранилищем config/, как в однопользовательском режиме.
+type Hub struct {
+	api        *outbox.Outbox
+	config     *config.Config
+	storage    *storage.Storage
+	factory    TenantFactory
+	tenants    map[int64]*Bot
+	releases   map[int64]func()
+	onboarding *dialog.Manager
+	loop       *updateLoop
+	mutex      sync.RWMutex
+	// storeMutex защищает чтение-изменение-запись списков пользователей и приглашений:
+	// обновления разных чатов обрабатываются параллельно
+	storeMutex sync.Mutex
+}
+
+// Шаги подключения нового пользователя
+const (
+	onboardingInviteCode dialog.State = "invite_code"
+	onboardingLogin      dialog.State = "hh_login"
+	onboardingPassword   dialog.State = "hh_password"
+)
+
+func NewHub(cfg *config.Config, store *storage.Storage, factory TenantFactory) (*Hub, error) {
+	if cfg.UsersKey == "" {
+		return nil, fmt.Errorf("USERS_KEY is required in multi-user mode to encrypt hh.ru passwords")
+	}
+
+	api, err := newBotAPI(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot: %w", err)
+	}
+
+	// Пароль hh.ru ждем недолго: незавершенный ввод хранится на диске
+	onboarding := dialog.New(30*time.Minute, map[dialog.State]time.Duration{
+		onboardingPassword: 5 * time.Minute,
+	})
+
+	h := &Hub{
+		api:        outbox.New(api, store),
+		config:     cfg,
+		storage:    store,
+		factory:    factory,
+		tenants:    make(map[int64]*Bot),
+		releases:   make(map[int64]func()),
+		onboarding: onboarding,
+	}
+	h.loop = newUpdateLoop(api, cfg.Telegram.Webhook, newDispatcher(h.handleUpdate, h.reportPanic))
+
+	if saved, err := store.LoadOnboarding(); err != nil {
+		log.Printf("Failed to load onboarding: %v", err)
+	} else {
+		h.onboarding.Restore(saved)
+	}
+	h.onboarding.SetSaveHandler(func(snapshot map[int64]dialog.Dialog) {
+		if err := store.SaveOnboarding(snapshot); err != nil {
+			log.Printf("Failed to save onboarding: %v", err)
+		}
+	})
+
+	if err := h.addTenant(cfg, store); err != nil {
+		return nil, err
+	}
+
+	users, err := store.LoadUsers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		password, err := storage.OpenSecret(cfg.UsersKey, user.SealedPassword)
+		if err != nil {
+			log.Printf("Failed to decrypt hh.ru password of user %d: %v", user.ID, err)
+			continue
+		}
+		if err := h.addTenant(h.userConfig(user, password), storage.NewForUser(user.ID)); err != nil {
+			log.Printf("Failed to start user %d: %v", user.ID, err)
+		}
+	}
+	log.Printf("Loaded %d users", len(users))
+
+	return h, nil
+}
+
+func (h *Hub) Start() error {
+	log.Printf("Authorized on account %s (multi-user mode)", h.api.Username())
+
+	registerDefaultCommands(h.api)
+	h.onboarding.StartExpiry(time.Minute, func(userID int64, _ dialog.Dialog) {
+		h.send(userID, "⌛ Время ввода истекло. Чтобы подключиться заново, отправьте /start.")
+	})
+	h.mutex.RLock()
+	for _, tenant := range h.tenants {
+		tenant.registerCommands()
+	}
+	h.mutex.RUnlock()
+	h.registerAdminCommands()
+
+	return h.loop.run()
+}
+
+// Stop прекращает получение обновлений и останавливает ботов пользователей
+func (h *Hub) Stop() {
+	h.loop.stop()
+	h.onboarding.Stop()
+
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for _, tenant := range h.tenants {
+		tenant.stopBackground()
+	}
+}
+
+func (h *Hub) handleUpdate(update tgbotapi.Update) {
+	if update.Message != nil && update.Message.From != nil {
+		h.handleMessage(update.Message)
+	} else if update.CallbackQuery != nil {
+		h.handleCallbackQuery(update.CallbackQuery)
+	}
+}
+
+func (h *Hub) reportPanic(chatID int64, recovered interface{}, _ []byte) {
+	if chatID != 0 {
+		h.enqueue(tgbotapi.NewMessage(chatID, "❌ Что-то пошло не так, попробуйте еще раз."))
+	}
+	h.Alert(panicReport(chatID, recovered))
+}
+
+// Alert отправляет служебное сообщение администратору
+func (h *Hub) Alert(text string) {
+	h.send(h.config.AdminTG, text)
+}
+
+// userConfig возвращает конфигурацию пользователя: общие настройки и его аккаунт hh.ru
+// с расшифрованным паролем
+func (h *Hub) userConfig(user storage.User, password string) *config.Config {
+	cfg := *h.config
+	cfg.AdminTG = user.ID
+	cfg.HHLogin, cfg.HHPassword = user.Login, password
+	cfg.Accounts = []config.Account{{
+		Name:     config.DefaultAccount,
+		Login:    user.Login,
+		Password: password,
+		Proxy:    h.config.Proxy,
+	}}
+	cfg.AllowedUsers = nil
+	// Роли из ADMINS относятся к боту администратора
+	cfg.Admins = nil
+	// Почта и вебхуки администратора не должны получать события других пользователей
+	cfg.Notify = config.NotifyConfig{Telegram: h.config.Notify.Telegram}
+	return &cfg
+}
+
+// addTenant создает и запускает бота пользователя cfg.AdminTG; прежний бот
+// пользователя, если он был, останавливается
+func (h *Hub) addTenant(cfg *config.Config, store *storage.Storage) error {
+	clients, sched, release, err := h.factory(cfg, store)
+	if err != nil {
+		return err
+	}
+
+	tenant := newBot(h.api, cfg, clients, sched, store)
+	sched.SetNotificationHandler(notify.FromConfig(cfg.Notify, tenant, store).Publish)
+	sched.Start()
+	tenant.startBackground()
+
+	h.mutex.Lock()
+	h.removeTenantLocked(cfg.AdminTG)
+	h.tenants[cfg.AdminTG] = tenant
+	h.releases[cfg.AdminTG] = release
+	h.mutex.Unlock()
+	return nil
+}
+
+// removeTenantLocked останавливает бота пользователя и освобождает его среду,
+// чтобы при завершении не сохранялось состояние отключенного пользователя.
+// Вызывается под h.mutex.
+func (h *Hub) removeTenantLocked(userID int64) {
+	tenant, exists := h.tenants[userID]
+	if !exists {
+		return
+	}
+	tenant.scheduler.Stop()
+	tenant.stopBackground()
+	if release := h.releases[userID]; release != nil {
+		release()
+	}
+	delete(h.tenants, userID)
+	delete(h.releases, userID)
+}
+
+func (h *Hub) tenant(userID int64) (*Bot, bool) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	tenant, exists := h.tenants[userID]
+	return tenant, exists
+}
+
+// tenantFor возвращает бота, с которым работает пользователь: собственного
+// или бота администратора, если пользователю выдана роль в ADMINS
+func (h *Hub) tenantFor(userID int64) (*Bot, bool) {
+	if tenant, exists := h.tenant(userID); exists {
+		return tenant, true
+	}
+	if h.config.RoleOf(userID) != config.RoleNone {
+		return h.tenant(h.config.AdminTG)
+	}
+	return nil, false
+}
+
+func (h *Hub) handleMessage(message *tgbotapi.Message) {
+	userID := message.From.ID
+
+	if userID == h.config.AdminTG && h.handleAdminCommand(message) {
+		return
+	}
+
+	if tenant, exists := h.tenantFor(userID); exists {
+		tenant.handleMessage(message)
+		return
+	}
+
+	h.handleOnboarding(message)
+}
+
+func (h *Hub) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
+	if callback.From.ID == h.config.AdminTG && strings.HasPrefix(callback.Data, "user_remove:") {
+		h.handleRemoveUser(callback)
+		h.request(tgbotapi.NewCallback(callback.ID, ""))
+		return
+	}
+
+	if tenant, exists := h.tenantFor(callback.From.ID); exists {
+		tenant.handleCallbackQuery(callback)
+		return
+	}
+	h.request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleAdminCommand обрабатывает команды администратора по управлению пользователями
+func (h *Hub) handleAdminCommand(message *tgbotapi.Message) bool {
+	if !message.IsCommand() {
+		return false
+	}
+
+	switch message.Command() {
+	case "invite":
+		h.handleInvite(message.Chat.ID)
+	case "users":
+		h.handleUsers(message.Chat.ID)
+	default:
+		return false
+	}
+	return true
+}
+
+// registerAdminCommands дополняет меню команд администратора командами управления пользователями
+func (h *Hub) registerAdminCommands() {
+	admin, exists := h.tenant(h.config.AdminTG)
+	if !exists {
+		return
+	}
+	commands := append(botCommands(config.RoleOwner),
+		tgbotapi.BotCommand{Command: "invite", Description: "Создать код приглашения"},
+		tgbotapi.BotCommand{Command: "users", Description: "Подключенные пользователи"},
+	)
+	admin.setChatCommands(h.config.AdminTG, commands)
+}
+
+// handleOnboarding подключает нового пользователя: проверка доступа, логин и пароль hh.ru
+func (h *Hub) handleOnboarding(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	userID := message.From.ID
+	text := strings.TrimSpace(message.Text)
+
+	state, err := h.onboarding.Get(userID)
+	if err != nil {
+		if errors.Is(err, dialog.ErrExpired) {
+			h.send(chatID, "⌛ Время ввода истекло, начинаем подключение заново.")
+		}
+
+		// Код приглашения может прийти в ссылке: /start <код>
+		code := strings.TrimSpace(strings.TrimPrefix(text, "/start"))
+		switch {
+		case h.config.IsAllowed(userID):
+		case code != "" && h.useInvite(code):
+		default:
+			h.onboarding.Start(userID, onboardingInviteCode, nil)
+			h.send(chatID, "🔒 <b>Доступ по приглашению</b>\n\nВведите код приглашения, который выдал администратор.")
+			return
+		}
+		h.askLogin(userID, chatID)
+		return
+	}
+
+	switch strings.ToLower(text) {
+	case "/cancel", "отмена":
+		h.onboarding.Clear(userID)
+		h.send(chatID, "❌ Подключение отменено. Чтобы начать заново, отправьте /start.")
+		return
+	case "/back", "назад":
+		previous, ok := h.onboarding.Back(userID)
+		if !ok {
+			h.send(chatID, "❌ Подключение отменено. Чтобы начать заново, отправьте /start.")
+			return
+		}
+		h.sendOnboardingPrompt(chatID, previous.State)
+		return
+	}
+
+	switch state.State {
+	case onboardingInviteCode:
+		if !h.useInvite(text) {
+			h.send(chatID, "❌ Код недействителен или истек. Попросите у администратора новый.")
+			return
+		}
+		h.askLogin(userID, chatID)
+	case onboardingLogin:
+		h.onboarding.Push(userID, onboardingPassword, map[string]string{"login": text})
+		h.sendOnboardingPrompt(chatID, onboardingPassword)
+	case onboardingPassword:
+		h.request(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
+		h.registerUser(message.From, state.Data["login"], text, chatID)
+	}
+}
+
+func (h *Hub) askLogin(userID, chatID int64) {
+	h.onboarding.Start(userID, onboardingLogin, nil)
+	h.sendOnboardingPrompt(chatID, onboardingLogin)
+}
+
+// sendOnboardingPrompt повторяет вопрос шага подключения (в том числе после "назад")
+func (h *Hub) sendOnboardingPrompt(chatID int64, state dialog.State) {
+	switch state {
+	case onboardingLogin:
+		text := "👋 <b>Подключение аккаунта HeadHunter</b>\n\n"
+		text += "Бот будет поднимать ваши резюме по расписанию. Ваши резюме, расписание и уведомления "
+		text += "видны только вам.\n\n📋 Введите логин от HeadHunter (email или телефон)."
+		h.send(chatID, text)
+	case onboardingPassword:
+		text := "🔒 Введите пароль от HeadHunter.\n\n<i>Сообщение с паролем будет удалено из чата. "
+		text += "«назад» - изменить логин, «отмена» - прервать подключение</i>"
+		h.send(chatID, text)
+	}
+}
+
+// registerUser сохраняет пользователя и запускает его бота
+func (h *Hub) registerUser(from *tgbotapi.User, login, password string, chatID int64) {
+	h.onboarding.Clear(from.ID)
+
+	sealed, err := storage.SealSecret(h.config.UsersKey, password)
+	if err != nil {
+		log.Printf("Failed to encrypt hh.ru password: %v", err)
+		h.send(chatID, "❌ Не удалось сохранить пользователя, попробуйте позже.")
+		return
+	}
+	user := storage.User{
+		ID:             from.ID,
+		Name:           strings.TrimSpace(from.FirstName + " " + from.LastName),
+		Login:          login,
+		SealedPassword: sealed,
+		JoinedAt:       time.Now(),
+	}
+	if from.UserName != "" {
+		user.Name = "@" + from.UserName
+	}
+
+	if err := h.saveUser(user); err != nil {
+		log.Printf("Failed to save user: %v", err)
+		h.send(chatID, "❌ Не удалось сохранить пользователя, попробуйте позже.")
+		return
+	}
+
+	if err := h.addTenant(h.userConfig(user, password), storage.NewForUser(user.ID)); err != nil {
+		log.Printf("Failed to start user %d: %v", user.ID, err)
+		h.send(chatID, "❌ Не удалось запустить бота для вашего аккаунта, попробуйте позже.")
+		return
+	}
+	log.Printf("User %d (%s) joined", user.ID, user.Name)
+
+	if tenant, exists := h.tenant(user.ID); exists {
+		tenant.registerCommands()
+	}
+
+	h.send(chatID, "✅ <b>Аккаунт подключен!</b>\n\nНажмите \"🔐 Войти в HeadHunter\" или отправьте /login, чтобы авторизоваться.")
+	if tenant, exists := h.tenant(user.ID); exists {
+		tenant.sendMainMenu(chatID)
+	}
+	h.send(h.config.AdminTG, fmt.Sprintf("👤 Новый пользователь: <b>%s</b> (<code>%d</code>)", escapeHTML(user.Name), user.ID))
+}
+
+// handleInvite создает одноразовый код приглашения
+func (h *Hub) handleInvite(chatID int64) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		log.Printf("Failed to generate invite code: %v", err)
+		return
+	}
+	code := hex.EncodeToString(buf)
+
+	if err := h.saveInvite(storage.Invite{Code: code, CreatedAt: time.Now()}); err != nil {
+		log.Printf("Failed to save invite: %v", err)
+		return
+	}
+
+	text := "🎟 <b>Код приглашения</b>\n\n"
+	text += fmt.Sprintf("Код: <code>%s</code>\n", code)
+	text += fmt.Sprintf("Ссылка: https://t.me/%s?start=%s\n\n", h.api.Username(), code)
+	text += fmt.Sprintf("💡 <i>Код одноразовый и действует %d дней</i>", int(inviteTTL.Hours()/24))
+	h.send(chatID, text)
+}
+
+func (h *Hub) saveInvite(invite storage.Invite) error {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	invites, err := h.storage.LoadInvites()
+	if err != nil {
+		return err
+	}
+	return h.storage.SaveInvites(append(invites, invite))
+}
+
+// useInvite проверяет код приглашения и удаляет его; заодно удаляются истекшие коды
+func (h *Hub) useInvite(code string) bool {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	invites, err := h.storage.LoadInvites()
+	if err != nil {
+		log.Printf("Failed to load invites: %v", err)
+		return false
+	}
+
+	found := false
+	var pending []storage.Invite
+	for _, invite := range invites {
+		if time.Since(invite.CreatedAt) > inviteTTL {
+			continue
+		}
+		if invite.Code == code && !found {
+			found = true
+			continue
+		}
+		pending = append(pending, invite)
+	}
+
+	if len(pending) != len(invites) {
+		if err := h.storage.SaveInvites(pending); err != nil {
+			log.Printf("Failed to save invites: %v", err)
+		}
+	}
+	return found
+}
+
+// handleUsers показывает подключенных пользователей
+func (h *Hub) handleUsers(chatID int64, editMessageID ...int) {
+	users, err := h.storage.LoadUsers()
+	if err != nil {
+		log.Printf("Failed to load users: %v", err)
+		return
+	}
+
+	text := fmt.Sprintf("👥 <b>Пользователи (%d)</b>\n\n", len(users))
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, user := range users {
+		schedules := 0
+		if tenant, exists := h.tenant(user.ID); exists {
+			schedules = len(tenant.scheduler.GetAll())
+		}
+		text += fmt.Sprintf("• <b>%s</b> (<code>%d</code>)\n", escapeHTML(user.Name), user.ID)
+		text += fmt.Sprintf("   👨‍💼 %s, 📅 автоподъемов: %d, с %s\n", escapeHTML(user.Login), schedules, user.JoinedAt.Format("02.01.2006"))
+
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Отключить "+user.Name, "user_remove:"+strconv.FormatInt(user.ID, 10)),
+		))
+	}
+	if len(users) == 0 {
+		text += "Пока никто не подключился.\n"
+	}
+	text += "\n💡 <i>/invite - создать код приглашения</i>"
+
+	if admin, exists := h.tenant(h.config.AdminTG); exists {
+		admin.sendOrEdit(chatID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...), editMessageID...)
+	}
+}
+
+// handleRemoveUser отключает пользователя: останавливает его расписание и удаляет из списка.
+// Данные пользователя в config/users/<id> сохраняются.
+func (h *Hub) handleRemoveUser(callback *tgbotapi.CallbackQuery) {
+	userID, err := strconv.ParseInt(strings.TrimPrefix(callback.Data, "user_remove:"), 10, 64)
+	if err != nil || userID == h.config.AdminTG {
+		return
+	}
+
+	if err := h.deleteUser(userID); err != nil {
+		log.Printf("Failed to remove user: %v", err)
+		return
+	}
+
+	h.mutex.Lock()
+	h.removeTenantLocked(userID)
+	h.mutex.Unlock()
+	log.Printf("User %d removed", userID)
+
+	scope := tgbotapi.NewBotCommandScopeChat(userID)
+	if _, err := h.api.Request(tgbotapi.NewDeleteMyCommandsWithScope(scope)); err != nil {
+		log.Printf("Failed to reset commands for %d: %v", userID, err)
+	}
+
+	h.handleUsers(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+// saveUser добавляет пользователя в список подключенных или заменяет запись
+// с тем же ID (повторное подключение)
+func (h *Hub) saveUser(user storage.User) error {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	users, err := h.storage.LoadUsers()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		if users[i].ID == user.ID {
+			users[i] = user
+			return h.storage.SaveUsers(users)
+		}
+	}
+	return h.storage.SaveUsers(append(users, user))
+}
+
+// deleteUser удаляет пользователя из списка подключенных
+func (h *Hub) deleteUser(userID int64) error {
+	h.storeMutex.Lock()
+	defer h.storeMutex.Unlock()
+
+	users, err := h.storage.LoadUsers()
+	if err != nil {
+		return err
+	}
+	var remaining []storage.User
+	for _, user := range users {
+		if user.ID != userID {
+			remaining = append(remaining, user)
+		}
+	}
+	return h.storage.SaveUsers(remaining)
+}
+
+// send ставит сообщение хаба (подключение пользователя, служебные уведомления) в очередь,
+// чтобы оно дошло и при временной недоступности Telegram
+func (h *Hub) send(chatID int64, text string) {
+	h.enqueue(newHTMLMessage(chatID, text))
+}
+
+func (h *Hub) enqueue(message tgbotapi.MessageConfig) {
+	if err := h.api.Enqueue(message); err != nil {
+		log.Printf("Failed to queue message for chat %d: %v", message.ChatID, err)
+	}
+}
+
+func (h *Hub) request(c tgbotapi.Chattable) {
+	if _, err := h.api.Request(c); err != nil {
+		log.Printf("Telegram request failed: %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+const (
+	// notifyBatchGrace - сколько ждать после конца минуты, пока завершатся запущенные в ней подъемы
+	notifyBatchGrace = 5 * time.Second
+	// notifyMessageLimit - длина сообщения с запасом до лимита Telegram (4096 символов)
+	notifyMessageLimit = 4000
+)
+
+// Notify - канал Telegram для рассылки уведомлений. События одной минуты
+// (например, подъемы нескольких резюме по одному расписанию) приходят одним сообщением.
+// Настройки уведомлений пользователя действуют только здесь: живой статус учитывает
+// все события, а в сообщения попадают только пропущенные настройками.
+func (b *Bot) Notify(event notify.Event) error {
+	if b.dashboardEnabled() {
+		b.noteDashboardEvent(event)
+	}
+	if !b.scheduler.GetNotifySettings().Delivers(event) {
+		return nil
+	}
+
+	b.notifyMutex.Lock()
+	defer b.notifyMutex.Unlock()
+
+	b.notifyBatch = append(b.notifyBatch, event)
+	if b.notifyTimer == nil {
+		b.notifyTimer = time.AfterFunc(notifyBatchDelay(event.Time), b.flushNotifications)
+	}
+	return nil
+}
+
+// notifyBatchDelay возвращает время до конца минуты события с запасом
+func notifyBatchDelay(eventTime time.Time) time.Duration {
+	delay := time.Until(eventTime.Truncate(time.Minute).Add(time.Minute)) + notifyBatchGrace
+	if delay < notifyBatchGrace {
+		return notifyBatchGrace
+	}
+	return delay
+}
+
+// flushNotifications отправляет накопленные события. Проблемы с подъемом приходят
+// отдельным сообщением по каждому резюме с кнопками, остальное - одним сообщением.
+// С живым статусом отдельно приходят только ошибки. В тихие часы сообщения приходят
+// без звука, а в режиме сводки все, кроме ошибок, откладывается до конца тихих часов.
+func (b *Bot) flushNotifications() {
+	settings := b.scheduler.GetNotifySettings()
+	quiet := settings.Quiet.Contains(time.Now())
+	dashboard := b.dashboardEnabled()
+
+	b.notifyMutex.Lock()
+	events := b.notifyBatch
+	b.notifyBatch, b.notifyTimer = nil, nil
+	if dashboard {
+		events = urgentEvents(events)
+	}
+	if quiet && settings.Quiet.Mode == notify.QuietDigest {
+		urgent := urgentEvents(events)
+		for _, event := range events {
+			if event.Severity < notify.SeverityError {
+				b.notifyDeferred = append(b.notifyDeferred, event)
+			}
+		}
+		if len(urgent) < len(events) {
+			b.saveDeferred()
+		}
+		events = urgent
+	}
+	b.notifyMutex.Unlock()
+
+	alerts, events := splitAlerts(events)
+	for _, resumeEvents := range alerts {
+		b.sendNotifications(notificationMessages("🔔 Уведомления", resumeEvents), quiet, alertKeyboard(resumeEvents[0].ResumeID)...)
+	}
+	if len(events) > 0 {
+		b.sendNotifications(notificationMessages("🔔 Уведомления", events), quiet)
+	}
+}
+
+// urgentEvents отбирает ошибки: они приходят отдельным сообщением и в тихие часы, и с живым статусом
+func urgentEvents(events []notify.Event) []notify.Event {
+	var urgent []notify.Event
+	for _, event := range events {
+		if event.Severity >= notify.SeverityError {
+			urgent = append(urgent, event)
+		}
+	}
+	return urgent
+}
+
+// sendDeferred присылает сводку отложенных событий, когда тихие часы закончились
+// или режим сводки выключен
+func (b *Bot) sendDeferred() {
+	settings := b.scheduler.GetNotifySettings()
+	if settings.Quiet.Mode == notify.QuietDigest && settings.Quiet.Contains(time.Now()) {
+		return
+	}
+
+	b.notifyMutex.Lock()
+	events := b.notifyDeferred
+	b.notifyDeferred = nil
+	if len(events) > 0 {
+		b.saveDeferred()
+	}
+	b.notifyMutex.Unlock()
+
+	if len(events) > 0 {
+		b.sendNotifications(notificationMessages("🌅 Сводка за тихие часы", events), false)
+	}
+}
+
+// saveDeferred сохраняет отложенные события. Вызывается под notifyMutex.
+func (b *Bot) saveDeferred() {
+	if err := b.storage.SaveDeferredNotifications(b.notifyDeferred); err != nil {
+		log.Printf("Failed to save deferred notifications: %v", err)
+	}
+}
+
+// startNotifications раз в минуту присылает отложенное после тихих часов (в том числе
+// накопленное до перезапуска) и сводки, время которых наступило, и обновляет живой статус
+func (b *Bot) startNotifications() {
+	b.notifyMutex.Lock()
+	defer b.notifyMutex.Unlock()
+	if b.notifyStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	b.notifyStop = stop
+
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		b.sendDeferred()
+		b.sendDueDigests()
+		b.refreshDashboard()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.sendDeferred()
+				b.sendDueDigests()
+				b.refreshDashboard()
+			}
+		}
+	}()
+}
+
+// stopNotifications останавливает фоновую рассылку и сразу отправляет накопленный пакет событий
+func (b *Bot) stopNotifications() {
+	b.notifyMutex.Lock()
+	if b.notifyStop != nil {
+		close(b.notifyStop)
+		b.notifyStop = nil
+	}
+	pending := b.notifyTimer != nil && b.notifyTimer.Stop()
+	b.notifyMutex.Unlock()
+
+	if pending {
+		b.flushNotifications()
+	}
+}
+
+// notificationMessages собирает события в сообщения: одно событие - как есть,
+// несколько - под общим заголовком, с разбиением по лимиту длины Telegram
+func notificationMessages(title string, events []notify.Event) []string {
+	if len(events) == 1 {
+		return []string{notify.Render(events[0]).HTML(events[0])}
+	}
+
+	var messages []string
+	text := fmt.Sprintf("<b>%s: %d</b>", title, len(events))
+	for _, event := range events {
+		part := notify.Render(event).HTML(event)
+		if utf8.RuneCountInString(text)+utf8.RuneCountInString(part)+2 > notifyMessageLimit {
+			messages = append(messages, text)
+			text = part
+			continue
+		}
+		text += "\n\n" + part
+	}
+	return append(messages, text)
+}
+
+// sendNotifications ставит сообщения в очередь отправки всем пользователям с доступом к боту:
+// они дойдут и после недоступности Telegram или перезапуска. silent - без звука (тихие часы).
+// Кнопки rows прикладываются к последнему сообщению с учетом роли пользователя.
+func (b *Bot) sendNotifications(messages []string, silent bool, rows ...[]tgbotapi.InlineKeyboardButton) {
+	for _, chatID := range b.config.AdminIDs() {
+		markup := b.inlineKeyboardFor(chatID, rows...)
+		for i, text := range messages {
+			msg := tgbotapi.NewMessage(chatID, text)
+			msg.ParseMode = "HTML"
+			msg.DisableNotification = silent
+			if i == len(messages)-1 && len(markup.InlineKeyboard) > 0 {
+				msg.ReplyMarkup = markup
+			}
+			if err := b.api.Enqueue(msg); err != nil {
+				log.Printf("Failed to queue notification for chat %d: %v", chatID, err)
+			}
+		}
+	}
+}
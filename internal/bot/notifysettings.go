@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+// quietPresets - тихие часы, которые можно выбрать кнопкой; остальные задаются командой /quiet
+var quietPresets = []string{"23:00-08:00", "22:00-09:00"}
+
+// notificationsStatus описывает общий уровень уведомлений для экранов статуса
+func (b *Bot) notificationsStatus() string {
+	return notify.LevelName(b.scheduler.GetNotifySettings().GlobalLevel())
+}
+
+// handleNotificationSettings показывает настройки уведомлений: общий уровень и тихие часы
+func (b *Bot) handleNotificationSettings(chatID int64, editMessageID ...int) {
+	settings := b.scheduler.GetNotifySettings()
+
+	text := "🔔 <b>Уведомления</b>\n\n"
+	text += fmt.Sprintf("📶 Общий уровень: <b>%s</b>\n", notify.LevelName(settings.GlobalLevel()))
+	if settings.Quiet.Enabled() {
+		text += fmt.Sprintf("🌙 Тихие часы: <b>%s, %s</b>\n", settings.Quiet, quietModeName(settings.Quiet.Mode))
+	} else {
+		text += "🌙 Тихие часы: <b>выключены</b>\n"
+	}
+	if b.dashboardEnabled() {
+		text += "📌 Живой статус: <b>включен</b>, отдельно приходят только ошибки\n"
+	}
+	if len(settings.Events) > 0 || len(settings.Resumes) > 0 {
+		text += fmt.Sprintf("⚙️ Отдельные настройки: типов событий - %d, резюме - %d\n", len(settings.Events), len(settings.Resumes))
+	}
+	text += "\n💡 <i>«Только ошибки» - неудачные подъемы и проблемы со входом. "
+	text += "События одной минуты приходят одним сообщением. "
+	text += "Другие тихие часы: /quiet 23:30-07:00. "
+	text += "Живой статус - одно закрепленное сообщение, которое обновляется после каждого события (/dashboard)</i>"
+
+	var levelRow []tgbotapi.InlineKeyboardButton
+	for _, level := range notify.Levels {
+		levelRow = append(levelRow, tgbotapi.NewInlineKeyboardButtonData(
+			checkedLabel(level == settings.GlobalLevel(), notify.LevelName(level)), "notify_level:"+string(level)))
+	}
+
+	var quietRow []tgbotapi.InlineKeyboardButton
+	for _, preset := range quietPresets {
+		label := checkedLabel(settings.Quiet.Enabled() && settings.Quiet.String() == preset, "🌙 "+preset)
+		quietRow = append(quietRow, tgbotapi.NewInlineKeyboardButtonData(label, "notify_quiet:"+preset))
+	}
+	quietRow = append(quietRow, tgbotapi.NewInlineKeyboardButtonData(checkedLabel(!settings.Quiet.Enabled(), "Без тихих часов"), "notify_quiet:off"))
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		levelRow,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📂 По типам событий", "notify_events"),
+			tgbotapi.NewInlineKeyboardButtonData("📄 По резюме", "notify_resumes"),
+		),
+		quietRow,
+	}
+	if settings.Quiet.Enabled() {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(checkedLabel(settings.Quiet.Mode != notify.QuietDigest, "🔕 Без звука"), "notify_quiet_mode:"+string(notify.QuietSilent)),
+			tgbotapi.NewInlineKeyboardButtonData(checkedLabel(settings.Quiet.Mode == notify.QuietDigest, "🌅 Сводкой утром"), "notify_quiet_mode:"+string(notify.QuietDigest)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(checkedLabel(b.dashboardEnabled(), "📌 Живой статус вместо сообщений"), "notify_dashboard"),
+	))
+	b.sendOrEdit(chatID, text, b.inlineKeyboardFor(chatID, rows...), editMessageID...)
+}
+
+// handleNotificationEvents показывает уровни по типам событий; кнопка переключает уровень по кругу
+func (b *Bot) handleNotificationEvents(chatID int64, editMessageID ...int) {
+	settings := b.scheduler.GetNotifySettings()
+
+	text := "📂 <b>Уведомления по типам событий</b>\n\n"
+	text += "Нажмите на событие, чтобы сменить уровень: все, только ошибки, выключены или по умолчанию (общий уровень).\n\n"
+	text += "💡 <i>Настройка резюме важнее настройки типа события</i>"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, eventType := range notify.EventTypes {
+		label := fmt.Sprintf("%s: %s", notify.EventTypeName(eventType), notify.LevelName(settings.Events[eventType]))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "notify_event:"+string(eventType)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", "notify_settings"),
+	))
+	b.sendOrEdit(chatID, text, b.inlineKeyboardFor(chatID, rows...), editMessageID...)
+}
+
+// handleNotificationResumes показывает уровни по резюме из расписания
+func (b *Bot) handleNotificationResumes(chatID int64, editMessageID ...int) {
+	settings := b.scheduler.GetNotifySettings()
+	schedules := b.scheduler.GetAll()
+
+	titles := make([]string, 0, len(schedules))
+	for title := range schedules {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+
+	text := "📄 <b>Уведомления по резюме</b>\n\n"
+	if len(titles) == 0 {
+		text += "В расписании пока нет резюме."
+	} else {
+		text += "Нажмите на резюме, чтобы сменить уровень: все, только ошибки, выключены или по умолчанию."
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, title := range titles {
+		label := fmt.Sprintf("%s: %s", title, notify.LevelName(settings.Resumes[title]))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "notify_resume:"+schedules[title].ResumeID),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", "notify_settings"),
+	))
+	b.sendOrEdit(chatID, text, b.inlineKeyboardFor(chatID, rows...), editMessageID...)
+}
+
+// handleNotifyCallback обрабатывает кнопки экранов настройки уведомлений
+func (b *Bot) handleNotifyCallback(callback *tgbotapi.CallbackQuery) {
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	action, value, _ := strings.Cut(callback.Data, ":")
+
+	switch action {
+	case "notify_settings":
+		b.handleNotificationSettings(chatID, messageID)
+	case "notify_events":
+		b.handleNotificationEvents(chatID, messageID)
+	case "notify_resumes":
+		b.handleNotificationResumes(chatID, messageID)
+	case "notify_level":
+		b.updateNotifySettings(func(settings *notify.Settings) {
+			settings.Level = notify.Level(value)
+		})
+		b.handleNotificationSettings(chatID, messageID)
+	case "notify_event":
+		eventType := notify.EventType(value)
+		b.updateNotifySettings(func(settings *notify.Settings) {
+			settings.SetEventLevel(eventType, nextLevel(settings.Events[eventType]))
+		})
+		b.handleNotificationEvents(chatID, messageID)
+	case "notify_resume":
+		for title, schedule := range b.scheduler.GetAll() {
+			if schedule.ResumeID == value {
+				b.updateNotifySettings(func(settings *notify.Settings) {
+					settings.SetResumeLevel(title, nextLevel(settings.Resumes[title]))
+				})
+			}
+		}
+		b.handleNotificationResumes(chatID, messageID)
+	case "notify_quiet":
+		quiet := notify.QuietHours{}
+		if value != "off" {
+			quiet, _ = notify.ParseQuietHours(value)
+		}
+		b.setQuietHours(quiet)
+		b.handleNotificationSettings(chatID, messageID)
+	case "notify_dashboard":
+		b.setDashboard(!b.dashboardEnabled())
+		b.handleNotificationSettings(chatID, messageID)
+	case "notify_quiet_mode":
+		b.updateNotifySettings(func(settings *notify.Settings) {
+			settings.Quiet.Mode = notify.QuietMode(value)
+		})
+		b.handleNotificationSettings(chatID, messageID)
+	}
+}
+
+// handleQuietCommand задает тихие часы: /quiet 23:00-08:00 или /quiet off
+func (b *Bot) handleQuietCommand(chatID int64, args string) {
+	var quiet notify.QuietHours
+	switch strings.ToLower(args) {
+	case "":
+		b.handleNotificationSettings(chatID)
+		return
+	case "off", "выкл", "0":
+	default:
+		parsed, err := notify.ParseQuietHours(args)
+		if err != nil {
+			b.send(newHTMLMessage(chatID, fmt.Sprintf("❌ %s\n\nПример: <code>/quiet 23:00-08:00</code> или <code>/quiet off</code>", escapeHTML(err.Error()))))
+			return
+		}
+		quiet = parsed
+	}
+
+	b.setQuietHours(quiet)
+	b.handleNotificationSettings(chatID)
+}
+
+// setQuietHours меняет промежуток тихих часов, сохраняя выбранный режим
+func (b *Bot) setQuietHours(quiet notify.QuietHours) {
+	b.updateNotifySettings(func(settings *notify.Settings) {
+		if settings.Quiet.Mode != "" {
+			quiet.Mode = settings.Quiet.Mode
+		}
+		settings.Quiet = quiet
+	})
+}
+
+// updateNotifySettings изменяет настройки уведомлений и сохраняет их
+func (b *Bot) updateNotifySettings(change func(settings *notify.Settings)) {
+	b.scheduler.UpdateNotifySettings(change)
+	b.saveState()
+}
+
+// nextLevel возвращает следующий уровень отдельной настройки: по умолчанию, все, только ошибки, выключены
+func nextLevel(level notify.Level) notify.Level {
+	if level == "" {
+		return notify.Levels[0]
+	}
+	for i, candidate := range notify.Levels {
+		if candidate == level && i+1 < len(notify.Levels) {
+			return notify.Levels[i+1]
+		}
+	}
+	return ""
+}
+
+func quietModeName(mode notify.QuietMode) string {
+	if mode == notify.QuietDigest {
+		return "сводкой утром"
+	}
+	return "без звука"
+}
+
+// checkedLabel отмечает выбранный вариант галочкой
+func checkedLabel(checked bool, label string) string {
+	if checked {
+		return "✅ " + label
+	}
+	return label
+}
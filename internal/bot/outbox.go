@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/outbox"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+const (
+	// outboxListLimit - сколько сообщений очереди и последних неотправленных показывать
+	outboxListLimit = 15
+	// outboxPreviewLength - сколько символов текста сообщения показывать
+	outboxPreviewLength = 60
+)
+
+// handleOutbox показывает очередь отправки сообщений Telegram и сообщения, которые
+// не удалось отправить. Видны только сообщения в чаты пользователей этого бота.
+func (b *Bot) handleOutbox(chatID int64) {
+	failures, err := b.api.Failures()
+	if err != nil {
+		log.Printf("Failed to load failed Telegram messages: %v", err)
+		msg := tgbotapi.NewMessage(chatID, "❌ Не удалось загрузить неотправленные сообщения")
+		b.send(msg)
+		return
+	}
+	queue := b.ownMessages(b.api.Pending())
+	failures = b.ownMessages(failures)
+
+	text := "📮 <b>Отправка сообщений</b>\n\n"
+	text += "Уведомления проходят через очередь: при недоступности Telegram или ограничении частоты (429) они отправляются позже.\n"
+	if len(queue) == 0 && len(failures) == 0 {
+		text += "\n✅ Очередь пуста, неотправленных сообщений нет."
+		b.send(newHTMLMessage(chatID, text))
+		return
+	}
+
+	if len(queue) > 0 {
+		text += fmt.Sprintf("\n<b>В очереди: %d</b>\n", len(queue))
+		if len(queue) > outboxListLimit {
+			queue = queue[:outboxListLimit]
+		}
+		for _, message := range queue {
+			text += outboxMessageLine(message)
+		}
+	}
+
+	if len(failures) > outboxListLimit {
+		failures = failures[len(failures)-outboxListLimit:]
+	}
+	if len(failures) > 0 {
+		text += "\n<b>Не отправлены</b>\n"
+		for i := len(failures) - 1; i >= 0; i-- {
+			text += outboxMessageLine(failures[i])
+		}
+	}
+	b.send(newHTMLMessage(chatID, text))
+}
+
+// ownMessages оставляет сообщения в чаты пользователей бота: в многопользовательском
+// режиме очередь общая
+func (b *Bot) ownMessages(messages []outbox.Message) []outbox.Message {
+	var own []outbox.Message
+	for _, message := range messages {
+		if b.config.RoleOf(message.ChatID) != config.RoleNone {
+			own = append(own, message)
+		}
+	}
+	return own
+}
+
+// outboxMessageLine описывает сообщение одной строкой: начало текста, попытки и ошибка
+func outboxMessageLine(message outbox.Message) string {
+	icon := "⏳"
+	if message.State == outbox.StateFailed {
+		icon = "❌"
+	}
+	line := fmt.Sprintf("%s %s <i>%s</i>, попыток: %d",
+		icon, message.UpdatedAt.Format("02.01 15:04"), escapeHTML(messagePreview(message.Text)), message.Attempts)
+	if message.LastError != "" {
+		line += " - " + escapeHTML(message.LastError)
+	}
+	if message.State == outbox.StatePending && message.Attempts > 0 {
+		line += ", повтор в " + message.NextAttempt.Format("15:04")
+	}
+	return line + "\n"
+}
+
+// messagePreview возвращает начало текста сообщения без разметки в одну строку
+func messagePreview(text string) string {
+	text = strings.Join(strings.Fields(html.UnescapeString(stripTags(text))), " ")
+	if utf8.RuneCountInString(text) <= outboxPreviewLength {
+		return text
+	}
+	return string([]rune(text)[:outboxPreviewLength]) + "…"
+}
+
+// stripTags убирает HTML-теги разметки Telegram
+func stripTags(text string) string {
+	var result strings.Builder
+	inTag := false
+	for _, r := range text {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>' && inTag:
+			inTag = false
+		case !inTag:
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
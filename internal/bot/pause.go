@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// handlePauseCallback ставит автоподъем резюме на паузу до ручного возобновления
+func (b *Bot) handlePauseCallback(callback *tgbotapi.CallbackQuery) {
+	title, _, exists := b.scheduleByResumeID(strings.TrimPrefix(callback.Data, "pause:"))
+	if exists && b.scheduler.PauseResume(title, time.Time{}) {
+		b.saveState()
+	}
+	b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+// handleUnpauseCallback возобновляет автоподъем резюме
+func (b *Bot) handleUnpauseCallback(callback *tgbotapi.CallbackQuery) {
+	title, _, exists := b.scheduleByResumeID(strings.TrimPrefix(callback.Data, "unpause:"))
+	if exists && b.scheduler.UnpauseResume(title) {
+		b.saveState()
+	}
+	b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+// handleVacation запрашивает срок режима отпуска
+func (b *Bot) handleVacation(callback *tgbotapi.CallbackQuery) {
+	b.askVacation(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+func (b *Bot) askVacation(chatID int64, editMessageID ...int) {
+	b.dialogs.Start(chatID, stateVacationUntil, nil)
+
+	text := "🏖 <b>Режим отпуска</b>\n\n"
+	text += "Автоподъем всех резюме будет приостановлен и возобновится автоматически.\n\n"
+	text += "📋 <b>Введите дату окончания</b> (формат ДД.ММ или ДД.ММ.ГГГГ)\n"
+	text += "или количество дней, например <code>7</code>.\n"
+	text += "<code>0</code> - до ручного возобновления"
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("1 день", "pause_all:1"),
+			tgbotapi.NewInlineKeyboardButtonData("7 дней", "pause_all:7"),
+			tgbotapi.NewInlineKeyboardButtonData("14 дней", "pause_all:14"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("♾ Бессрочно", "pause_all:0"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel_vacation"),
+		),
+	)
+	b.sendOrEdit(chatID, text, markup, editMessageID...)
+}
+
+// handlePauseAllCallback включает режим отпуска на выбранное число дней
+func (b *Bot) handlePauseAllCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	b.dialogs.Clear(chatID)
+
+	until, err := parseVacationUntil(strings.TrimPrefix(callback.Data, "pause_all:"), time.Now())
+	if err != nil {
+		return
+	}
+	b.pauseAll(chatID, until)
+	b.handleShowSchedule(chatID, callback.Message.MessageID)
+}
+
+// handleVacationUntil обрабатывает введенную дату окончания режима отпуска
+func (b *Bot) handleVacationUntil(message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	until, err := parseVacationUntil(message.Text, time.Now())
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при вводе даты, используйте формат 25.12, 25.12.2025 или число дней.")
+		b.send(msg)
+		return
+	}
+
+	b.dialogs.Clear(chatID)
+	b.pauseAll(chatID, until)
+	b.handleShowSchedule(chatID)
+}
+
+// handleCancelVacation отменяет ввод срока режима отпуска
+func (b *Bot) handleCancelVacation(callback *tgbotapi.CallbackQuery) {
+	b.dialogs.Clear(callback.Message.Chat.ID)
+	b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+// handleUnpauseAll выключает режим отпуска
+func (b *Bot) handleUnpauseAll(callback *tgbotapi.CallbackQuery) {
+	b.unpauseAll()
+	b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+func (b *Bot) unpauseAll() {
+	b.scheduler.UnpauseAll()
+	b.saveState()
+}
+
+func (b *Bot) pauseAll(chatID int64, until time.Time) {
+	b.scheduler.PauseAll(until)
+	b.saveState()
+
+	text := "🏖 <b>Режим отпуска включен</b>\n\n"
+	text += fmt.Sprintf("Автоподъем приостановлен %s", pauseStatusText(until))
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	b.send(msg)
+}
+
+// saveState сохраняет расписание, разовые подъемы, паузу и настройки уведомлений.
+// Бот пишет тем же путем, что и планировщик, чтобы записи не обгоняли друг друга.
+func (b *Bot) saveState() {
+	b.scheduler.Save()
+}
+
+// parseVacationUntil разбирает срок паузы: число дней, ДД.ММ или ДД.ММ.ГГГГ.
+// Ноль дней означает паузу без срока (нулевое время).
+func parseVacationUntil(text string, now time.Time) (time.Time, error) {
+	text = strings.TrimSpace(text)
+
+	if days, err := strconv.Atoi(text); err == nil {
+		if days < 0 {
+			return time.Time{}, fmt.Errorf("negative number of days: %d", days)
+		}
+		if days == 0 {
+			return time.Time{}, nil
+		}
+		return now.AddDate(0, 0, days), nil
+	}
+
+	if date, err := time.ParseInLocation("02.01.2006", text, now.Location()); err == nil {
+		if !date.After(now) {
+			return time.Time{}, fmt.Errorf("date %s is in the past", text)
+		}
+		return date, nil
+	}
+
+	date, err := time.ParseInLocation("02.01", text, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", text)
+	}
+	date = time.Date(now.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+	if !date.After(now) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, nil
+}
+
+func pauseStatusText(until time.Time) string {
+	if until.IsZero() {
+		return "до ручного возобновления"
+	}
+	return "до " + until.Format("02.01 15:04")
+}
+
+// scheduleButtonRow возвращает кнопки паузы и редактирования расписания резюме.
+// В данных кнопок ID резюме: название не помещается в 64 байта callback_data.
+func scheduleButtonRow(title string, schedule scheduler.ResumeSchedule) []tgbotapi.InlineKeyboardButton {
+	editButton := tgbotapi.NewInlineKeyboardButtonData("✏️", "edit:"+schedule.ResumeID)
+	if schedule.Paused {
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ "+title, "unpause:"+schedule.ResumeID),
+			editButton,
+		)
+	}
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⏸ "+title, "pause:"+schedule.ResumeID),
+		editButton,
+	)
+}
+
+func vacationButtonRow(paused bool) []tgbotapi.InlineKeyboardButton {
+	if paused {
+		return tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("▶️ Возобновить все", "unpause_all"),
+		)
+	}
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏖 Режим отпуска", "vacation"),
+	)
+}
+
+func countPaused(schedules map[string]scheduler.ResumeSchedule) int {
+	count := 0
+	for _, schedule := range schedules {
+		if schedule.Paused {
+			count++
+		}
+	}
+	return count
+}
+
+func sortedTitles(schedules map[string]scheduler.ResumeSchedule) []string {
+	titles := make([]string, 0, len(schedules))
+	for title := range schedules {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles
+}
@@ -0,0 +1,439 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/schedparse"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+var (
+	pickerPresets       = []string{"09:00", "10:00", "12:00", "18:00"}
+	pickerMinutes       = []int{0, 15, 30, 45}
+	pickerIntervalHours = []int{4, 6, 8, 12, 24}
+	pickerWeekdays      = []time.Weekday{
+		time.Monday, time.Tuesday, time.Wednesday, time.Thursday,
+		time.Friday, time.Saturday, time.Sunday,
+	}
+	weekdayNames = map[time.Weekday]string{
+		time.Monday:    "Пн",
+		time.Tuesday:   "Вт",
+		time.Wednesday: "Ср",
+		time.Thursday:  "Чт",
+		time.Friday:    "Пт",
+		time.Saturday:  "Сб",
+		time.Sunday:    "Вс",
+	}
+)
+
+// timePicker - настройки, выбираемые в инлайн-клавиатуре.
+// Хранится в данных шага диалога, чтобы сообщение можно было редактировать на месте.
+type timePicker struct {
+	Account  string // аккаунт резюме в терминах планировщика (пустой - по умолчанию)
+	Title    string // ключ расписания, см. scheduler.ScheduleKey
+	ResumeID string
+	Edit     bool // true - изменение существующего расписания
+	Phrase   bool // true - настройки разобраны из фразы и включают фиксированное время и окна
+	Settings scheduler.ScheduleSettings
+}
+
+func pickerFromState(state dialog.Dialog) timePicker {
+	picker := timePicker{
+		Account:  state.Data["account"],
+		Title:    state.Data["title"],
+		ResumeID: state.Data["resumeID"],
+		Edit:     state.Data["mode"] == "edit",
+	}
+	if err := json.Unmarshal([]byte(state.Data["settings"]), &picker.Settings); err != nil {
+		log.Printf("Failed to restore time picker settings: %v", err)
+	}
+	return picker
+}
+
+// saveTo сохраняет выбор в данные шага диалога. Настройки сохраняются целиком, чтобы
+// фиксированное время, окна и разброс редактируемого расписания не терялись.
+func (p timePicker) saveTo(state *dialog.Dialog) {
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		log.Printf("Failed to save time picker settings: %v", err)
+	}
+
+	state.Data["account"] = p.Account
+	state.Data["title"] = p.Title
+	state.Data["resumeID"] = p.ResumeID
+	state.Data["settings"] = string(settings)
+	if p.Edit {
+		state.Data["mode"] = "edit"
+	}
+}
+
+// startTimePicker показывает клавиатуру выбора расписания вместо сообщения messageID
+func (b *Bot) startTimePicker(chatID int64, messageID int, picker timePicker) {
+	state := dialog.Dialog{
+		Data: map[string]string{
+			"picker_message_id": strconv.Itoa(messageID),
+		},
+	}
+	picker.saveTo(&state)
+	b.dialogs.Start(chatID, stateTimePicker, state.Data)
+
+	b.renderTimePicker(chatID, messageID, picker)
+}
+
+func (b *Bot) renderTimePicker(chatID int64, messageID int, picker timePicker) {
+	settings := picker.Settings
+
+	text := "⏰ <b>Настройка автоподъема</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n\n", picker.Title)
+	if len(settings.Times) > 0 {
+		text += fmt.Sprintf("🕐 Время подъемов: <b>%s</b>\n", formatTimes(settings))
+	} else {
+		text += fmt.Sprintf("⏰ Первый подъем: <b>%02d:%02d</b>\n", settings.Hour, settings.Minute)
+		text += fmt.Sprintf("🔁 Интервал: <b>%s</b>\n", formatInterval(settings.GetInterval()))
+	}
+	text += fmt.Sprintf("📆 Дни: <b>%s</b>\n\n", formatWeekdays(settings.Weekdays))
+	text += "💡 <i>Выберите час, минуты, интервал и дни недели, введите время текстом (ЧЧ:ММ) "
+	text += "или опишите расписание фразой, например «по будням в 9 и 14»</i>"
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+
+	// Быстрые пресеты
+	var presetRow []tgbotapi.InlineKeyboardButton
+	for _, preset := range pickerPresets {
+		presetRow = append(presetRow,
+			tgbotapi.NewInlineKeyboardButtonData("⭐ "+preset, "tp:p:"+strings.ReplaceAll(preset, ":", "")))
+	}
+	keyboard = append(keyboard, presetRow)
+
+	// Сетка часов 4x6
+	for row := 0; row < 4; row++ {
+		var hourRow []tgbotapi.InlineKeyboardButton
+		for col := 0; col < 6; col++ {
+			hour := row*6 + col
+			label := fmt.Sprintf("%02d", hour)
+			if hour == settings.Hour {
+				label = "[" + label + "]"
+			}
+			hourRow = append(hourRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("tp:h:%d", hour)))
+		}
+		keyboard = append(keyboard, hourRow)
+	}
+
+	// Минуты
+	var minuteRow []tgbotapi.InlineKeyboardButton
+	for _, minute := range pickerMinutes {
+		label := fmt.Sprintf(":%02d", minute)
+		if minute == settings.Minute {
+			label = "[" + label + "]"
+		}
+		minuteRow = append(minuteRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("tp:m:%d", minute)))
+	}
+	keyboard = append(keyboard, minuteRow, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("−5 мин", "tp:s:-5"),
+		tgbotapi.NewInlineKeyboardButtonData("+5 мин", "tp:s:5"),
+	))
+
+	// Интервал
+	var intervalRow []tgbotapi.InlineKeyboardButton
+	for _, hours := range pickerIntervalHours {
+		label := fmt.Sprintf("%d ч", hours)
+		if settings.GetInterval() == time.Duration(hours)*time.Hour {
+			label = "🔁 " + label
+		}
+		intervalRow = append(intervalRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("tp:i:%d", hours)))
+	}
+	keyboard = append(keyboard, intervalRow)
+
+	// Дни недели
+	var weekdayRow []tgbotapi.InlineKeyboardButton
+	for _, weekday := range pickerWeekdays {
+		label := weekdayNames[weekday]
+		if len(settings.Weekdays) == 0 || containsWeekday(settings.Weekdays, weekday) {
+			label = "✅" + label
+		}
+		weekdayRow = append(weekdayRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("tp:d:%d", weekday)))
+	}
+	keyboard = append(keyboard, weekdayRow, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Каждый день", "tp:all"),
+		tgbotapi.NewInlineKeyboardButtonData("Будни", "tp:wd"),
+	))
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Готово", "tp:ok"),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "tp:cancel"),
+	))
+
+	b.sendOrEdit(chatID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...), messageID)
+}
+
+// handleTimePickerCallback обрабатывает нажатия в клавиатуре выбора расписания
+func (b *Bot) handleTimePickerCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	state, exists := b.dialogs.Is(chatID, stateTimePicker)
+	if !exists {
+		// Клавиатура устарела (например, после перезапуска бота)
+		b.request(tgbotapi.NewDeleteMessage(chatID, messageID))
+		return
+	}
+
+	picker := pickerFromState(state)
+	settings := &picker.Settings
+	parts := strings.Split(strings.TrimPrefix(callback.Data, "tp:"), ":")
+	value := 0
+	if len(parts) > 1 {
+		value, _ = strconv.Atoi(parts[1])
+	}
+
+	// Выбор времени или интервала кнопками заменяет фиксированное время подъемов
+	switch parts[0] {
+	case "h", "m", "s", "p", "i":
+		settings.Times = nil
+	}
+
+	switch parts[0] {
+	case "h":
+		settings.Hour = value
+	case "m":
+		settings.Minute = value
+	case "s":
+		total := (settings.Hour*60 + settings.Minute + value + 24*60) % (24 * 60)
+		settings.Hour, settings.Minute = total/60, total%60
+	case "p":
+		settings.Hour, settings.Minute = value/100, value%100
+	case "i":
+		settings.Interval = time.Duration(value) * time.Hour
+	case "d":
+		settings.Weekdays = toggleWeekday(settings.Weekdays, time.Weekday(value))
+	case "all":
+		settings.Weekdays = nil
+	case "wd":
+		settings.Weekdays = append([]time.Weekday(nil), pickerWeekdays[:5]...)
+	case "ok":
+		b.finishTimePicker(chatID, messageID, picker)
+		return
+	case "nl":
+		// Применяем расписание, разобранное из фразы
+		parsed, err := schedparse.Parse(state.Data["phrase"])
+		if err != nil {
+			break
+		}
+		picker.Phrase = true
+		picker.Settings = parsed
+		b.finishTimePicker(chatID, messageID, picker)
+		return
+	case "kb":
+		delete(state.Data, "phrase")
+	case "cancel":
+		b.dialogs.Clear(chatID)
+		if picker.Edit {
+			b.handleEditSchedule(chatID, picker.Title, messageID)
+		} else {
+			b.request(tgbotapi.NewDeleteMessage(chatID, messageID))
+		}
+		return
+	}
+
+	picker.saveTo(&state)
+	b.dialogs.Update(chatID, state)
+	b.renderTimePicker(chatID, messageID, picker)
+}
+
+// timePickerBack возвращает к карточке расписания или, при добавлении, к списку резюме
+func (b *Bot) timePickerBack(chatID int64, messageID int, state dialog.Dialog) {
+	picker := pickerFromState(state)
+	if messageID == 0 {
+		messageID, _ = dialogMessageID(state, "picker_message_id")
+	}
+
+	if picker.Edit {
+		b.handleEditSchedule(chatID, picker.Title, editID(messageID)...)
+		return
+	}
+	if messageID != 0 {
+		b.request(tgbotapi.NewDeleteMessage(chatID, messageID))
+	}
+	b.handleAddResume(chatID)
+}
+
+// handleTimePickerText позволяет ввести время (ЧЧ:ММ) или расписание фразой, пока открыта клавиатура выбора
+func (b *Bot) handleTimePickerText(message *tgbotapi.Message, state dialog.Dialog) {
+	chatID := message.Chat.ID
+	picker := pickerFromState(state)
+
+	t, err := time.Parse("15:04", strings.TrimSpace(message.Text))
+	if err != nil {
+		b.handleSchedulePhrase(message, state, picker)
+		return
+	}
+
+	picker.Settings.Hour, picker.Settings.Minute = t.Hour(), t.Minute()
+	picker.Settings.Times = nil
+	picker.saveTo(&state)
+	b.dialogs.Update(chatID, state)
+
+	b.request(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
+	if messageID, err := strconv.Atoi(state.Data["picker_message_id"]); err == nil {
+		b.renderTimePicker(chatID, messageID, picker)
+	}
+}
+
+// handleSchedulePhrase разбирает расписание, описанное фразой, и показывает его для подтверждения
+func (b *Bot) handleSchedulePhrase(message *tgbotapi.Message, state dialog.Dialog, picker timePicker) {
+	chatID := message.Chat.ID
+
+	settings, err := schedparse.Parse(message.Text)
+	if err != nil {
+		text := fmt.Sprintf("🤔 Не удалось разобрать расписание: %s\n\n", escapeHTML(err.Error()))
+		text += "Примеры: <code>по будням в 9 и 14</code>, <code>каждые 6 часов с 8 до 22</code>, "
+		text += "<code>weekdays at 9am</code>"
+		b.send(newHTMLMessage(chatID, text))
+		return
+	}
+	state.Data["phrase"] = message.Text
+	b.dialogs.Update(chatID, state)
+
+	text := "🧠 <b>Расписание из фразы</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n", picker.Title)
+	text += fmt.Sprintf("Фраза: <i>%s</i>\n\n", escapeHTML(message.Text))
+	text += formatScheduleSettings(settings)
+	text += "\n🔄 <b>Расписание на день:</b>\n"
+	for _, minute := range settings.DayPlan() {
+		text += fmt.Sprintf("• %02d:%02d\n", minute/60, minute%60)
+	}
+	text += "\n💡 <i>Все верно? Можно отправить другую фразу или вернуться к кнопкам</i>"
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Применить", "tp:nl"),
+			tgbotapi.NewInlineKeyboardButtonData("⌨️ К кнопкам", "tp:kb"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "tp:cancel"),
+		),
+	)
+
+	b.request(tgbotapi.NewDeleteMessage(chatID, message.MessageID))
+	if messageID, err := strconv.Atoi(state.Data["picker_message_id"]); err == nil {
+		b.sendOrEdit(chatID, text, markup, messageID)
+	}
+}
+
+// finishTimePicker сохраняет расписание и показывает карточку подтверждения с планом на день
+func (b *Bot) finishTimePicker(chatID int64, messageID int, picker timePicker) {
+	b.dialogs.Clear(chatID)
+
+	if picker.Edit {
+		b.updateSchedule(picker.Title, func(settings *scheduler.ScheduleSettings) {
+			settings.Hour = picker.Settings.Hour
+			settings.Minute = picker.Settings.Minute
+			settings.Interval = picker.Settings.Interval
+			settings.Weekdays = picker.Settings.Weekdays
+			settings.Times = picker.Settings.Times
+			if picker.Phrase {
+				settings.Windows = picker.Settings.Windows
+				settings.Jitter = picker.Settings.Jitter
+			}
+		})
+	} else {
+		b.scheduler.AddResume(picker.Account, picker.Title, picker.ResumeID, picker.Settings)
+		b.saveState()
+	}
+
+	schedule, exists := b.scheduler.GetAll()[picker.Title]
+	if !exists {
+		return
+	}
+
+	text := "✅ <b>Автоподъем настроен!</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n", picker.Title)
+	text += fmt.Sprintf("⏰ Первый подъем: <b>%s</b>\n", schedule.NextRun.Format("02.01 15:04"))
+	if len(schedule.Times) > 0 {
+		text += fmt.Sprintf("🕐 Время подъемов: <b>%s</b>\n", formatTimes(schedule.Settings()))
+	} else {
+		text += fmt.Sprintf("🔁 Интервал: <b>%s</b>\n", formatInterval(schedule.GetInterval()))
+	}
+	text += fmt.Sprintf("📆 Дни: <b>%s</b>\n", formatWeekdays(schedule.Weekdays))
+	for _, window := range schedule.Windows {
+		text += fmt.Sprintf("🪟 Окно: <b>%s</b>\n", window)
+	}
+	text += "\n🔄 <b>Расписание на день:</b>\n"
+	for _, minute := range schedule.Settings().DayPlan() {
+		text += fmt.Sprintf("• %02d:%02d\n", minute/60, minute%60)
+	}
+	text += "\n💡 <i>Автоподъем активен! Проверить статус можно в разделе \"📅 Расписание\"</i>"
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Изменить", "edit:"+picker.ResumeID),
+			tgbotapi.NewInlineKeyboardButtonData("📅 Расписание", "schedule_edit"),
+		),
+	)
+	b.sendOrEdit(chatID, text, markup, messageID)
+}
+
+func toggleWeekday(weekdays []time.Weekday, weekday time.Weekday) []time.Weekday {
+	// Пустой список означает "каждый день" - разворачиваем его перед изменением
+	if len(weekdays) == 0 {
+		weekdays = append([]time.Weekday(nil), pickerWeekdays...)
+	}
+
+	var result []time.Weekday
+	for _, current := range weekdays {
+		if current != weekday {
+			result = append(result, current)
+		}
+	}
+	if len(result) == len(weekdays) {
+		result = append(result, weekday)
+	}
+
+	// Все дни или ни одного - то же, что каждый день
+	if len(result) == 0 || len(result) == len(pickerWeekdays) {
+		return nil
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return weekdayOrder(result[i]) < weekdayOrder(result[j])
+	})
+	return result
+}
+
+// weekdayOrder возвращает порядковый номер дня недели, начиная с понедельника
+func weekdayOrder(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, current := range weekdays {
+		if current == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func formatWeekdays(weekdays []time.Weekday) string {
+	switch {
+	case len(weekdays) == 0:
+		return "каждый день"
+	case len(weekdays) == 5 && !containsWeekday(weekdays, time.Saturday) && !containsWeekday(weekdays, time.Sunday):
+		return "будни"
+	case len(weekdays) == 2 && containsWeekday(weekdays, time.Saturday) && containsWeekday(weekdays, time.Sunday):
+		return "выходные"
+	}
+
+	names := make([]string, len(weekdays))
+	for i, weekday := range weekdays {
+		names[i] = weekdayNames[weekday]
+	}
+	return strings.Join(names, ", ")
+}
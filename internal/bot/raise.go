@@ -0,0 +1,251 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// handleRaiseNowCallback немедленно поднимает выбранное резюме
+func (b *Bot) handleRaiseNowCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	resumeID := strings.TrimPrefix(callback.Data, "raise_now:")
+
+	resume, found := b.findResume(resumeID)
+	if !found {
+		msg := tgbotapi.NewMessage(chatID, "Резюме не найдено")
+		b.send(msg)
+		return
+	}
+	b.raiseResume(chatID, resume)
+}
+
+// raiseResume поднимает резюме и показывает результат в одном сообщении
+func (b *Bot) raiseResume(chatID int64, resume hh.Resume) {
+	text := fmt.Sprintf("🚀 <b>Поднимаем резюме...</b>\n\n<code>%s</code>", b.scheduleKey(resume.Title))
+	placeholder, err := b.send(newHTMLMessage(chatID, text))
+	if err != nil {
+		return
+	}
+
+	text = fmt.Sprintf("📄 <b>%s</b>\n%s", b.scheduleKey(resume.Title), b.raiseNowText(resume))
+	b.showResult(chatID, placeholder.MessageID, text)
+}
+
+// handleRaiseAll немедленно поднимает все резюме аккаунта
+func (b *Bot) handleRaiseAll(callback *tgbotapi.CallbackQuery) {
+	b.raiseAll(callback.Message.Chat.ID)
+}
+
+func (b *Bot) raiseAll(chatID int64) {
+	resumes, err := b.resumes()
+	if err != nil || len(resumes) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "Обновите список резюме.")
+		b.send(msg)
+		return
+	}
+
+	placeholder, err := b.send(newHTMLMessage(chatID, fmt.Sprintf("🚀 <b>Поднимаем резюме (%d)...</b>", len(resumes))))
+	if err != nil {
+		return
+	}
+
+	text := fmt.Sprintf("🚀 <b>Подъем всех резюме (%d)</b>\n\n", len(resumes))
+	for _, resume := range resumes {
+		text += fmt.Sprintf("<code>%s</code>\n%s\n\n", resume.Title, b.raiseNowText(resume))
+	}
+	b.showResult(chatID, placeholder.MessageID, text)
+}
+
+func (b *Bot) raiseNowText(resume hh.Resume) string {
+	return b.raiseText(b.account(), b.scheduleKey(resume.Title), resume.ID)
+}
+
+// raiseText немедленно поднимает резюме аккаунта и описывает результат
+func (b *Bot) raiseText(account, key, resumeID string) string {
+	code, err := b.scheduler.RaiseNow(account, key, resumeID)
+	// Подъем мог обнаружить истекшую сессию или перелогиниться - статус в кэше больше не точен
+	b.resumeCaches[b.accountName(account)].Invalidate()
+	switch {
+	case errors.Is(err, scheduler.ErrRaiseInProgress):
+		return "⏳ Резюме уже поднимается, дождитесь результата"
+	case err != nil:
+		return "❌ Ошибка: " + escapeHTML(err.Error())
+	default:
+		return notify.StatusText(code)
+	}
+}
+
+// handleOneOff предлагает выбрать резюме для разового подъема
+func (b *Bot) handleOneOff(callback *tgbotapi.CallbackQuery) {
+	b.sendOneOffList(callback.Message.Chat.ID)
+}
+
+func (b *Bot) sendOneOffList(chatID int64, editMessageID ...int) {
+	resumes, err := b.resumes()
+	if err != nil || len(resumes) == 0 {
+		msg := tgbotapi.NewMessage(chatID, "Обновите список резюме.")
+		b.send(msg)
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, resume := range resumes {
+		button := tgbotapi.NewInlineKeyboardButtonData(resume.Title, "oneoff_resume:"+resume.ID)
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+	}
+	cancelButton := tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel_oneoff")
+	keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{cancelButton})
+
+	text := "🎯 <b>Разовый подъем</b>\n\n"
+	text += "Выберите резюме. Разовый подъем выполняется в указанное время "
+	text += "независимо от расписания и режима паузы, после чего удаляется."
+
+	b.sendOrEdit(chatID, text, tgbotapi.NewInlineKeyboardMarkup(keyboard...), editMessageID...)
+}
+
+// handleOneOffResumeCallback запрашивает время разового подъема
+func (b *Bot) handleOneOffResumeCallback(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	resumeID := strings.TrimPrefix(callback.Data, "oneoff_resume:")
+
+	resume, found := b.findResume(resumeID)
+	if !found {
+		msg := tgbotapi.NewMessage(chatID, "Резюме не найдено")
+		b.send(msg)
+		return
+	}
+
+	b.dialogs.Start(chatID, stateOneOffTime, map[string]string{
+		"account":  b.account(),
+		"title":    b.scheduleKey(resume.Title),
+		"resumeID": resume.ID,
+	})
+
+	text := "🎯 <b>Разовый подъем</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n\n", b.scheduleKey(resume.Title))
+	text += "📋 <b>Введите дату и время подъема</b>\n"
+	text += "Например: <code>14:30</code>, <code>25.12 09:00</code> или <code>25.12.2025 09:00</code>"
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", "dialog:back"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "cancel_oneoff"),
+		),
+	)
+	b.sendOrEdit(chatID, text, markup, callback.Message.MessageID)
+}
+
+// handleOneOffTime обрабатывает введенное время разового подъема
+func (b *Bot) handleOneOffTime(message *tgbotapi.Message, state dialog.Dialog) {
+	chatID := message.Chat.ID
+
+	at, err := parseOneOffTime(message.Text, time.Now())
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, "Ошибка при вводе времени, используйте формат 14:30 или 25.12 09:00.")
+		b.send(msg)
+		return
+	}
+
+	oneOff := b.scheduler.AddOneOff(state.Data["account"], state.Data["title"], state.Data["resumeID"], at)
+	b.saveState()
+	b.dialogs.Clear(chatID)
+
+	text := "✅ <b>Разовый подъем запланирован</b>\n\n"
+	text += fmt.Sprintf("Резюме: <code>%s</code>\n", oneOff.Title)
+	text += fmt.Sprintf("🕐 Время: <b>%s</b>\n\n", oneOff.At.Format("02.01.2006 15:04"))
+	text += "💡 <i>Отменить можно в разделе \"📅 Расписание\"</i>"
+	b.send(newHTMLMessage(chatID, text))
+}
+
+// handleOneOffDeleteCallback отменяет разовый подъем
+func (b *Bot) handleOneOffDeleteCallback(callback *tgbotapi.CallbackQuery) {
+	if b.scheduler.RemoveOneOff(strings.TrimPrefix(callback.Data, "oneoff_delete:")) {
+		b.saveState()
+	}
+	b.handleShowSchedule(callback.Message.Chat.ID, callback.Message.MessageID)
+}
+
+func (b *Bot) handleCancelOneOff(callback *tgbotapi.CallbackQuery) {
+	chatID := callback.Message.Chat.ID
+	b.request(tgbotapi.NewDeleteMessage(chatID, callback.Message.MessageID))
+	b.dialogs.Clear(chatID)
+}
+
+// findResume ищет резюме активного аккаунта по ID
+func (b *Bot) findResume(resumeID string) (hh.Resume, bool) {
+	resumes, err := b.resumes()
+	if err != nil {
+		return hh.Resume{}, false
+	}
+	for _, resume := range resumes {
+		if resume.ID == resumeID {
+			return resume, true
+		}
+	}
+	return hh.Resume{}, false
+}
+
+// parseOneOffTime разбирает момент разового подъема: ЧЧ:ММ, ДД.ММ ЧЧ:ММ или ДД.ММ.ГГГГ ЧЧ:ММ.
+// Время без даты означает ближайшее такое время.
+func parseOneOffTime(text string, now time.Time) (time.Time, error) {
+	text = strings.Join(strings.Fields(text), " ")
+
+	if at, err := time.ParseInLocation("02.01.2006 15:04", text, now.Location()); err == nil {
+		if !at.After(now) {
+			return time.Time{}, fmt.Errorf("time %s is in the past", text)
+		}
+		return at, nil
+	}
+
+	if at, err := time.ParseInLocation("02.01 15:04", text, now.Location()); err == nil {
+		at = time.Date(now.Year(), at.Month(), at.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(1, 0, 0)
+		}
+		return at, nil
+	}
+
+	at, err := time.ParseInLocation("15:04", text, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time: %s", text)
+	}
+	at = time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at, nil
+}
+
+func raiseNowKeyboard(resumes []hh.Resume) tgbotapi.InlineKeyboardMarkup {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, resume := range resumes {
+		button := tgbotapi.NewInlineKeyboardButtonData("🚀 Поднять сейчас: "+resume.Title, "raise_now:"+resume.ID)
+		keyboard = append(keyboard, []tgbotapi.InlineKeyboardButton{button})
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🚀 Поднять все", "raise_all"),
+		tgbotapi.NewInlineKeyboardButtonData("🎯 Разовый подъем", "oneoff"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+func oneOffButtonRow(oneOff scheduler.OneOffRaise) []tgbotapi.InlineKeyboardButton {
+	text := fmt.Sprintf("🗑 %s %s", oneOff.At.Format("02.01 15:04"), oneOff.Title)
+	return tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(text, "oneoff_delete:"+oneOff.ID),
+	)
+}
+
+func newHTMLMessage(chatID int64, text string) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	return msg
+}
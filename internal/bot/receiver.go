@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+const (
+	// webhookSecretHeader - заголовок, в котором Telegram передает секретный токен вебхука
+	webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+	// webhookShutdownTimeout - сколько ждать завершения запросов при остановке сервера
+	webhookShutdownTimeout = 5 * time.Second
+)
+
+// newBotAPI подключается к Telegram Bot API по адресу из конфигурации: к api.telegram.org,
+// локальному серверу Bot API или его имитации
+func newBotAPI(cfg *config.Config) (*tgbotapi.BotAPI, error) {
+	endpoint := tgbotapi.APIEndpoint
+	if cfg.Telegram.APIURL != "" {
+		endpoint = strings.TrimRight(cfg.Telegram.APIURL, "/") + "/bot%s/%s"
+	}
+	return tgbotapi.NewBotAPIWithAPIEndpoint(cfg.TelegramToken, endpoint)
+}
+
+// webhookReceiver принимает обновления Telegram HTTP-запросами на адрес вебхука.
+// Запросы без верного секретного токена отклоняются.
+type webhookReceiver struct {
+	api     *tgbotapi.BotAPI
+	secret  string
+	server  *http.Server
+	updates chan tgbotapi.Update
+}
+
+// startWebhook запускает HTTP(S)-сервер и регистрирует вебхук в Telegram
+func startWebhook(api *tgbotapi.BotAPI, cfg config.TelegramWebhook) (*webhookReceiver, error) {
+	link, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	secret := cfg.Secret
+	if secret == "" {
+		// Telegram принимает токен из букв, цифр, "_" и "-"
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	r := &webhookReceiver{
+		api:     api,
+		secret:  secret,
+		updates: make(chan tgbotapi.Update, api.Buffer),
+	}
+
+	path := link.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, r)
+	r.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	// Порт занимаем сразу, чтобы ошибка привела к long polling, а не к вебхуку без сервера
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		var err error
+		if cfg.CertFile != "" && cfg.KeyFile != "" {
+			err = r.server.ServeTLS(listener, cfg.CertFile, cfg.KeyFile)
+		} else {
+			err = r.server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Telegram webhook server stopped: %v", err)
+		}
+	}()
+
+	params := tgbotapi.Params{"url": link.String(), "secret_token": secret}
+	if _, err := api.MakeRequest("setWebhook", params); err != nil {
+		r.shutdown()
+		return nil, fmt.Errorf("failed to set webhook: %w", err)
+	}
+	log.Printf("Receiving Telegram updates via webhook %s (listening on %s)", link.Redacted(), cfg.Listen)
+	return r, nil
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := req.Header.Get(webhookSecretHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(r.secret)) != 1 {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case r.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-req.Context().Done():
+		// Telegram повторит обновление, которое не было принято
+	}
+}
+
+// stop удаляет вебхук в Telegram и останавливает сервер
+func (r *webhookReceiver) stop() {
+	if _, err := r.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("Failed to delete webhook: %v", err)
+	}
+	r.shutdown()
+}
+
+func (r *webhookReceiver) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+	if err := r.server.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop webhook server: %v", err)
+	}
+}
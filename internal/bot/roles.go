@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+// callbackRoles - роль, необходимая для инлайн-кнопок: данные callback равны
+// имени или начинаются с "имя:".
+// Все, чего нет в списке, меняет состояние и требует роли operator.
+var callbackRoles = []struct {
+	prefix string
+	role   config.Role
+}{
+	{"auth", config.RoleOwner},
+	{"account", config.RoleOwner},
+	{"alert_relogin", config.RoleOwner},
+	{"history", config.RoleViewer},
+	{"schedule", config.RoleViewer},
+	{"update_resumes", config.RoleViewer},
+}
+
+// messageRole возвращает роль, необходимую для кнопки меню.
+// Текст, не относящийся к командам, доступен для просмотра (viewer).
+func messageRole(text string) config.Role {
+	if cmd, ok := commandByButton(text); ok {
+		return cmd.role
+	}
+	return config.RoleViewer
+}
+
+func callbackRole(data string) config.Role {
+	for _, entry := range callbackRoles {
+		if data == entry.prefix || strings.HasPrefix(data, entry.prefix+":") {
+			return entry.role
+		}
+	}
+	return config.RoleOperator
+}
+
+// roleOf возвращает роль пользователя в личном чате с ботом (ID чата совпадает с ID пользователя)
+func (b *Bot) roleOf(chatID int64) config.Role {
+	return b.config.RoleOf(chatID)
+}
+
+// canOperate сообщает, может ли пользователь чата управлять расписанием и поднимать резюме
+func (b *Bot) canOperate(chatID int64) bool {
+	return b.roleOf(chatID).Allows(config.RoleOperator)
+}
+
+// replyKeyboardFor собирает клавиатуру меню, убирая кнопки, недоступные пользователю чата
+func (b *Bot) replyKeyboardFor(chatID int64, rows ...[]tgbotapi.KeyboardButton) tgbotapi.ReplyKeyboardMarkup {
+	role := b.roleOf(chatID)
+
+	var allowed [][]tgbotapi.KeyboardButton
+	for _, row := range rows {
+		var buttons []tgbotapi.KeyboardButton
+		for _, button := range row {
+			if role.Allows(messageRole(button.Text)) {
+				buttons = append(buttons, button)
+			}
+		}
+		if len(buttons) > 0 {
+			allowed = append(allowed, buttons)
+		}
+	}
+	return tgbotapi.NewReplyKeyboard(allowed...)
+}
+
+// inlineKeyboardFor собирает инлайн-клавиатуру, убирая кнопки, недоступные пользователю чата
+func (b *Bot) inlineKeyboardFor(chatID int64, rows ...[]tgbotapi.InlineKeyboardButton) tgbotapi.InlineKeyboardMarkup {
+	role := b.roleOf(chatID)
+
+	allowed := [][]tgbotapi.InlineKeyboardButton{}
+	for _, row := range rows {
+		var buttons []tgbotapi.InlineKeyboardButton
+		for _, button := range row {
+			if button.CallbackData == nil || role.Allows(callbackRole(*button.CallbackData)) {
+				buttons = append(buttons, button)
+			}
+		}
+		if len(buttons) > 0 {
+			allowed = append(allowed, buttons)
+		}
+	}
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: allowed}
+}
+
+func (b *Bot) sendForbidden(chatID int64, required config.Role) {
+	text := "🚫 <b>Недостаточно прав</b>\n\n"
+	switch required {
+	case config.RoleOwner:
+		text += "Это действие доступно только владельцу бота."
+	default:
+		text += "Ваша роль позволяет только просматривать статус, расписание и историю."
+	}
+	b.send(newHTMLMessage(chatID, text))
+}
+
+// roleName возвращает название роли для интерфейса
+func roleName(role config.Role) string {
+	switch role {
+	case config.RoleOwner:
+		return "владелец"
+	case config.RoleOperator:
+		return "оператор"
+	case config.RoleViewer:
+		return "наблюдатель"
+	}
+	return "нет доступа"
+}
+
+func (b *Bot) answerForbidden(callback *tgbotapi.CallbackQuery) {
+	b.request(tgbotapi.NewCallbackWithAlert(callback.ID, "🚫 Недостаточно прав"))
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"time"
+)
+
+// ErrStopped возвращает Start после штатной остановки через Stop
+var ErrStopped = errors.New("bot stopped")
+
+const (
+	restartInitialDelay = time.Second
+	restartMaxDelay     = time.Minute
+	// restartResetAfter - после такой работы без сбоев пауза перед перезапуском снова минимальна
+	restartResetAfter = 5 * time.Minute
+)
+
+// Supervise запускает start и перезапускает его, если он завершился ошибкой или паникой,
+// с растущей паузой между попытками. О каждом перезапуске сообщает report (может быть nil).
+// Возвращается, только когда start вернул ErrStopped.
+func Supervise(name string, start func() error, report func(text string)) {
+	var delay time.Duration
+	for {
+		startedAt := time.Now()
+		err := runProtected(start)
+		if errors.Is(err, ErrStopped) {
+			return
+		}
+
+		delay = nextRestartDelay(delay, time.Since(startedAt))
+		log.Printf("%s stopped unexpectedly: %v; restarting in %s", name, err, delay)
+		if report != nil {
+			text := "⚠️ <b>Бот перезапускается после сбоя</b>\n\n"
+			text += fmt.Sprintf("Ошибка: <code>%s</code>", escapeHTML(err.Error()))
+			report(text)
+		}
+
+		restartSleep(delay)
+	}
+}
+
+// restartSleep - пауза перед перезапуском; тесты подменяют ее, чтобы не ждать
+var restartSleep = time.Sleep
+
+// nextRestartDelay возвращает паузу перед перезапуском: previous - прошлая пауза (0 - сбоев
+// еще не было), uptime - сколько start проработал. Пауза удваивается до restartMaxDelay
+// и снова становится минимальной после долгой работы без сбоев.
+func nextRestartDelay(previous, uptime time.Duration) time.Duration {
+	if previous == 0 || uptime > restartResetAfter {
+		return restartInitialDelay
+	}
+	if delay := previous * 2; delay < restartMaxDelay {
+		return delay
+	}
+	return restartMaxDelay
+}
+
+// runProtected превращает панику start в ошибку
+func runProtected(start func() error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("Panic: %v\n%s", recovered, debug.Stack())
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	if err := start(); err != nil {
+		return err
+	}
+	return errors.New("exited without error")
+}
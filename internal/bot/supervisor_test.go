@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextRestartDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous time.Duration
+		uptime   time.Duration
+		want     time.Duration
+	}{
+		{"first failure", 0, time.Second, restartInitialDelay},
+		{"doubles after quick failure", time.Second, time.Second, 2 * time.Second},
+		{"keeps doubling", 8 * time.Second, time.Second, 16 * time.Second},
+		{"capped at max delay", 32 * time.Second, time.Second, restartMaxDelay},
+		{"stays at max delay", restartMaxDelay, time.Second, restartMaxDelay},
+		{"reset after long uptime", restartMaxDelay, restartResetAfter + time.Second, restartInitialDelay},
+		{"no reset at exactly reset time", 4 * time.Second, restartResetAfter, 8 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRestartDelay(tt.previous, tt.uptime); got != tt.want {
+				t.Errorf("nextRestartDelay(%s, %s) = %s, want %s", tt.previous, tt.uptime, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuperviseRestarts(t *testing.T) {
+	var delays []time.Duration
+	sleep := restartSleep
+	restartSleep = func(delay time.Duration) { delays = append(delays, delay) }
+	t.Cleanup(func() { restartSleep = sleep })
+
+	// Два сбоя с ошибкой, паника, выход без ошибки и штатная остановка
+	runs := []func() error{
+		func() error { return errors.New("connection reset") },
+		func() error { return errors.New("connection reset") },
+		func() error { panic("boom") },
+		func() error { return nil },
+		func() error { return ErrStopped },
+	}
+	started := 0
+	var reports []string
+
+	Supervise("test", func() error {
+		run := runs[started]
+		started++
+		return run()
+	}, func(text string) { reports = append(reports, text) })
+
+	if started != len(runs) {
+		t.Fatalf("started %d times, want %d", started, len(runs))
+	}
+	wantDelays := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+	if len(delays) != len(wantDelays) {
+		t.Fatalf("delays = %v, want %v", delays, wantDelays)
+	}
+	for i := range wantDelays {
+		if delays[i] != wantDelays[i] {
+			t.Errorf("delays = %v, want %v", delays, wantDelays)
+			break
+		}
+	}
+
+	wantReports := []string{"connection reset", "connection reset", "panic: boom", "exited without error"}
+	if len(reports) != len(wantReports) {
+		t.Fatalf("got %d reports, want %d", len(reports), len(wantReports))
+	}
+	for i, want := range wantReports {
+		if !strings.Contains(reports[i], want) {
+			t.Errorf("report %d = %q, want it to contain %q", i, reports[i], want)
+		}
+	}
+}
+
+func TestSuperviseWithoutReport(t *testing.T) {
+	sleep := restartSleep
+	restartSleep = func(time.Duration) {}
+	t.Cleanup(func() { restartSleep = sleep })
+
+	started := 0
+	Supervise("test", func() error {
+		started++
+		if started == 1 {
+			return errors.New("failed")
+		}
+		return ErrStopped
+	}, nil)
+
+	if started != 2 {
+		t.Errorf("started %d times, want 2", started)
+	}
+}
@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+// webhooksHistoryLimit - сколько последних завершенных доставок показывать
+const webhooksHistoryLimit = 15
+
+// handleWebhooks показывает очередь доставки вебхуков и последние доставки
+func (b *Bot) handleWebhooks(chatID int64) {
+	queue, err := b.storage.LoadWebhookQueue()
+	if err == nil {
+		var deliveries []notify.Delivery
+		deliveries, err = b.storage.LoadWebhookDeliveries()
+		if err == nil {
+			b.sendWebhooks(chatID, queue, deliveries)
+			return
+		}
+	}
+
+	log.Printf("Failed to load webhook deliveries: %v", err)
+	msg := tgbotapi.NewMessage(chatID, "❌ Не удалось загрузить историю вебхуков")
+	b.send(msg)
+}
+
+func (b *Bot) sendWebhooks(chatID int64, queue, deliveries []notify.Delivery) {
+	if len(queue) == 0 && len(deliveries) == 0 {
+		text := "🪝 <b>Доставок вебхуков пока не было</b>\n\n"
+		text += "Вебхук включается переменной NOTIFY_WEBHOOK_URL."
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "HTML"
+		b.send(msg)
+		return
+	}
+
+	text := "🪝 <b>Вебхуки</b>\n"
+	if len(queue) > 0 {
+		text += fmt.Sprintf("\n<b>В очереди: %d</b>\n", len(queue))
+		for _, delivery := range queue {
+			text += webhookDeliveryLine(delivery)
+		}
+	}
+
+	if len(deliveries) > webhooksHistoryLimit {
+		deliveries = deliveries[len(deliveries)-webhooksHistoryLimit:]
+	}
+	if len(deliveries) > 0 {
+		text += "\n<b>Последние доставки</b>\n"
+		for i := len(deliveries) - 1; i >= 0; i-- {
+			text += webhookDeliveryLine(deliveries[i])
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	b.send(msg)
+}
+
+// webhookDeliveryLine описывает доставку одной строкой: событие, попытки и результат
+func webhookDeliveryLine(delivery notify.Delivery) string {
+	line := fmt.Sprintf("%s %s <code>%s</code>, попыток: %d",
+		webhookStateIcon(delivery.State),
+		delivery.UpdatedAt.Format("02.01 15:04"),
+		escapeHTML(string(delivery.Event.Type)),
+		delivery.Attempts)
+
+	switch {
+	case delivery.LastError != "":
+		line += " - " + escapeHTML(delivery.LastError)
+	case delivery.LastStatus != 0:
+		line += fmt.Sprintf(" - HTTP %d", delivery.LastStatus)
+	}
+	if delivery.State == notify.DeliveryPending && delivery.Attempts > 0 {
+		line += ", повтор в " + delivery.NextAttempt.Format("15:04")
+	}
+	return line + "\n"
+}
+
+func webhookStateIcon(state notify.DeliveryState) string {
+	switch state {
+	case notify.DeliveryDelivered:
+		return "✅"
+	case notify.DeliveryFailed:
+		return "❌"
+	}
+	return "⏳"
+}
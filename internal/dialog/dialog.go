@@ -0,0 +1,253 @@
+// Package dialog хранит состояние многошаговых диалогов с пользователями:
+// текущий шаг, его данные, предыдущий шаг для возврата "назад" и срок ожидания ответа.
+package dialog
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State - шаг диалога
+type State string
+
+var (
+	// ErrNotFound - у чата нет активного диалога
+	ErrNotFound = errors.New("no active dialog")
+	// ErrExpired - пользователь не ответил вовремя, диалог сброшен
+	ErrExpired = errors.New("dialog expired")
+)
+
+// Dialog - состояние диалога в чате
+type Dialog struct {
+	State     State             `json:"state"`
+	Data      map[string]string `json:"data,omitempty"`
+	Previous  *Dialog           `json:"previous,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// SaveHandler получает снимок всех диалогов после каждого изменения
+type SaveHandler func(dialogs map[int64]Dialog)
+
+// ExpireHandler вызывается для диалога, сброшенного по таймауту
+type ExpireHandler func(chatID int64, dialog Dialog)
+
+// Manager потокобезопасно хранит диалоги чатов.
+// Наружу отдаются копии: изменения применяются через Update.
+type Manager struct {
+	dialogs        map[int64]*Dialog
+	timeouts       map[State]time.Duration
+	defaultTimeout time.Duration
+	saveHandler    SaveHandler
+	stop           chan struct{}
+	mutex          sync.Mutex
+}
+
+// New создает хранилище диалогов. Шаг без собственного таймаута ждет ответа defaultTimeout.
+func New(defaultTimeout time.Duration, timeouts map[State]time.Duration) *Manager {
+	return &Manager{
+		dialogs:        make(map[int64]*Dialog),
+		timeouts:       timeouts,
+		defaultTimeout: defaultTimeout,
+	}
+}
+
+// SetSaveHandler задает обработчик сохранения диалогов
+func (m *Manager) SetSaveHandler(handler SaveHandler) {
+	m.saveHandler = handler
+}
+
+// Restore восстанавливает сохраненные диалоги, пропуская истекшие
+func (m *Manager) Restore(dialogs map[int64]Dialog) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	for chatID, dialog := range dialogs {
+		if dialog.ExpiresAt.After(now) {
+			m.dialogs[chatID] = dialog.clone()
+		}
+	}
+}
+
+// Get возвращает копию диалога чата. Истекший диалог удаляется, а Get возвращает ErrExpired.
+func (m *Manager) Get(chatID int64) (Dialog, error) {
+	m.mutex.Lock()
+	dialog, exists := m.dialogs[chatID]
+	if !exists {
+		m.mutex.Unlock()
+		return Dialog{}, ErrNotFound
+	}
+	if !dialog.ExpiresAt.After(time.Now()) {
+		delete(m.dialogs, chatID)
+		m.mutex.Unlock()
+		m.save()
+		return Dialog{}, ErrExpired
+	}
+	result := *dialog.clone()
+	m.mutex.Unlock()
+	return result, nil
+}
+
+// Is возвращает диалог чата, если он находится на шаге state
+func (m *Manager) Is(chatID int64, state State) (Dialog, bool) {
+	dialog, err := m.Get(chatID)
+	if err != nil || dialog.State != state {
+		return Dialog{}, false
+	}
+	return dialog, true
+}
+
+// Start начинает новый диалог, заменяя текущий
+func (m *Manager) Start(chatID int64, state State, data map[string]string) Dialog {
+	return m.set(chatID, &Dialog{State: state, Data: data})
+}
+
+// Push переходит к следующему шагу, запоминая текущий для возврата через Back
+func (m *Manager) Push(chatID int64, state State, data map[string]string) Dialog {
+	previous, err := m.Get(chatID)
+	if err != nil {
+		return m.Start(chatID, state, data)
+	}
+	return m.set(chatID, &Dialog{State: state, Data: data, Previous: &previous})
+}
+
+// Update сохраняет измененные данные шага и продлевает срок ожидания ответа
+func (m *Manager) Update(chatID int64, dialog Dialog) {
+	m.set(chatID, &dialog)
+}
+
+// Back возвращает диалог к предыдущему шагу. Если его нет, диалог завершается и ok = false.
+func (m *Manager) Back(chatID int64) (previous Dialog, ok bool) {
+	m.mutex.Lock()
+	dialog, exists := m.dialogs[chatID]
+	m.mutex.Unlock()
+
+	if !exists || dialog.Previous == nil {
+		m.Clear(chatID)
+		return Dialog{}, false
+	}
+	return m.set(chatID, dialog.Previous), true
+}
+
+// Clear завершает диалог чата и возвращает его последнее состояние
+func (m *Manager) Clear(chatID int64) (Dialog, bool) {
+	m.mutex.Lock()
+	dialog, exists := m.dialogs[chatID]
+	delete(m.dialogs, chatID)
+	m.mutex.Unlock()
+
+	if !exists {
+		return Dialog{}, false
+	}
+	m.save()
+	return *dialog, true
+}
+
+// StartExpiry раз в interval сбрасывает истекшие диалоги и сообщает о каждом обработчику
+func (m *Manager) StartExpiry(interval time.Duration, handler ExpireHandler) {
+	m.mutex.Lock()
+	if m.stop != nil {
+		m.mutex.Unlock()
+		return
+	}
+	m.stop = make(chan struct{})
+	stop := m.stop
+	m.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				for chatID, dialog := range m.expire() {
+					handler(chatID, dialog)
+				}
+			}
+		}
+	}()
+}
+
+// Stop останавливает сброс истекших диалогов
+func (m *Manager) Stop() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+func (m *Manager) expire() map[int64]Dialog {
+	m.mutex.Lock()
+	expired := make(map[int64]Dialog)
+	now := time.Now()
+	for chatID, dialog := range m.dialogs {
+		if !dialog.ExpiresAt.After(now) {
+			expired[chatID] = *dialog
+			delete(m.dialogs, chatID)
+		}
+	}
+	m.mutex.Unlock()
+
+	if len(expired) > 0 {
+		m.save()
+	}
+	return expired
+}
+
+func (m *Manager) set(chatID int64, dialog *Dialog) Dialog {
+	dialog = dialog.clone()
+	if dialog.Data == nil {
+		dialog.Data = make(map[string]string)
+	}
+	dialog.ExpiresAt = time.Now().Add(m.timeout(dialog.State))
+
+	m.mutex.Lock()
+	m.dialogs[chatID] = dialog
+	result := *dialog.clone()
+	m.mutex.Unlock()
+
+	m.save()
+	return result
+}
+
+func (m *Manager) timeout(state State) time.Duration {
+	if timeout, exists := m.timeouts[state]; exists {
+		return timeout
+	}
+	return m.defaultTimeout
+}
+
+func (m *Manager) save() {
+	if m.saveHandler == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	snapshot := make(map[int64]Dialog, len(m.dialogs))
+	for chatID, dialog := range m.dialogs {
+		snapshot[chatID] = *dialog.clone()
+	}
+	m.mutex.Unlock()
+
+	m.saveHandler(snapshot)
+}
+
+// clone копирует диалог вместе с данными и цепочкой предыдущих шагов
+func (d *Dialog) clone() *Dialog {
+	result := *d
+	if d.Data != nil {
+		result.Data = make(map[string]string, len(d.Data))
+		for key, value := range d.Data {
+			result.Data[key] = value
+		}
+	}
+	if d.Previous != nil {
+		result.Previous = d.Previous.clone()
+	}
+	return &result
+}
@@ -0,0 +1,235 @@
+package dialog_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/dialog"
+	"hh-ru-auto-resume-raising/internal/storage"
+)
+
+const (
+	stateLogin    dialog.State = "login"
+	statePassword dialog.State = "password"
+	stateConfirm  dialog.State = "confirm"
+)
+
+func TestStateTimeout(t *testing.T) {
+	m := dialog.New(time.Hour, map[dialog.State]time.Duration{statePassword: 20 * time.Millisecond})
+
+	tests := []struct {
+		name    string
+		state   dialog.State
+		wantErr error
+	}{
+		{"own timeout expires", statePassword, dialog.ErrExpired},
+		{"default timeout still waits", stateLogin, nil},
+	}
+
+	for i, tt := range tests {
+		m.Start(int64(i), tt.state, nil)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Get(int64(i))
+			if err != tt.wantErr {
+				t.Fatalf("Get error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.State != tt.state {
+				t.Errorf("state = %q, want %q", got.State, tt.state)
+			}
+		})
+	}
+
+	// Истекший диалог удаляется при первом обращении
+	if _, err := m.Get(0); err != dialog.ErrNotFound {
+		t.Errorf("Get after expiry error = %v, want %v", err, dialog.ErrNotFound)
+	}
+}
+
+func TestUpdateExtendsTimeout(t *testing.T) {
+	m := dialog.New(50*time.Millisecond, nil)
+
+	m.Start(1, stateLogin, nil)
+	time.Sleep(30 * time.Millisecond)
+	current, err := m.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current.Data["login"] = "user@example.com"
+	m.Update(1, current)
+	time.Sleep(30 * time.Millisecond)
+
+	got, err := m.Get(1)
+	if err != nil {
+		t.Fatalf("Get after update error = %v, want none", err)
+	}
+	if got.Data["login"] != "user@example.com" {
+		t.Errorf("login = %q, want updated value", got.Data["login"])
+	}
+}
+
+func TestStartExpiry(t *testing.T) {
+	m := dialog.New(time.Hour, map[dialog.State]time.Duration{statePassword: 10 * time.Millisecond})
+	m.Start(1, statePassword, map[string]string{"login": "user@example.com"})
+	m.Start(2, stateLogin, nil)
+
+	expired := make(chan int64, 2)
+	m.StartExpiry(5*time.Millisecond, func(chatID int64, d dialog.Dialog) {
+		if d.State != statePassword || d.Data["login"] != "user@example.com" {
+			t.Errorf("expired dialog = %+v, want password step with its data", d)
+		}
+		expired <- chatID
+	})
+	defer m.Stop()
+
+	select {
+	case chatID := <-expired:
+		if chatID != 1 {
+			t.Errorf("expired chat = %d, want 1", chatID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expiry handler was not called")
+	}
+
+	if _, err := m.Get(2); err != nil {
+		t.Errorf("dialog with default timeout was reset: %v", err)
+	}
+}
+
+func TestBack(t *testing.T) {
+	m := dialog.New(time.Hour, nil)
+	m.Start(1, stateLogin, map[string]string{"step": "1"})
+	m.Push(1, statePassword, map[string]string{"step": "2"})
+	m.Push(1, stateConfirm, map[string]string{"step": "3"})
+
+	tests := []struct {
+		wantState dialog.State
+		wantStep  string
+		wantOK    bool
+	}{
+		{statePassword, "2", true},
+		{stateLogin, "1", true},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := m.Back(1)
+		if ok != tt.wantOK || got.State != tt.wantState || got.Data["step"] != tt.wantStep {
+			t.Fatalf("Back = %q (step %q), %v, want %q (step %q), %v",
+				got.State, got.Data["step"], ok, tt.wantState, tt.wantStep, tt.wantOK)
+		}
+		if !ok {
+			continue
+		}
+		if current, err := m.Get(1); err != nil || current.State != tt.wantState {
+			t.Fatalf("current step after Back = %q, %v, want %q", current.State, err, tt.wantState)
+		}
+	}
+
+	// Возврат с первого шага завершает диалог
+	if _, err := m.Get(1); err != dialog.ErrNotFound {
+		t.Errorf("Get after leaving first step error = %v, want %v", err, dialog.ErrNotFound)
+	}
+}
+
+func TestPushWithoutDialogStarts(t *testing.T) {
+	m := dialog.New(time.Hour, nil)
+	m.Push(1, statePassword, nil)
+
+	if _, ok := m.Back(1); ok {
+		t.Error("Back after Push without a dialog returned a previous step")
+	}
+}
+
+func TestClear(t *testing.T) {
+	m := dialog.New(time.Hour, nil)
+	m.Start(1, stateLogin, nil)
+	m.Push(1, statePassword, map[string]string{"login": "user@example.com"})
+
+	last, ok := m.Clear(1)
+	if !ok || last.State != statePassword || last.Data["login"] != "user@example.com" {
+		t.Fatalf("Clear = %+v, %v, want last password step", last, ok)
+	}
+	if _, err := m.Get(1); err != dialog.ErrNotFound {
+		t.Errorf("Get after Clear error = %v, want %v", err, dialog.ErrNotFound)
+	}
+	if _, ok := m.Clear(1); ok {
+		t.Error("second Clear returned ok")
+	}
+}
+
+func TestGetReturnsCopy(t *testing.T) {
+	m := dialog.New(time.Hour, nil)
+	m.Start(1, stateLogin, map[string]string{"login": "user@example.com"})
+
+	got, _ := m.Get(1)
+	got.Data["login"] = "changed"
+
+	if current, _ := m.Get(1); current.Data["login"] != "user@example.com" {
+		t.Errorf("stored login = %q, changed without Update", current.Data["login"])
+	}
+}
+
+func TestSaveAndRestoreThroughStorage(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	store := storage.New()
+	m := dialog.New(time.Hour, nil)
+	m.SetSaveHandler(func(dialogs map[int64]dialog.Dialog) {
+		if err := store.SaveDialogs(dialogs); err != nil {
+			t.Errorf("SaveDialogs: %v", err)
+		}
+	})
+
+	m.Start(1, stateLogin, map[string]string{"login": "user@example.com"})
+	m.Push(1, statePassword, nil)
+	m.Start(2, stateConfirm, nil)
+	m.Clear(2)
+
+	saved, err := store.LoadDialogs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Истекший диалог не должен пережить перезапуск
+	saved[3] = dialog.Dialog{State: stateLogin, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	restored := dialog.New(time.Hour, nil)
+	restored.Restore(saved)
+
+	tests := []struct {
+		name      string
+		chatID    int64
+		wantState dialog.State
+		wantErr   error
+	}{
+		{"active dialog restored", 1, statePassword, nil},
+		{"cleared dialog not saved", 2, "", dialog.ErrNotFound},
+		{"expired dialog skipped", 3, "", dialog.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := restored.Get(tt.chatID)
+			if err != tt.wantErr || got.State != tt.wantState {
+				t.Errorf("Get = %q, %v, want %q, %v", got.State, err, tt.wantState, tt.wantErr)
+			}
+		})
+	}
+
+	// Предыдущий шаг восстанавливается вместе с данными
+	previous, ok := restored.Back(1)
+	if !ok || previous.State != stateLogin || previous.Data["login"] != "user@example.com" {
+		t.Errorf("Back after restore = %+v, %v, want login step with its data", previous, ok)
+	}
+}
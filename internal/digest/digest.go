@@ -0,0 +1,314 @@
+// Package digest собирает периодическую сводку: подъемы и ошибки по резюме,
+// просмотры и приглашения, повторные входы и ближайшие подъемы.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// Period - период сводки
+type Period string
+
+const (
+	Daily  Period = "daily"
+	Weekly Period = "weekly"
+)
+
+// Duration возвращает длину периода
+func (p Period) Duration() time.Duration {
+	if p == Weekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// upcomingLimit - сколько ближайших подъемов показывать в сводке
+const upcomingLimit = 5
+
+// Settings - расписание сводок, шаблон и состояние, которое должно переживать перезапуск
+type Settings struct {
+	Daily    string `json:"daily,omitempty"`    // время ежедневной сводки ЧЧ:ММ; пусто - выключена
+	Weekly   string `json:"weekly,omitempty"`   // день и время еженедельной сводки: "пн 10:00"
+	Template string `json:"template,omitempty"` // свой шаблон; пусто - DefaultTemplate
+
+	// SentAt - когда отправлена последняя сводка каждого периода
+	SentAt map[Period]time.Time `json:"sent_at,omitempty"`
+	// Stats - счетчики резюме на момент последней сводки периода: из них считается прирост
+	Stats map[Period]map[string]hh.ResumeStats `json:"stats,omitempty"`
+}
+
+// Clone возвращает копию настроек, не разделяющую с ними словари
+func (s Settings) Clone() Settings {
+	clone := s
+	clone.SentAt, clone.Stats = nil, nil
+	for period, at := range s.SentAt {
+		clone.MarkSent(period, at, s.Stats[period])
+	}
+	return clone
+}
+
+// Schedule возвращает расписание сводки периода
+func (s Settings) Schedule(period Period) string {
+	if period == Weekly {
+		return s.Weekly
+	}
+	return s.Daily
+}
+
+// Due возвращает время сводки периода, если она наступила и еще не отправлена.
+// После простоя отправляется одна сводка - за последнее наступившее время.
+func (s Settings) Due(period Period, now time.Time) (time.Time, bool) {
+	at, err := lastOccurrence(period, s.Schedule(period), now)
+	if err != nil || at.IsZero() {
+		return time.Time{}, false
+	}
+	return at, s.SentAt[period].Before(at)
+}
+
+// MarkSent запоминает отправку сводки и счетчики, от которых считать прирост в следующий раз;
+// без счетчиков остаются прежние
+func (s *Settings) MarkSent(period Period, at time.Time, stats map[string]hh.ResumeStats) {
+	if s.SentAt == nil {
+		s.SentAt = make(map[Period]time.Time)
+	}
+	s.SentAt[period] = at
+	if len(stats) == 0 {
+		return
+	}
+	if s.Stats == nil {
+		s.Stats = make(map[Period]map[string]hh.ResumeStats)
+	}
+	s.Stats[period] = make(map[string]hh.ResumeStats, len(stats))
+	for resumeID, resumeStats := range stats {
+		s.Stats[period][resumeID] = resumeStats
+	}
+}
+
+// lastOccurrence возвращает последнее наступившее время по расписанию сводки;
+// пустое расписание - сводка выключена
+func lastOccurrence(period Period, schedule string, now time.Time) (time.Time, error) {
+	if schedule == "" {
+		return time.Time{}, nil
+	}
+
+	if period == Daily {
+		minute, err := ParseDaily(schedule)
+		if err != nil {
+			return time.Time{}, err
+		}
+		at := dayStart(now).Add(time.Duration(minute) * time.Minute)
+		if at.After(now) {
+			at = at.AddDate(0, 0, -1)
+		}
+		return at, nil
+	}
+
+	weekday, minute, err := ParseWeekly(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+	daysBack := (int(now.Weekday()) - int(weekday) + 7) % 7
+	at := dayStart(now).AddDate(0, 0, -daysBack).Add(time.Duration(minute) * time.Minute)
+	if at.After(now) {
+		at = at.AddDate(0, 0, -7)
+	}
+	return at, nil
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// ParseDaily разбирает время ЧЧ:ММ (или ЧЧ) и возвращает минуты от полуночи
+func ParseDaily(text string) (int, error) {
+	hourText, minuteText, _ := strings.Cut(strings.TrimSpace(text), ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("неверное время «%s», пример: 09:00", text)
+	}
+	minute := 0
+	if minuteText != "" {
+		if minute, err = strconv.Atoi(minuteText); err != nil || minute < 0 || minute > 59 {
+			return 0, fmt.Errorf("неверное время «%s», пример: 09:00", text)
+		}
+	}
+	return hour*60 + minute, nil
+}
+
+// weekdayNames - сокращения дней недели в расписании еженедельной сводки
+var weekdayNames = []struct {
+	weekday time.Weekday
+	names   []string
+}{
+	{time.Monday, []string{"пн", "mon"}},
+	{time.Tuesday, []string{"вт", "tue"}},
+	{time.Wednesday, []string{"ср", "wed"}},
+	{time.Thursday, []string{"чт", "thu"}},
+	{time.Friday, []string{"пт", "fri"}},
+	{time.Saturday, []string{"сб", "sat"}},
+	{time.Sunday, []string{"вс", "sun"}},
+}
+
+// ParseWeekly разбирает день недели и время: "пн 10:00" или "mon 10:00"
+func ParseWeekly(text string) (time.Weekday, int, error) {
+	fields := strings.Fields(strings.ToLower(text))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("ожидается день недели и время, пример: пн 10:00")
+	}
+	minute, err := ParseDaily(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, day := range weekdayNames {
+		for _, name := range day.names {
+			if fields[0] == name {
+				return day.weekday, minute, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("непонятный день недели «%s», пример: пн 10:00", fields[0])
+}
+
+// FormatWeekly записывает расписание еженедельной сводки в единообразном виде
+func FormatWeekly(weekday time.Weekday, minute int) string {
+	for _, day := range weekdayNames {
+		if day.weekday == weekday {
+			return fmt.Sprintf("%s %02d:%02d", day.names[0], minute/60, minute%60)
+		}
+	}
+	return ""
+}
+
+// Source - данные для сводки
+type Source struct {
+	Schedules map[string]scheduler.ResumeSchedule
+	History   []scheduler.RaiseAttempt
+	Logins    int // автоматические входы за период
+	Paused    bool
+	// Stats - текущие счетчики по ID резюме, Previous - на момент прошлой сводки
+	Stats    map[string]hh.ResumeStats
+	Previous map[string]hh.ResumeStats
+}
+
+// Data - содержимое сводки, доступное шаблону
+type Data struct {
+	Period   Period
+	Title    string // "Ежедневная сводка" или "Еженедельная сводка"
+	From, To time.Time
+	Resumes  []Resume
+	Raised   int
+	Failed   int
+	Relogins int
+	Upcoming []Upcoming
+	Paused   bool // включен режим отпуска
+	// HasStats - hh.ru показал счетчики хотя бы одного резюме
+	HasStats bool
+}
+
+// Resume - итоги периода по одному резюме
+type Resume struct {
+	Title    string
+	Raised   int
+	Failed   int
+	LastRun  time.Time
+	NextRun  time.Time
+	Paused   bool
+	HasStats bool
+	// Views и Invitations - прирост с прошлой сводки (Gained) или, если ее не было, всего
+	Views       int
+	Invitations int
+	Gained      bool
+}
+
+// Upcoming - ближайший подъем
+type Upcoming struct {
+	Title string
+	At    time.Time
+}
+
+// Build собирает сводку за период, закончившийся в to
+func Build(period Period, to time.Time, src Source) Data {
+	data := Data{
+		Period: period,
+		Title:  "Ежедневная сводка",
+		From:   to.Add(-period.Duration()),
+		To:     to,
+		Paused: src.Paused,
+	}
+	if period == Weekly {
+		data.Title = "Еженедельная сводка"
+	}
+
+	byKey := make(map[string]*Resume)
+	resume := func(key string) *Resume {
+		if r, exists := byKey[key]; exists {
+			return r
+		}
+		r := &Resume{Title: key}
+		byKey[key] = r
+		return r
+	}
+
+	for key, schedule := range src.Schedules {
+		r := resume(key)
+		r.NextRun, r.Paused = schedule.NextRun, schedule.Paused
+		if stats, ok := src.Stats[schedule.ResumeID]; ok {
+			r.HasStats, data.HasStats = true, true
+			r.Views, r.Invitations = stats.Views, stats.Invitations
+			if previous, ok := src.Previous[schedule.ResumeID]; ok {
+				r.Views = nonNegative(stats.Views - previous.Views)
+				r.Invitations = nonNegative(stats.Invitations - previous.Invitations)
+				r.Gained = true
+			}
+		}
+		if !r.Paused && !src.Paused {
+			data.Upcoming = append(data.Upcoming, Upcoming{Title: key, At: schedule.NextRun})
+		}
+	}
+
+	for _, attempt := range src.History {
+		if attempt.Time.Before(data.From) || attempt.Time.After(to) {
+			continue
+		}
+		r := resume(scheduler.ScheduleKey(attempt.Account, attempt.Title))
+		if attempt.Succeeded() {
+			r.Raised++
+			data.Raised++
+		} else {
+			r.Failed++
+			data.Failed++
+		}
+		if attempt.Time.After(r.LastRun) {
+			r.LastRun = attempt.Time
+		}
+	}
+	data.Relogins = src.Logins
+
+	for _, r := range byKey {
+		data.Resumes = append(data.Resumes, *r)
+	}
+	sort.Slice(data.Resumes, func(i, j int) bool {
+		return data.Resumes[i].Title < data.Resumes[j].Title
+	})
+	sort.Slice(data.Upcoming, func(i, j int) bool {
+		return data.Upcoming[i].At.Before(data.Upcoming[j].At)
+	})
+	if len(data.Upcoming) > upcomingLimit {
+		data.Upcoming = data.Upcoming[:upcomingLimit]
+	}
+	return data
+}
+
+func nonNegative(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
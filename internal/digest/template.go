@@ -0,0 +1,80 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate - стандартный шаблон сводки (html/template, разметка HTML Telegram).
+// Доступные поля описаны в Data, функции - в templateFuncs.
+const DefaultTemplate = `📰 <b>{{.Title}}</b>
+{{date .From}} - {{date .To}}
+
+🚀 Поднято: <b>{{.Raised}}</b>, ошибок: <b>{{.Failed}}</b>
+🔐 Повторных входов: <b>{{.Relogins}}</b>
+{{- if .Paused}}
+🏖 Включен режим отпуска
+{{- end}}
+{{range .Resumes}}
+📄 <b>{{.Title}}</b>{{if .Paused}} ⏸{{end}}
+   ✅ {{.Raised}} · ❌ {{.Failed}}{{if not .LastRun.IsZero}} · последний {{datetime .LastRun}}{{end}}
+{{- if .HasStats}}
+   👀 {{if .Gained}}+{{end}}{{.Views}} · ✉️ {{if .Gained}}+{{end}}{{.Invitations}}
+{{- end}}
+{{- end}}
+{{- if .Upcoming}}
+
+🕐 <b>Ближайшие подъемы</b>
+{{- range .Upcoming}}
+   {{datetime .At}} {{.Title}}
+{{- end}}
+{{- end}}`
+
+var templateFuncs = template.FuncMap{
+	"date":     func(t time.Time) string { return t.Format("02.01") },
+	"datetime": func(t time.Time) string { return t.Format("02.01 15:04") },
+	"clock":    func(t time.Time) string { return t.Format("15:04") },
+}
+
+// Render заполняет шаблон сводки; пустой шаблон - DefaultTemplate
+func Render(text string, data Data) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("digest").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("ошибка в шаблоне: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("ошибка в шаблоне: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Validate проверяет шаблон на примере сводки
+func Validate(text string) error {
+	now := time.Now()
+	sample := Data{
+		Period: Daily,
+		Title:  "Ежедневная сводка",
+		From:   now.Add(-Daily.Duration()),
+		To:     now,
+		Resumes: []Resume{{
+			Title: "Go-разработчик", Raised: 6, Failed: 1, LastRun: now, NextRun: now.Add(4 * time.Hour),
+			HasStats: true, Views: 10, Invitations: 1, Gained: true,
+		}},
+		Raised:   6,
+		Failed:   1,
+		Relogins: 1,
+		Upcoming: []Upcoming{{Title: "Go-разработчик", At: now.Add(4 * time.Hour)}},
+		HasStats: true,
+	}
+	_, err := Render(text, sample)
+	return err
+}
@@ -0,0 +1,164 @@
+package hh
+
+import (
+	"sync"
+	"time"
+)
+
+// ResumeCache кэширует список резюме аккаунта, а вместе с ним и статус сессии:
+// список загружается только с действующей сессией. Интерфейс бота читает кэш
+// вместо запроса к hh.ru на каждом экране.
+type ResumeCache struct {
+	client   *Client
+	ttl      time.Duration
+	errorTTL time.Duration
+
+	resumes   []Resume
+	err       error
+	fetchedAt time.Time
+	usedAt    time.Time
+	// version увеличивается при Invalidate, чтобы ответ, полученный до сброса, не попал в кэш
+	version uint64
+	mutex   sync.Mutex
+
+	// fetchMutex не дает параллельным вызовам запрашивать hh.ru одновременно
+	fetchMutex sync.Mutex
+	stop       chan struct{}
+}
+
+// NewResumeCache создает кэш: успешный ответ хранится ttl, ошибка - errorTTL
+func NewResumeCache(client *Client, ttl, errorTTL time.Duration) *ResumeCache {
+	return &ResumeCache{
+		client:   client,
+		ttl:      ttl,
+		errorTTL: errorTTL,
+	}
+}
+
+// Resumes возвращает список резюме из кэша или, если он устарел, загружает его с hh.ru
+func (c *ResumeCache) Resumes() ([]Resume, error) {
+	c.mutex.Lock()
+	c.usedAt = time.Now()
+	if c.fresh() {
+		resumes, err := c.cached()
+		c.mutex.Unlock()
+		return resumes, err
+	}
+	c.mutex.Unlock()
+
+	return c.fetch(false)
+}
+
+// Authorized сообщает, действует ли сессия аккаунта
+func (c *ResumeCache) Authorized() bool {
+	_, err := c.Resumes()
+	return err == nil
+}
+
+// Refresh загружает список резюме с hh.ru независимо от возраста кэша
+func (c *ResumeCache) Refresh() ([]Resume, error) {
+	c.mutex.Lock()
+	c.usedAt = time.Now()
+	c.mutex.Unlock()
+
+	return c.fetch(true)
+}
+
+// Invalidate сбрасывает кэш; следующее обращение загрузит список заново.
+// Вызывается после входа в аккаунт и подъема резюме.
+func (c *ResumeCache) Invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.version++
+	c.resumes, c.err, c.fetchedAt = nil, nil, time.Time{}
+}
+
+// StartRefresher раз в interval обновляет кэш, который скоро устареет, чтобы экраны бота
+// открывались без ожидания hh.ru. Кэш, к которому не обращались дольше idle, не обновляется.
+func (c *ResumeCache) StartRefresher(interval, idle time.Duration) {
+	c.mutex.Lock()
+	if c.stop != nil {
+		c.mutex.Unlock()
+		return
+	}
+	c.stop = make(chan struct{})
+	stop := c.stop
+	c.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if c.needsRefresh(interval, idle) {
+					c.fetch(true)
+				}
+			}
+		}
+	}()
+}
+
+// Stop останавливает фоновое обновление
+func (c *ResumeCache) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+func (c *ResumeCache) needsRefresh(interval, idle time.Duration) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.usedAt.IsZero() || time.Since(c.usedAt) > idle {
+		return false
+	}
+	// Обновляем заранее: до следующего тика кэш не должен устареть
+	return c.fetchedAt.IsZero() || time.Since(c.fetchedAt)+interval >= c.lifetime()
+}
+
+func (c *ResumeCache) fetch(force bool) ([]Resume, error) {
+	c.fetchMutex.Lock()
+	defer c.fetchMutex.Unlock()
+
+	// Пока ждали, кэш мог обновить другой вызов
+	c.mutex.Lock()
+	if !force && c.fresh() {
+		resumes, err := c.cached()
+		c.mutex.Unlock()
+		return resumes, err
+	}
+	version := c.version
+	c.mutex.Unlock()
+
+	resumes, err := c.client.GetResumes()
+
+	c.mutex.Lock()
+	if c.version == version {
+		c.resumes, c.err, c.fetchedAt = resumes, err, time.Now()
+	}
+	c.mutex.Unlock()
+
+	return append([]Resume(nil), resumes...), err
+}
+
+func (c *ResumeCache) fresh() bool {
+	return !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < c.lifetime()
+}
+
+func (c *ResumeCache) lifetime() time.Duration {
+	if c.err != nil {
+		return c.errorTTL
+	}
+	return c.ttl
+}
+
+func (c *ResumeCache) cached() ([]Resume, error) {
+	return append([]Resume(nil), c.resumes...), c.err
+}
@@ -3,6 +3,7 @@ package hh
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,21 +11,53 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// loginReuseWindow - вход, завершившийся так недавно, не повторяется: вызывающие,
+// получившие отказ до него, пользуются уже обновленными токенами
+const loginReuseWindow = 30 * time.Second
+
+// ErrSessionExpired - hh.ru не принял токены, нужен повторный вход
+var ErrSessionExpired = errors.New("session expired")
+
 type Client struct {
 	Username  string
 	Password  string
 	UserAgent string
 	xsrf      string
 	hhtoken   string
-	client    *http.Client
+	// loggedInAt - время входа, которым получены текущие токены (нулевое, если неизвестно)
+	loggedInAt time.Time
+	client     *http.Client
+
+	// mutex защищает токены: клиентом одновременно пользуются планировщик и бот
+	mutex sync.RWMutex
+	// login - выполняющийся вход; параллельные вызовы Login ждут его результата
+	login *loginCall
+	// lastLogin - когда этот процесс последний раз успешно вошел
+	lastLogin time.Time
+}
+
+// loginCall - один вход, результат которого получают все ожидающие
+type loginCall struct {
+	done chan struct{}
+	err  error
 }
 
 type Resume struct {
 	ID    string
 	Title string
+	Stats *ResumeStats // nil, если hh.ru не показал счетчики на странице резюме
+}
+
+// ResumeStats - счетчики резюме за все время
+type ResumeStats struct {
+	Views       int `json:"views"`
+	Invitations int `json:"invitations"`
 }
 
 func NewClient(login, password, proxy string) (*Client, error) {
@@ -48,7 +81,7 @@ func NewClient(login, password, proxy string) (*Client, error) {
 	return client, nil
 }
 
-func (c *Client) getCookieAnonymous() error {
+func (c *Client) getCookieAnonymous() (string, string, error) {
 	log.Println("Making HEAD request to hh.ru to get anonymous cookies...")
 	req, _ := http.NewRequest("HEAD", "https://hh.ru/", nil)
 	req.Header.Set("User-Agent", c.UserAgent)
@@ -56,7 +89,7 @@ func (c *Client) getCookieAnonymous() error {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		log.Printf("Error making request to hh.ru: %v", err)
-		return fmt.Errorf("request failed: %w", err)
+		return "", "", fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -67,26 +100,27 @@ func (c *Client) getCookieAnonymous() error {
 	log.Printf("Response headers string: %s", headersStr)
 
 	// Ищем токены используя точно такие же regex как в Python
+	var xsrf, hhtoken string
 	xsrfRegex := regexp.MustCompile(`_xsrf=([^;]+);`)
 	if xsrfMatch := xsrfRegex.FindStringSubmatch(headersStr); len(xsrfMatch) > 1 {
-		c.xsrf = xsrfMatch[1]
-		log.Printf("Found XSRF token: %s", c.xsrf[:min(8, len(c.xsrf))]+"...")
+		xsrf = xsrfMatch[1]
+		log.Printf("Found XSRF token: %s", xsrf[:min(8, len(xsrf))]+"...")
 	} else {
 		log.Printf("XSRF token not found in headers")
-		return fmt.Errorf("XSRF token not found")
+		return "", "", fmt.Errorf("XSRF token not found")
 	}
 
 	hhtokenRegex := regexp.MustCompile(`hhtoken=([^;]+);`)
 	if hhtokenMatch := hhtokenRegex.FindStringSubmatch(headersStr); len(hhtokenMatch) > 1 {
-		c.hhtoken = hhtokenMatch[1]
-		log.Printf("Found HH token: %s", c.hhtoken[:min(8, len(c.hhtoken))]+"...")
+		hhtoken = hhtokenMatch[1]
+		log.Printf("Found HH token: %s", hhtoken[:min(8, len(hhtoken))]+"...")
 	} else {
 		log.Printf("HH token not found in headers")
-		return fmt.Errorf("HH token not found")
+		return "", "", fmt.Errorf("HH token not found")
 	}
 
 	log.Printf("Successfully extracted both tokens from anonymous request")
-	return nil
+	return xsrf, hhtoken, nil
 }
 
 func min(a, b int) int {
@@ -96,9 +130,39 @@ func min(a, b int) int {
 	return b
 }
 
+// Login входит в аккаунт. Параллельные вызовы не создают новых входов: они ждут
+// уже начатый и получают его результат, а только что успешно завершенный вход не повторяется.
 func (c *Client) Login() error {
+	c.mutex.Lock()
+	if call := c.login; call != nil {
+		c.mutex.Unlock()
+		log.Printf("Login for user %s is already in progress, waiting for it", c.Username)
+		<-call.done
+		return call.err
+	}
+	if !c.lastLogin.IsZero() && time.Since(c.lastLogin) < loginReuseWindow {
+		c.mutex.Unlock()
+		log.Printf("User %s has just logged in, reusing the session", c.Username)
+		return nil
+	}
+	call := &loginCall{done: make(chan struct{})}
+	c.login = call
+	c.mutex.Unlock()
+
+	call.err = c.doLogin()
+
+	c.mutex.Lock()
+	c.login = nil
+	c.mutex.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// doLogin выполняет вход; токены клиента меняются только при успехе
+func (c *Client) doLogin() error {
 	// Получаем анонимные куки точно как в Python
-	if err := c.getCookieAnonymous(); err != nil {
+	xsrf, hhtoken, err := c.getCookieAnonymous()
+	if err != nil {
 		return err
 	}
 
@@ -111,7 +175,7 @@ func (c *Client) Login() error {
 	writer.SetBoundary(boundary)
 
 	// Формируем данные точно как в Python
-	_ = writer.WriteField("_xsrf", c.xsrf)
+	_ = writer.WriteField("_xsrf", xsrf)
 	_ = writer.WriteField("backUrl", "https://hh.ru/")
 	_ = writer.WriteField("failUrl", "/account/login")
 	_ = writer.WriteField("remember", "yes")
@@ -127,9 +191,9 @@ func (c *Client) Login() error {
 	
 	req, _ := http.NewRequest("POST", "https://hh.ru/account/login", &buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", c.xsrf, c.hhtoken))
+	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", xsrf, hhtoken))
 	req.Header.Set("User-Agent", c.UserAgent)
-	req.Header.Set("X-Xsrftoken", c.xsrf)
+	req.Header.Set("X-Xsrftoken", xsrf)
 
 	// Логируем все заголовки запроса
 	log.Println("=== LOGIN REQUEST HEADERS ===")
@@ -160,13 +224,17 @@ func (c *Client) Login() error {
 
 	// Обновляем XSRF токен
 	if xsrfMatch := regexp.MustCompile(`_xsrf=([^;]+)`).FindStringSubmatch(cookiesStr); len(xsrfMatch) > 1 {
-		c.xsrf = xsrfMatch[1]
+		xsrf = xsrfMatch[1]
 		log.Printf("Updated XSRF token from login response")
 	}
 
 	// Проверяем наличие hhtoken для подтверждения успешной авторизации
 	if hhtokenMatch := regexp.MustCompile(`hhtoken=([^;]+)`).FindStringSubmatch(cookiesStr); len(hhtokenMatch) > 1 {
-		c.hhtoken = hhtokenMatch[1]
+		now := time.Now()
+		c.mutex.Lock()
+		c.xsrf, c.hhtoken = xsrf, hhtokenMatch[1]
+		c.loggedInAt, c.lastLogin = now, now
+		c.mutex.Unlock()
 		log.Printf("Got HH token from login response")
 		return nil
 	}
@@ -176,8 +244,9 @@ func (c *Client) Login() error {
 }
 
 func (c *Client) GetResumes() ([]Resume, error) {
+	xsrf, hhtoken := c.GetTokens()
 	req, _ := http.NewRequest("GET", "https://hh.ru/applicant/resumes", nil)
-	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", c.xsrf, c.hhtoken))
+	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", xsrf, hhtoken))
 	req.Header.Set("User-Agent", c.UserAgent)
 
 	resp, err := c.client.Do(req)
@@ -186,6 +255,11 @@ func (c *Client) GetResumes() ([]Resume, error) {
 	}
 	defer resp.Body.Close()
 
+	// С истекшей сессией hh.ru отказывает или перенаправляет на страницу входа
+	if resp.StatusCode == 401 || resp.StatusCode == 403 || strings.HasPrefix(resp.Request.URL.Path, "/account/login") {
+		return nil, fmt.Errorf("%w (status code: %d)", ErrSessionExpired, resp.StatusCode)
+	}
+
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -200,22 +274,49 @@ func (c *Client) GetResumes() ([]Resume, error) {
 	// Парсим резюме из HTML используя regex как в Python версии
 	// Ищем элементы с data-qa="resume" и извлекаем название и ID
 	resumePattern := regexp.MustCompile(`<div[^>]*data-qa="resume"[^>]*data-qa-title="([^"]+)"[^>]*>[\s\S]*?<a[^>]*href="[^"]*resume/([a-f0-9]+)`)
-	matches := resumePattern.FindAllStringSubmatch(content, -1)
+	matches := resumePattern.FindAllStringSubmatchIndex(content, -1)
 
 	var resumes []Resume
-	for _, match := range matches {
-		if len(match) > 2 {
-			resumes = append(resumes, Resume{
-				Title: match[1],
-				ID:    match[2],
-			})
+	for i, match := range matches {
+		// Счетчики ищем в разметке резюме - до начала следующего
+		end := len(content)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
 		}
+		resumes = append(resumes, Resume{
+			Title: content[match[2]:match[3]],
+			ID:    content[match[4]:match[5]],
+			Stats: parseResumeStats(content[match[0]:end]),
+		})
 	}
 
 	log.Printf("Found %d resumes", len(resumes))
 	return resumes, nil
 }
 
+var (
+	viewsPattern       = regexp.MustCompile(`(\d+)(?:\s|&nbsp;|\x{00a0}|<[^>]*>)*(?:новых\s+)?просмотр`)
+	invitationsPattern = regexp.MustCompile(`(\d+)(?:\s|&nbsp;|\x{00a0}|<[^>]*>)*(?:новых\s+)?приглашени`)
+)
+
+// parseResumeStats ищет счетчики просмотров и приглашений в разметке резюме
+func parseResumeStats(block string) *ResumeStats {
+	views := viewsPattern.FindStringSubmatch(block)
+	invitations := invitationsPattern.FindStringSubmatch(block)
+	if views == nil && invitations == nil {
+		return nil
+	}
+
+	var stats ResumeStats
+	if views != nil {
+		stats.Views, _ = strconv.Atoi(views[1])
+	}
+	if invitations != nil {
+		stats.Invitations, _ = strconv.Atoi(invitations[1])
+	}
+	return &stats
+}
+
 func (c *Client) RaiseResume(resumeID string) (int, error) {
 	// Используем тот же подход что и в Python - multipart.Writer с кастомным boundary
 	var buf bytes.Buffer
@@ -233,11 +334,12 @@ func (c *Client) RaiseResume(resumeID string) (int, error) {
 	log.Printf("Raising resume with ID: %s", resumeID)
 	log.Printf("POST data length: %d bytes", buf.Len())
 
+	xsrf, hhtoken := c.GetTokens()
 	req, _ := http.NewRequest("POST", "https://hh.ru/applicant/resumes/touch", &buf)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", c.xsrf, c.hhtoken))
+	req.Header.Set("Cookie", fmt.Sprintf("_xsrf=%s; hhtoken=%s;", xsrf, hhtoken))
 	req.Header.Set("User-Agent", c.UserAgent)
-	req.Header.Set("X-Xsrftoken", c.xsrf)
+	req.Header.Set("X-Xsrftoken", xsrf)
 
 	// Логируем заголовки для отладки
 	log.Println("=== RAISE RESUME HEADERS ===")
@@ -271,10 +373,28 @@ func (c *Client) RaiseResume(resumeID string) (int, error) {
 }
 
 func (c *Client) GetTokens() (string, string) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
 	return c.xsrf, c.hhtoken
 }
 
 func (c *Client) SetTokens(xsrf, hhtoken string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	c.xsrf = xsrf
 	c.hhtoken = hhtoken
 }
+
+// LoginTime возвращает время входа, которым получены текущие токены
+func (c *Client) LoginTime() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.loggedInAt
+}
+
+// SetLoginTime восстанавливает время входа для сохраненных токенов
+func (c *Client) SetLoginTime(loggedInAt time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.loggedInAt = loggedInAt
+}
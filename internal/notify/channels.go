@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpClient - клиент для всех HTTP-каналов; таймаут не дает зависшему сервису копить очередь
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// SMTP отправляет события письмом
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string // пустой логин - без авторизации
+	Password string
+	From     string
+	To       []string
+}
+
+func (s *SMTP) Notify(event Event) error {
+	message := Render(event)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", message.Title))
+	fmt.Fprintf(&body, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	// Строки base64 в письме не длиннее 76 символов
+	encoded := base64.StdEncoding.EncodeToString([]byte(message.Text(event)))
+	for len(encoded) > 76 {
+		body.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	body.WriteString(encoded + "\r\n")
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	address := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	return smtp.SendMail(address, auth, s.From, s.To, body.Bytes())
+}
+
+// Slack отправляет событие во входящий вебхук Slack (или совместимого сервиса: Mattermost, Rocket.Chat)
+type Slack struct {
+	URL string
+}
+
+func (s *Slack) Notify(event Event) error {
+	message := Render(event)
+	text := "*" + message.Title + "*"
+	if len(message.Lines) > 0 {
+		text += "\n" + strings.Join(message.Lines, "\n")
+	}
+	return postJSON(s.URL, map[string]string{"text": text}, nil)
+}
+
+// Ntfy отправляет push-уведомление через ntfy; URL - адрес темы, например https://ntfy.sh/my-topic
+type Ntfy struct {
+	URL   string
+	Token string // пустой токен - тема без авторизации
+}
+
+func (n *Ntfy) Notify(event Event) error {
+	message := Render(event)
+	req, err := http.NewRequest(http.MethodPost, n.URL, strings.NewReader(strings.Join(message.Lines, "\n")))
+	if err != nil {
+		return err
+	}
+	// Заголовки HTTP - только ASCII, русский заголовок кодируем по RFC 2047
+	req.Header.Set("Title", mime.BEncoding.Encode("UTF-8", message.Title))
+	req.Header.Set("Priority", strconv.Itoa(3+int(event.Severity)))
+	req.Header.Set("Tags", string(event.Type))
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return send(req)
+}
+
+// Gotify отправляет push-уведомление на сервер Gotify; URL - адрес сервера
+type Gotify struct {
+	URL   string
+	Token string // токен приложения
+}
+
+func (g *Gotify) Notify(event Event) error {
+	message := Render(event)
+	payload := map[string]interface{}{
+		"title":    message.Title,
+		"message":  strings.Join(message.Lines, "\n"),
+		"priority": 2 + 3*int(event.Severity),
+	}
+	return postJSON(strings.TrimRight(g.URL, "/")+"/message", payload, map[string]string{"X-Gotify-Key": g.Token})
+}
+
+func postJSON(url string, payload interface{}, headers map[string]string) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return send(req)
+}
+
+func send(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
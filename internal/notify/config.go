@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"log"
+
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+// FromConfig создает рассылку с Telegram и каналами, включенными в конфигурации.
+// store хранит очередь и историю доставки вебхуков.
+func FromConfig(cfg config.NotifyConfig, telegram Notifier, store DeliveryStore) *Router {
+	router := NewRouter()
+	router.Add("telegram", telegram, filterFromConfig("telegram", cfg.Telegram))
+
+	if cfg.SMTP.Host != "" {
+		router.Add("smtp", &SMTP{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+			To:       cfg.SMTP.To,
+		}, filterFromConfig("smtp", cfg.SMTP.Filter))
+	}
+	if cfg.Webhook.URL != "" {
+		webhook := NewWebhook(cfg.Webhook.URL, cfg.Webhook.Secret, store)
+		router.Add("webhook", webhook, filterFromConfig("webhook", cfg.Webhook.Filter))
+	}
+	if cfg.Slack.URL != "" {
+		router.Add("slack", &Slack{URL: cfg.Slack.URL}, filterFromConfig("slack", cfg.Slack.Filter))
+	}
+	if cfg.Ntfy.URL != "" {
+		router.Add("ntfy", &Ntfy{URL: cfg.Ntfy.URL, Token: cfg.Ntfy.Token}, filterFromConfig("ntfy", cfg.Ntfy.Filter))
+	}
+	if cfg.Gotify.URL != "" {
+		router.Add("gotify", &Gotify{URL: cfg.Gotify.URL, Token: cfg.Gotify.Token}, filterFromConfig("gotify", cfg.Gotify.Filter))
+	}
+
+	return router
+}
+
+func filterFromConfig(channel string, cfg config.NotifyFilter) Filter {
+	severity, ok := ParseSeverity(cfg.Level)
+	if !ok {
+		log.Printf("Unknown notification level %q for channel %s, using info", cfg.Level, channel)
+	}
+
+	filter := Filter{MinSeverity: severity}
+	for _, eventType := range cfg.Events {
+		filter.Types = append(filter.Types, EventType(eventType))
+	}
+	return filter
+}
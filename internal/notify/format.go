@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Message - текст события для человека: заголовок и строки
+type Message struct {
+	Title string
+	Lines []string
+}
+
+// Render описывает событие по-русски
+func Render(event Event) Message {
+	switch event.Type {
+	case EventRaiseSucceeded:
+		lines := []string{StatusText(event.Status)}
+		if event.Attempt > 1 {
+			lines = append(lines, fmt.Sprintf("🔁 Попытка %d/%d", event.Attempt, event.MaxAttempts))
+		}
+		return Message{Title: "📄 " + event.Resume, Lines: lines}
+	case EventRaiseRetry:
+		return Message{Title: "📄 " + event.Resume, Lines: []string{
+			raiseResultText(event),
+			fmt.Sprintf("🔁 Попытка %d/%d, повтор в %s", event.Attempt, event.MaxAttempts, event.RetryAt.Format("15:04")),
+		}}
+	case EventRaiseFailed:
+		return Message{Title: "🚨 " + event.Resume, Lines: []string{
+			fmt.Sprintf("Не удалось поднять резюме за %d попыток", event.MaxAttempts),
+			raiseResultText(event),
+			"📅 Следующий плановый подъем: " + event.NextRun.Format("02.01 15:04"),
+		}}
+	case EventOneOffRaised:
+		return Message{Title: "🎯 " + event.Resume, Lines: []string{
+			fmt.Sprintf("Разовый подъем (%s)", event.ScheduledAt.Format("02.01 15:04")),
+			raiseResultText(event),
+		}}
+	case EventResumeStatusChanged:
+		return Message{Title: "🔀 " + event.Resume, Lines: []string{
+			"Изменился ответ hh.ru на подъем",
+			"Было: " + StatusText(event.PreviousStatus),
+			"Стало: " + StatusText(event.Status),
+		}}
+	case EventVacationEnded:
+		return Message{Title: "▶️ Режим отпуска завершен", Lines: []string{"Автоподъем резюме возобновлен"}}
+	case EventResumePauseEnded:
+		return Message{Title: "▶️ " + event.Resume, Lines: []string{"Пауза завершена, автоподъем возобновлен"}}
+	case EventSessionExpired:
+		return Message{Title: "⌛ Сессия hh.ru истекла", Lines: []string{
+			"Аккаунт: " + AccountLabel(event.Account),
+			"Причина: " + event.Error,
+			"Выполняется повторный вход",
+		}}
+	case EventLoginFailed:
+		return Message{Title: "🔐 Не удалось войти в hh.ru", Lines: []string{
+			"Аккаунт: " + AccountLabel(event.Account),
+			"Причина: " + event.Error,
+			"Автоподъем не сработает, пока вход не восстановится",
+		}}
+	case EventLoginRestored:
+		return Message{Title: "✅ Вход в hh.ru восстановлен", Lines: []string{"Аккаунт: " + AccountLabel(event.Account)}}
+	}
+
+	message := Message{Title: string(event.Type)}
+	if event.Resume != "" {
+		message.Lines = append(message.Lines, event.Resume)
+	}
+	if event.Error != "" {
+		message.Lines = append(message.Lines, event.Error)
+	}
+	return message
+}
+
+// Text - текст сообщения без разметки со временем события
+func (m Message) Text(event Event) string {
+	lines := append([]string{m.Title}, m.Lines...)
+	lines = append(lines, "🕐 "+event.Time.Format("02.01 15:04:05"))
+	return strings.Join(lines, "\n")
+}
+
+// HTML - текст сообщения для Telegram со временем события
+func (m Message) HTML(event Event) string {
+	text := "<b>" + html.EscapeString(m.Title) + "</b>"
+	for _, line := range m.Lines {
+		text += "\n" + html.EscapeString(line)
+	}
+	return text + "\n🕐 " + event.Time.Format("15:04:05")
+}
+
+// raiseResultText описывает результат попытки подъема: ошибку или ответ hh.ru
+func raiseResultText(event Event) string {
+	if event.Error != "" {
+		return "❌ Ошибка: " + event.Error
+	}
+	return StatusText(event.Status)
+}
+
+// StatusText возвращает описание кода ответа hh.ru на подъем резюме
+func StatusText(code int) string {
+	switch code {
+	case 200:
+		return "✅ Резюме успешно поднято"
+	case 409:
+		return "⏳ Резюме уже поднималось недавно"
+	case 403:
+		return "❌ Доступ запрещен"
+	case 429:
+		return "⏸️ Слишком много запросов"
+	default:
+		return fmt.Sprintf("❌ Ошибка: %d", code)
+	}
+}
+
+// EventTypes - типы событий с отдельной настройкой уровня, в порядке показа
+var EventTypes = []EventType{
+	EventRaiseSucceeded,
+	EventRaiseRetry,
+	EventRaiseFailed,
+	EventOneOffRaised,
+	EventResumeStatusChanged,
+	EventType("pause"),
+	EventSessionExpired,
+	EventLoginFailed,
+	EventLoginRestored,
+}
+
+// EventTypeName - название типа события или категории для интерфейса
+func EventTypeName(eventType EventType) string {
+	switch eventType {
+	case EventRaiseSucceeded:
+		return "Резюме поднято"
+	case EventRaiseRetry:
+		return "Повтор подъема"
+	case EventRaiseFailed:
+		return "Подъем не удался"
+	case EventOneOffRaised:
+		return "Разовый подъем"
+	case EventResumeStatusChanged:
+		return "Изменился ответ hh.ru"
+	case "pause":
+		return "Конец паузы и отпуска"
+	case EventSessionExpired:
+		return "Сессия истекла"
+	case EventLoginFailed:
+		return "Ошибка входа"
+	case EventLoginRestored:
+		return "Вход восстановлен"
+	}
+	return string(eventType)
+}
+
+// LevelName - название уровня уведомлений; пустой уровень - настройка не задана
+func LevelName(level Level) string {
+	switch level {
+	case LevelAll:
+		return "все"
+	case LevelErrors:
+		return "только ошибки"
+	case LevelNone:
+		return "выключены"
+	}
+	return "по умолчанию"
+}
+
+// AccountLabel - имя аккаунта для сообщений; у аккаунта по умолчанию имя пустое
+func AccountLabel(account string) string {
+	if account == "" {
+		return "основной"
+	}
+	return account
+}
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// EventType - тип события. Типы сгруппированы по категориям: raise.*, resume.*, pause.*, session.*
+type EventType string
+
+const (
+	EventRaiseSucceeded EventType = "raise.succeeded" // резюме поднято или уже было поднято недавно
+	EventRaiseRetry     EventType = "raise.retry"     // подъем не удался, назначен повтор
+	EventRaiseFailed    EventType = "raise.failed"    // попытки подъема исчерпаны
+	EventOneOffRaised   EventType = "raise.oneoff"    // выполнен разовый подъем
+
+	EventResumeStatusChanged EventType = "resume.status_changed" // ответ hh.ru на подъем изменился
+	EventVacationEnded       EventType = "pause.vacation_end"    // закончился режим отпуска
+	EventResumePauseEnded    EventType = "pause.resume_end"      // закончилась пауза резюме
+	EventSessionExpired      EventType = "session.expired"       // hh.ru отклонил запрос, выполняется повторный вход
+	EventLoginFailed         EventType = "session.login_failed"
+	EventLoginRestored       EventType = "session.restored"
+)
+
+// Severity - важность события
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// ParseSeverity разбирает важность: info, warning или error
+func ParseSeverity(name string) (Severity, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "info", "":
+		return SeverityInfo, true
+	case "warning", "warn":
+		return SeverityWarning, true
+	case "error":
+		return SeverityError, true
+	}
+	return SeverityInfo, false
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "info"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, ok := ParseSeverity(string(text))
+	if !ok {
+		return fmt.Errorf("unknown severity %q", text)
+	}
+	*s = severity
+	return nil
+}
+
+// Event - событие планировщика или монитора. Поля, не относящиеся к типу события, пустые.
+type Event struct {
+	Type           EventType
+	Severity       Severity
+	Time           time.Time
+	Account        string // пустое значение - аккаунт по умолчанию
+	Resume         string // ключ расписания резюме
+	ResumeID       string
+	Status         int    // код ответа hh.ru на подъем
+	PreviousStatus int    // предыдущий код ответа (для resume.status_changed)
+	Error          string // текст ошибки подъема или входа
+	Attempt        int
+	MaxAttempts    int
+	RetryAt        time.Time
+	NextRun        time.Time
+	ScheduledAt    time.Time // время разового подъема
+}
+
+// eventJSON - представление события для внешних систем без пустых полей
+type eventJSON struct {
+	Type           EventType  `json:"type"`
+	Severity       Severity   `json:"severity"`
+	Time           time.Time  `json:"time"`
+	Account        string     `json:"account,omitempty"`
+	Resume         string     `json:"resume,omitempty"`
+	ResumeID       string     `json:"resume_id,omitempty"`
+	Status         int        `json:"status,omitempty"`
+	PreviousStatus int        `json:"previous_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	Attempt        int        `json:"attempt,omitempty"`
+	MaxAttempts    int        `json:"max_attempts,omitempty"`
+	RetryAt        *time.Time `json:"retry_at,omitempty"`
+	NextRun        *time.Time `json:"next_run,omitempty"`
+	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"`
+}
+
+func (e Event) MarshalJSON() ([]byte, error) {
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+
+	return json.Marshal(eventJSON{
+		Type:           e.Type,
+		Severity:       e.Severity,
+		Time:           e.Time,
+		Account:        e.Account,
+		Resume:         e.Resume,
+		ResumeID:       e.ResumeID,
+		Status:         e.Status,
+		PreviousStatus: e.PreviousStatus,
+		Error:          e.Error,
+		Attempt:        e.Attempt,
+		MaxAttempts:    e.MaxAttempts,
+		RetryAt:        optional(e.RetryAt),
+		NextRun:        optional(e.NextRun),
+		ScheduledAt:    optional(e.ScheduledAt),
+	})
+}
+
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var decoded eventJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	value := func(t *time.Time) time.Time {
+		if t == nil {
+			return time.Time{}
+		}
+		return *t
+	}
+
+	*e = Event{
+		Type:           decoded.Type,
+		Severity:       decoded.Severity,
+		Time:           decoded.Time,
+		Account:        decoded.Account,
+		Resume:         decoded.Resume,
+		ResumeID:       decoded.ResumeID,
+		Status:         decoded.Status,
+		PreviousStatus: decoded.PreviousStatus,
+		Error:          decoded.Error,
+		Attempt:        decoded.Attempt,
+		MaxAttempts:    decoded.MaxAttempts,
+		RetryAt:        value(decoded.RetryAt),
+		NextRun:        value(decoded.NextRun),
+		ScheduledAt:    value(decoded.ScheduledAt),
+	}
+	return nil
+}
+
+// Notifier доставляет событие в один канал
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// Filter отбирает события для канала
+type Filter struct {
+	// Types - типы событий или их категории ("raise" - все raise.*); пустой список - все события
+	Types       []EventType
+	MinSeverity Severity
+}
+
+// Allows сообщает, нужно ли отправить событие в канал
+func (f Filter) Allows(event Event) bool {
+	if event.Severity < f.MinSeverity {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, eventType := range f.Types {
+		if event.Type == eventType || strings.HasPrefix(string(event.Type), string(eventType)+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// channelQueueSize - сколько событий канал держит в очереди, пока доставляет предыдущие
+const channelQueueSize = 100
+
+type channel struct {
+	name     string
+	notifier Notifier
+	filter   Filter
+	queue    chan Event
+}
+
+// Router рассылает события по каналам. У каждого канала своя очередь:
+// медленный канал не задерживает остальные и публикацию события.
+type Router struct {
+	channels []*channel
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Add подключает канал. Каналы подключаются до первой публикации.
+func (r *Router) Add(name string, notifier Notifier, filter Filter) {
+	ch := &channel{
+		name:     name,
+		notifier: notifier,
+		filter:   filter,
+		queue:    make(chan Event, channelQueueSize),
+	}
+	r.channels = append(r.channels, ch)
+	go ch.run()
+	log.Printf("Notification channel %s enabled", name)
+}
+
+// Publish передает событие всем каналам, фильтр которых его пропускает
+func (r *Router) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for _, ch := range r.channels {
+		if !ch.filter.Allows(event) {
+			continue
+		}
+		select {
+		case ch.queue <- event:
+		default:
+			log.Printf("Notification channel %s is overloaded, dropping %s event", ch.name, event.Type)
+		}
+	}
+}
+
+func (ch *channel) run() {
+	for event := range ch.queue {
+		if err := ch.notifier.Notify(event); err != nil {
+			log.Printf("Failed to send %s event via %s: %v", event.Type, ch.name, err)
+		}
+	}
+}
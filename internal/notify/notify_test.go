@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"hh-ru-auto-resume-raising/pkg/config"
+)
+
+func TestFilterAllows(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		event  Event
+		want   bool
+	}{
+		{"empty filter allows all", Filter{}, Event{Type: EventRaiseSucceeded}, true},
+		{"exact type", Filter{Types: []EventType{EventRaiseFailed}}, Event{Type: EventRaiseFailed}, true},
+		{"other type", Filter{Types: []EventType{EventRaiseFailed}}, Event{Type: EventRaiseRetry}, false},
+		{"category matches its types", Filter{Types: []EventType{"session"}}, Event{Type: EventLoginFailed}, true},
+		{"category does not match other categories", Filter{Types: []EventType{"raise"}}, Event{Type: EventVacationEnded}, false},
+		{"category prefix needs a dot", Filter{Types: []EventType{"rai"}}, Event{Type: EventRaiseFailed}, false},
+		{"type does not match category", Filter{Types: []EventType{EventRaiseFailed}}, Event{Type: "raise"}, false},
+		{"any of several types", Filter{Types: []EventType{"pause", EventRaiseFailed}}, Event{Type: EventRaiseFailed}, true},
+		{"severity below minimum", Filter{MinSeverity: SeverityWarning}, Event{Type: EventRaiseRetry, Severity: SeverityInfo}, false},
+		{"severity at minimum", Filter{MinSeverity: SeverityWarning}, Event{Type: EventRaiseRetry, Severity: SeverityWarning}, true},
+		{"severity above minimum", Filter{MinSeverity: SeverityWarning}, Event{Type: EventRaiseFailed, Severity: SeverityError}, true},
+		{"type matches but severity too low",
+			Filter{Types: []EventType{"raise"}, MinSeverity: SeverityError},
+			Event{Type: EventRaiseRetry, Severity: SeverityWarning}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(tt.event); got != tt.want {
+				t.Errorf("Allows(%s, %s) = %v, want %v", tt.event.Type, tt.event.Severity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterFromConfig(t *testing.T) {
+	filter := filterFromConfig("test", config.NotifyFilter{Events: []string{"raise", "session.restored"}, Level: "warning"})
+
+	if filter.MinSeverity != SeverityWarning {
+		t.Errorf("min severity = %s, want warning", filter.MinSeverity)
+	}
+	if !filter.Allows(Event{Type: EventRaiseFailed, Severity: SeverityError}) {
+		t.Error("filter rejects raise.failed")
+	}
+	if filter.Allows(Event{Type: EventLoginFailed, Severity: SeverityError}) {
+		t.Error("filter allows session.login_failed")
+	}
+
+	// Неизвестный уровень заменяется на info
+	if filter := filterFromConfig("test", config.NotifyFilter{Level: "verbose"}); filter.MinSeverity != SeverityInfo {
+		t.Errorf("min severity for unknown level = %s, want info", filter.MinSeverity)
+	}
+}
+
+// recorder - канал, запоминающий полученные события
+type recorder chan Event
+
+func (r recorder) Notify(event Event) error {
+	r <- event
+	return nil
+}
+
+func TestRouterAppliesChannelFilters(t *testing.T) {
+	errorsOnly := make(recorder, 10)
+	everything := make(recorder, 10)
+
+	router := NewRouter()
+	router.Add("errors", errorsOnly, Filter{MinSeverity: SeverityError})
+	router.Add("everything", everything, Filter{})
+
+	router.Publish(Event{Type: EventRaiseSucceeded})
+	router.Publish(Event{Type: EventRaiseFailed, Severity: SeverityError})
+
+	for _, want := range []EventType{EventRaiseSucceeded, EventRaiseFailed} {
+		select {
+		case event := <-everything:
+			if event.Type != want {
+				t.Errorf("everything channel got %s, want %s", event.Type, want)
+			}
+			if event.Time.IsZero() {
+				t.Error("published event has no time")
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("everything channel did not get %s", want)
+		}
+	}
+
+	select {
+	case event := <-errorsOnly:
+		if event.Type != EventRaiseFailed {
+			t.Errorf("errors channel got %s, want %s", event.Type, EventRaiseFailed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("errors channel did not get raise.failed")
+	}
+	select {
+	case event := <-errorsOnly:
+		t.Errorf("errors channel got filtered event %s", event.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Level - какие события присылать
+type Level string
+
+const (
+	LevelAll    Level = "all"    // все события
+	LevelErrors Level = "errors" // только неудачи: важность warning и error
+	LevelNone   Level = "none"   // ничего
+)
+
+// Levels - уровни в порядке переключения кнопкой
+var Levels = []Level{LevelAll, LevelErrors, LevelNone}
+
+// allows сообщает, проходит ли событие на этом уровне
+func (l Level) allows(event Event) bool {
+	switch l {
+	case LevelNone:
+		return false
+	case LevelErrors:
+		return event.Severity >= SeverityWarning
+	}
+	return true
+}
+
+// QuietMode - что делать с уведомлениями в тихие часы
+type QuietMode string
+
+const (
+	QuietSilent QuietMode = "silent" // присылать без звука
+	QuietDigest QuietMode = "digest" // копить до конца тихих часов и прислать сводкой; ошибки - без звука
+)
+
+// QuietHours - тихие часы: с Start до End минут от полуночи, в том числе через полночь.
+// Start == End - тихие часы выключены.
+type QuietHours struct {
+	Start int       `json:"start"`
+	End   int       `json:"end"`
+	Mode  QuietMode `json:"mode,omitempty"`
+}
+
+func (q QuietHours) Enabled() bool {
+	return q.Start != q.End
+}
+
+// Contains сообщает, приходится ли t на тихие часы
+func (q QuietHours) Contains(t time.Time) bool {
+	if !q.Enabled() {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// String - тихие часы в виде 23:00-08:00
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+// ParseQuietHours разбирает промежуток вида 23:00-08:00 или 23-8
+func ParseQuietHours(text string) (QuietHours, error) {
+	from, to, found := strings.Cut(strings.ReplaceAll(text, " ", ""), "-")
+	if !found {
+		return QuietHours{}, fmt.Errorf("ожидается промежуток вида 23:00-08:00")
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("начало и конец тихих часов совпадают")
+	}
+	return QuietHours{Start: start, End: end, Mode: QuietSilent}, nil
+}
+
+// parseClock разбирает время ЧЧ или ЧЧ:ММ и возвращает минуты от полуночи
+func parseClock(text string) (int, error) {
+	hourText, minuteText, _ := strings.Cut(text, ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("неверное время «%s»", text)
+	}
+	minute := 0
+	if minuteText != "" {
+		if minute, err = strconv.Atoi(minuteText); err != nil || minute < 0 || minute > 59 {
+			return 0, fmt.Errorf("неверное время «%s»", text)
+		}
+	}
+	return hour*60 + minute, nil
+}
+
+// Settings - настройки уведомлений пользователя. Уровень события берется из настройки
+// его резюме, затем из настройки типа события (или его категории), затем общий.
+type Settings struct {
+	Level   Level               `json:"level"`
+	Events  map[EventType]Level `json:"events,omitempty"`
+	Resumes map[string]Level    `json:"resumes,omitempty"` // по ключу расписания резюме
+	Quiet   QuietHours          `json:"quiet"`
+}
+
+// DefaultSettings - все уведомления, без тихих часов
+func DefaultSettings() Settings {
+	return Settings{Level: LevelAll}
+}
+
+// Clone возвращает копию настроек, не разделяющую с ними словари
+func (s Settings) Clone() Settings {
+	clone := s
+	clone.Events, clone.Resumes = nil, nil
+	for eventType, level := range s.Events {
+		clone.SetEventLevel(eventType, level)
+	}
+	for resume, level := range s.Resumes {
+		clone.SetResumeLevel(resume, level)
+	}
+	return clone
+}
+
+// Allows сообщает, нужно ли уведомлять о событии
+func (s Settings) Allows(event Event) bool {
+	if level, ok := s.override(event); ok {
+		return level.allows(event)
+	}
+	return s.GlobalLevel().allows(event)
+}
+
+// AllowsAlert - как Allows, но без общего уровня: важные события (ошибки входа и
+// исчерпанные попытки подъема) отключаются только явной настройкой резюме или типа события
+func (s Settings) AllowsAlert(event Event) bool {
+	if level, ok := s.override(event); ok {
+		return level.allows(event)
+	}
+	return true
+}
+
+// Delivers решает, присылать ли событие: важные события проверяются по AllowsAlert, остальные - по Allows
+func (s Settings) Delivers(event Event) bool {
+	if event.Type.IsAlert() {
+		return s.AllowsAlert(event)
+	}
+	return s.Allows(event)
+}
+
+// GlobalLevel возвращает общий уровень; пустой уровень старых настроек означает все события
+func (s Settings) GlobalLevel() Level {
+	if s.Level == "" {
+		return LevelAll
+	}
+	return s.Level
+}
+
+// override возвращает уровень, явно заданный для резюме или типа события
+func (s Settings) override(event Event) (Level, bool) {
+	if event.Resume != "" {
+		if level, ok := s.Resumes[event.Resume]; ok {
+			return level, true
+		}
+	}
+	if level, ok := s.Events[event.Type]; ok {
+		return level, true
+	}
+	if level, ok := s.Events[event.Type.Category()]; ok {
+		return level, true
+	}
+	return "", false
+}
+
+// SetEventLevel задает уровень типа события или категории; пустой уровень убирает настройку
+func (s *Settings) SetEventLevel(eventType EventType, level Level) {
+	if level == "" {
+		delete(s.Events, eventType)
+		return
+	}
+	if s.Events == nil {
+		s.Events = make(map[EventType]Level)
+	}
+	s.Events[eventType] = level
+}
+
+// SetResumeLevel задает уровень резюме; пустой уровень убирает настройку
+func (s *Settings) SetResumeLevel(resume string, level Level) {
+	if level == "" {
+		delete(s.Resumes, resume)
+		return
+	}
+	if s.Resumes == nil {
+		s.Resumes = make(map[string]Level)
+	}
+	s.Resumes[resume] = level
+}
+
+// IsAlert сообщает, важный ли тип события: исчерпанные попытки подъема, ошибка и восстановление входа
+func (t EventType) IsAlert() bool {
+	switch t {
+	case EventRaiseFailed, EventLoginFailed, EventLoginRestored:
+		return true
+	}
+	return false
+}
+
+// Category возвращает категорию типа события: raise для raise.succeeded
+func (t EventType) Category() EventType {
+	category, _, _ := strings.Cut(string(t), ".")
+	return EventType(category)
+}
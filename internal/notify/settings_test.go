@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	overnight := QuietHours{Start: 23 * 60, End: 8 * 60}
+	daytime := QuietHours{Start: 13 * 60, End: 14*60 + 30}
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		hour  int
+		min   int
+		want  bool
+	}{
+		{"overnight start is inclusive", overnight, 23, 0, true},
+		{"overnight before midnight", overnight, 23, 59, true},
+		{"overnight at midnight", overnight, 0, 0, true},
+		{"overnight after midnight", overnight, 3, 0, true},
+		{"overnight end is exclusive", overnight, 8, 0, false},
+		{"overnight just before start", overnight, 22, 59, false},
+		{"overnight at noon", overnight, 12, 0, false},
+		{"daytime inside", daytime, 14, 0, true},
+		{"daytime end minute is exclusive", daytime, 14, 30, false},
+		{"daytime before start", daytime, 12, 59, false},
+		{"daytime at midnight", daytime, 0, 0, false},
+		{"disabled", QuietHours{Start: 60, End: 60}, 1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := time.Date(2024, time.January, 1, tt.hour, tt.min, 0, 0, time.UTC)
+			if got := tt.quiet.Contains(at); got != tt.want {
+				t.Errorf("%s Contains(%02d:%02d) = %v, want %v", tt.quiet, tt.hour, tt.min, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    QuietHours
+		wantErr bool
+	}{
+		{"23:00-08:00", QuietHours{Start: 23 * 60, End: 8 * 60, Mode: QuietSilent}, false},
+		{"23-8", QuietHours{Start: 23 * 60, End: 8 * 60, Mode: QuietSilent}, false},
+		{"22:30 - 7:15", QuietHours{Start: 22*60 + 30, End: 7*60 + 15, Mode: QuietSilent}, false},
+		{"13-14", QuietHours{Start: 13 * 60, End: 14 * 60, Mode: QuietSilent}, false},
+		{"23:00", QuietHours{}, true},
+		{"8-8", QuietHours{}, true},
+		{"24-8", QuietHours{}, true},
+		{"23:60-8", QuietHours{}, true},
+		{"night-8", QuietHours{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseQuietHours(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseQuietHours(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseQuietHours(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingsAllows(t *testing.T) {
+	succeeded := Event{Type: EventRaiseSucceeded, Severity: SeverityInfo, Resume: "Go"}
+	retry := Event{Type: EventRaiseRetry, Severity: SeverityWarning, Resume: "Go"}
+	failed := Event{Type: EventRaiseFailed, Severity: SeverityError, Resume: "Go"}
+	loginFailed := Event{Type: EventLoginFailed, Severity: SeverityError}
+	vacationEnded := Event{Type: EventVacationEnded, Severity: SeverityInfo}
+
+	tests := []struct {
+		name      string
+		settings  Settings
+		event     Event
+		wantAllow bool // Allows
+		wantAlert bool // AllowsAlert
+	}{
+		{"default allows all", DefaultSettings(), succeeded, true, true},
+		{"empty level of old settings allows all", Settings{}, succeeded, true, true},
+		{"errors level drops info", Settings{Level: LevelErrors}, succeeded, false, true},
+		{"errors level keeps warnings", Settings{Level: LevelErrors}, retry, true, true},
+		{"none drops errors but not alerts", Settings{Level: LevelNone}, failed, false, true},
+		{"category override over level",
+			Settings{Level: LevelNone, Events: map[EventType]Level{"pause": LevelAll}}, vacationEnded, true, true},
+		{"category override silences alerts",
+			Settings{Level: LevelAll, Events: map[EventType]Level{"session": LevelNone}}, loginFailed, false, false},
+		{"type override over category",
+			Settings{Events: map[EventType]Level{"raise": LevelNone, EventRaiseFailed: LevelErrors}}, failed, true, true},
+		{"category override for other type of category",
+			Settings{Events: map[EventType]Level{"raise": LevelNone, EventRaiseFailed: LevelErrors}}, retry, false, false},
+		{"resume override over type",
+			Settings{Events: map[EventType]Level{EventRaiseSucceeded: LevelNone}, Resumes: map[string]Level{"Go": LevelAll}},
+			succeeded, true, true},
+		{"resume override errors level",
+			Settings{Resumes: map[string]Level{"Go": LevelErrors}}, succeeded, false, false},
+		{"resume override silences alerts",
+			Settings{Resumes: map[string]Level{"Go": LevelNone}}, failed, false, false},
+		{"other resume override does not apply",
+			Settings{Level: LevelNone, Resumes: map[string]Level{"Java": LevelAll}}, succeeded, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.settings.Allows(tt.event); got != tt.wantAllow {
+				t.Errorf("Allows(%s) = %v, want %v", tt.event.Type, got, tt.wantAllow)
+			}
+			if got := tt.settings.AllowsAlert(tt.event); got != tt.wantAlert {
+				t.Errorf("AllowsAlert(%s) = %v, want %v", tt.event.Type, got, tt.wantAlert)
+			}
+		})
+	}
+}
+
+func TestSettingsDelivers(t *testing.T) {
+	settings := Settings{Level: LevelNone}
+
+	tests := []struct {
+		event Event
+		want  bool
+	}{
+		{Event{Type: EventRaiseFailed, Severity: SeverityError}, true},
+		{Event{Type: EventLoginFailed, Severity: SeverityError}, true},
+		{Event{Type: EventLoginRestored, Severity: SeverityInfo}, true},
+		{Event{Type: EventRaiseRetry, Severity: SeverityWarning}, false},
+		{Event{Type: EventSessionExpired, Severity: SeverityWarning}, false},
+	}
+
+	for _, tt := range tests {
+		if got := settings.Delivers(tt.event); got != tt.want {
+			t.Errorf("Delivers(%s) with level none = %v, want %v", tt.event.Type, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Параметры повторной доставки вебхука: задержка растет вдвое после каждой неудачи
+const (
+	webhookMaxAttempts  = 8
+	webhookInitialDelay = 30 * time.Second
+	webhookMaxDelay     = time.Hour
+	// webhookIdleWait - как долго ждать новых событий, когда очередь пуста
+	webhookIdleWait = time.Hour
+)
+
+// DeliveryState - состояние доставки вебхука
+type DeliveryState string
+
+const (
+	DeliveryPending   DeliveryState = "pending"
+	DeliveryDelivered DeliveryState = "delivered"
+	DeliveryFailed    DeliveryState = "failed" // попытки исчерпаны
+)
+
+// Delivery - доставка одного события на адрес вебхука
+type Delivery struct {
+	ID          string        `json:"id"`
+	URL         string        `json:"url"`
+	Event       Event         `json:"event"`
+	State       DeliveryState `json:"state"`
+	Attempts    int           `json:"attempts"`
+	NextAttempt time.Time     `json:"next_attempt"`
+	LastStatus  int           `json:"last_status,omitempty"`
+	LastError   string        `json:"last_error,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// DeliveryStore хранит очередь доставки, чтобы события не терялись при перезапуске,
+// и историю завершенных доставок
+type DeliveryStore interface {
+	LoadWebhookQueue() ([]Delivery, error)
+	SaveWebhookQueue(queue []Delivery) error
+	AppendWebhookDelivery(delivery Delivery) error
+}
+
+// Webhook отправляет события JSON-запросом POST. Запрос подписывается HMAC-SHA256
+// секретом; неудачная доставка повторяется с растущей задержкой.
+//
+// Заголовки запроса:
+//   - X-Webhook-Event - тип события
+//   - X-Webhook-Delivery - ID доставки, одинаковый во всех попытках
+//   - X-Webhook-Timestamp - время отправки в секундах Unix
+//   - X-Webhook-Signature - sha256=<hex HMAC-SHA256 от "<timestamp>.<тело запроса>">
+type Webhook struct {
+	url    string
+	secret string
+	store  DeliveryStore
+
+	queue []Delivery
+	mutex sync.Mutex
+	wake  chan struct{}
+}
+
+// NewWebhook создает канал и возобновляет доставки, сохраненные в store до перезапуска
+func NewWebhook(url, secret string, store DeliveryStore) *Webhook {
+	w := &Webhook{
+		url:    url,
+		secret: secret,
+		store:  store,
+		wake:   make(chan struct{}, 1),
+	}
+
+	if queue, err := store.LoadWebhookQueue(); err != nil {
+		log.Printf("Failed to load webhook queue: %v", err)
+	} else {
+		w.queue = queue
+		if len(queue) > 0 {
+			log.Printf("Resuming %d pending webhook deliveries", len(queue))
+		}
+	}
+
+	go w.run()
+	return w
+}
+
+// Notify ставит событие в очередь доставки
+func (w *Webhook) Notify(event Event) error {
+	now := time.Now()
+	delivery := Delivery{
+		ID:          strconv.FormatInt(now.UnixNano(), 36),
+		URL:         w.url,
+		Event:       event,
+		State:       DeliveryPending,
+		NextAttempt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	w.mutex.Lock()
+	w.queue = append(w.queue, delivery)
+	err := w.saveQueue()
+	w.mutex.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+func (w *Webhook) run() {
+	for {
+		select {
+		case <-w.wake:
+		case <-time.After(w.untilNext()):
+		}
+		w.deliverDue()
+	}
+}
+
+// untilNext возвращает время до ближайшей попытки доставки
+func (w *Webhook) untilNext() time.Duration {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	wait := webhookIdleWait
+	for _, delivery := range w.queue {
+		if until := time.Until(delivery.NextAttempt); until < wait {
+			wait = until
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// deliverDue выполняет все наступившие попытки доставки
+func (w *Webhook) deliverDue() {
+	w.mutex.Lock()
+	var due []Delivery
+	now := time.Now()
+	for _, delivery := range w.queue {
+		if !delivery.NextAttempt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	w.mutex.Unlock()
+
+	for _, delivery := range due {
+		status, err := w.post(delivery)
+
+		delivery.Attempts++
+		delivery.LastStatus = status
+		delivery.LastError = ""
+		delivery.UpdatedAt = time.Now()
+		switch {
+		case err == nil:
+			delivery.State = DeliveryDelivered
+		case delivery.Attempts >= webhookMaxAttempts:
+			log.Printf("Webhook delivery %s of %s event failed after %d attempts: %v", delivery.ID, delivery.Event.Type, delivery.Attempts, err)
+			delivery.State = DeliveryFailed
+			delivery.LastError = err.Error()
+		default:
+			log.Printf("Webhook delivery %s of %s event failed (attempt %d/%d): %v", delivery.ID, delivery.Event.Type, delivery.Attempts, webhookMaxAttempts, err)
+			delivery.LastError = err.Error()
+			delivery.NextAttempt = delivery.UpdatedAt.Add(webhookRetryDelay(delivery.Attempts))
+		}
+
+		w.update(delivery)
+		if delivery.State != DeliveryPending {
+			if err := w.store.AppendWebhookDelivery(delivery); err != nil {
+				log.Printf("Failed to save webhook delivery: %v", err)
+			}
+		}
+	}
+}
+
+// update сохраняет результат попытки: завершенная доставка уходит из очереди
+func (w *Webhook) update(delivery Delivery) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for i := range w.queue {
+		if w.queue[i].ID != delivery.ID {
+			continue
+		}
+		if delivery.State == DeliveryPending {
+			w.queue[i] = delivery
+		} else {
+			w.queue = append(w.queue[:i], w.queue[i+1:]...)
+		}
+		break
+	}
+	if err := w.saveQueue(); err != nil {
+		log.Printf("Failed to save webhook queue: %v", err)
+	}
+}
+
+// saveQueue сохраняет очередь. Вызывается под блокировкой.
+func (w *Webhook) saveQueue() error {
+	return w.store.SaveWebhookQueue(append([]Delivery(nil), w.queue...))
+}
+
+// post отправляет событие и возвращает код ответа
+func (w *Webhook) post(delivery Delivery) (int, error) {
+	payload, err := webhookPayload(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hh-ru-auto-resume-raising")
+	req.Header.Set("X-Webhook-Event", string(delivery.Event.Type))
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	if w.secret != "" {
+		req.Header.Set("X-Webhook-Signature", "sha256="+Sign(w.secret, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign возвращает подпись вебхука: hex HMAC-SHA256 от "<timestamp>.<body>".
+// Получатель проверяет ее тем же секретом.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay возвращает задержку перед повтором после неудачной попытки attempt (с 1)
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookInitialDelay
+	for i := 1; i < attempt && delay < webhookMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxDelay {
+		return webhookMaxDelay
+	}
+	return delay
+}
+
+// webhookPayload - поля события вместе с его описанием (title и text)
+func webhookPayload(event Event) (map[string]interface{}, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	message := Render(event)
+	payload["title"] = message.Title
+	payload["text"] = strings.Join(message.Lines, "\n")
+	return payload, nil
+}
@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore - хранилище доставок в памяти; finished получает завершенные доставки
+type memoryStore struct {
+	queue    []Delivery
+	finished chan Delivery
+	mutex    sync.Mutex
+}
+
+func newMemoryStore(queue ...Delivery) *memoryStore {
+	return &memoryStore{queue: queue, finished: make(chan Delivery, 10)}
+}
+
+func (s *memoryStore) LoadWebhookQueue() ([]Delivery, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Delivery(nil), s.queue...), nil
+}
+
+func (s *memoryStore) SaveWebhookQueue(queue []Delivery) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.queue = queue
+	return nil
+}
+
+func (s *memoryStore) AppendWebhookDelivery(delivery Delivery) error {
+	s.finished <- delivery
+	return nil
+}
+
+func (s *memoryStore) pending() []Delivery {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Delivery(nil), s.queue...)
+}
+
+func (s *memoryStore) waitFinished(t *testing.T) Delivery {
+	t.Helper()
+	select {
+	case delivery := <-s.finished:
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook delivery did not finish")
+	}
+	return Delivery{}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", "1700000000", []byte(`{"type":"raise.failed"}`))
+	// hex HMAC-SHA256 от "1700000000.{"type":"raise.failed"}" с ключом "secret"
+	want := "427d19720eec293d1dab16d30bcd0a469a89af79b7083fb500fad83a0c772ff3"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if other := Sign("other", "1700000000", []byte(`{"type":"raise.failed"}`)); other == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if other := Sign("secret", "1700000001", []byte(`{"type":"raise.failed"}`)); other == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestWebhookSignsRequest(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{r.Header, body}
+	}))
+	defer server.Close()
+
+	store := newMemoryStore()
+	webhook := NewWebhook(server.URL, "secret", store)
+	if err := webhook.Notify(Event{Type: EventRaiseFailed, Severity: SeverityError, Resume: "Go", Error: "timeout"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got request
+	select {
+	case got = <-requests:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not sent")
+	}
+
+	timestamp := got.header.Get("X-Webhook-Timestamp")
+	if want := "sha256=" + Sign("secret", timestamp, got.body); got.header.Get("X-Webhook-Signature") != want {
+		t.Errorf("signature = %q, want %q", got.header.Get("X-Webhook-Signature"), want)
+	}
+	if sent, err := strconv.ParseInt(timestamp, 10, 64); err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Errorf("timestamp = %q, want current Unix time", timestamp)
+	}
+	if got.header.Get("X-Webhook-Event") != string(EventRaiseFailed) {
+		t.Errorf("event header = %q, want %q", got.header.Get("X-Webhook-Event"), EventRaiseFailed)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(got.body, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["type"] != string(EventRaiseFailed) || payload["resume"] != "Go" || payload["title"] == "" {
+		t.Errorf("payload = %v, want raise.failed event for Go with title", payload)
+	}
+
+	delivered := store.waitFinished(t)
+	if delivered.State != DeliveryDelivered || delivered.Attempts != 1 || delivered.ID != got.header.Get("X-Webhook-Delivery") {
+		t.Errorf("delivery = %+v, want delivered on first attempt with the sent ID", delivered)
+	}
+	if pending := store.pending(); len(pending) != 0 {
+		t.Errorf("queue after delivery = %+v, want empty", pending)
+	}
+}
+
+func TestWebhookUnsignedWithoutSecret(t *testing.T) {
+	signatures := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures <- r.Header.Get("X-Webhook-Signature")
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, "", newMemoryStore())
+	webhook.Notify(Event{Type: EventRaiseSucceeded})
+
+	select {
+	case signature := <-signatures:
+		if signature != "" {
+			t.Errorf("signature without secret = %q, want none", signature)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not sent")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhookRetryDelay(tt.attempt); got != tt.want {
+			t.Errorf("webhookRetryDelay(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSchedulesRetry(t *testing.T) {
+	attempts := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		attempts <- struct{}{}
+	}))
+	defer server.Close()
+
+	store := newMemoryStore()
+	webhook := NewWebhook(server.URL, "secret", store)
+	before := time.Now()
+	webhook.Notify(Event{Type: EventRaiseFailed})
+
+	select {
+	case <-attempts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not sent")
+	}
+
+	// Результат попытки сохраняется после ответа сервера
+	var pending []Delivery
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if pending = store.pending(); len(pending) == 1 && pending[0].Attempts == 1 {
+			break
+		}
+	}
+	if len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("queue after failed attempt = %+v, want one delivery with 1 attempt", pending)
+	}
+
+	delivery := pending[0]
+	if delivery.State != DeliveryPending || delivery.LastStatus != http.StatusBadGateway || delivery.LastError == "" {
+		t.Errorf("delivery = %+v, want pending with status 502 and error", delivery)
+	}
+	if wait := delivery.NextAttempt.Sub(before); wait < webhookInitialDelay || wait > webhookInitialDelay+5*time.Second {
+		t.Errorf("next attempt in %s, want %s", wait, webhookInitialDelay)
+	}
+}
+
+func TestWebhookResumesSavedQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Webhook-Delivery") == "failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	past := time.Now().Add(-time.Minute)
+	store := newMemoryStore(
+		Delivery{ID: "saved", URL: server.URL, Event: Event{Type: EventRaiseFailed}, State: DeliveryPending,
+			Attempts: 2, NextAttempt: past},
+		Delivery{ID: "failing", URL: server.URL, Event: Event{Type: EventLoginFailed}, State: DeliveryPending,
+			Attempts: webhookMaxAttempts - 1, NextAttempt: past},
+		Delivery{ID: "later", URL: server.URL, Event: Event{Type: EventRaiseRetry}, State: DeliveryPending,
+			Attempts: 1, NextAttempt: time.Now().Add(time.Hour)},
+	)
+	NewWebhook("http://unused.invalid", "secret", store)
+
+	finished := map[string]Delivery{}
+	for i := 0; i < 2; i++ {
+		delivery := store.waitFinished(t)
+		finished[delivery.ID] = delivery
+	}
+
+	if saved := finished["saved"]; saved.State != DeliveryDelivered || saved.Attempts != 3 {
+		t.Errorf("saved delivery = %+v, want delivered on attempt 3", saved)
+	}
+	if failing := finished["failing"]; failing.State != DeliveryFailed || failing.Attempts != webhookMaxAttempts || failing.LastError == "" {
+		t.Errorf("failing delivery = %+v, want failed after %d attempts", failing, webhookMaxAttempts)
+	}
+
+	// Доставка, время которой не наступило, остается в очереди
+	pending := store.pending()
+	if len(pending) != 1 || pending[0].ID != "later" || pending[0].Attempts != 1 {
+		t.Errorf("queue after resume = %+v, want only the later delivery", pending)
+	}
+}
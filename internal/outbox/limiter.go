@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"sync"
+	"time"
+)
+
+// Ограничения частоты Telegram Bot API: около 30 запросов в секунду на бота
+// и не больше одного сообщения в секунду в один чат (короткие всплески допустимы)
+const (
+	globalRate  = 30.0
+	globalBurst = 30.0
+	chatRate    = 1.0
+	chatBurst   = 3.0
+)
+
+// bucket - корзина токенов; токены могут уйти в минус, тогда следующий запрос ждет
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// reserve забирает токен и возвращает, сколько ждать до запроса
+func (b *bucket) reserve(now time.Time, rate, burst float64) time.Duration {
+	if b.updated.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.updated).Seconds() * rate
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.updated = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / rate * float64(time.Second))
+}
+
+// limiter соблюдает общие ограничения и ограничения по чатам, а также запрет
+// на запросы, который Telegram выставляет ответом 429 с retry_after
+type limiter struct {
+	global  bucket
+	chats   map[int64]*bucket
+	blocked map[int64]time.Time // до какого времени запросы в чат запрещены; 0 - во все чаты
+	mutex   sync.Mutex
+}
+
+func newLimiter() *limiter {
+	return &limiter{
+		chats:   make(map[int64]*bucket),
+		blocked: make(map[int64]time.Time),
+	}
+}
+
+// wait ждет, пока запрос в чат станет допустим; chatID 0 - запрос не к чату
+func (l *limiter) wait(chatID int64) {
+	if delay := l.reserve(chatID, time.Now()); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+func (l *limiter) reserve(chatID int64, now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delay := l.global.reserve(now, globalRate, globalBurst)
+	if chatID != 0 {
+		chat, exists := l.chats[chatID]
+		if !exists {
+			chat = &bucket{}
+			l.chats[chatID] = chat
+		}
+		if chatDelay := chat.reserve(now, chatRate, chatBurst); chatDelay > delay {
+			delay = chatDelay
+		}
+	}
+
+	for _, id := range []int64{0, chatID} {
+		if until := l.blocked[id].Sub(now); until > delay {
+			delay = until
+		}
+	}
+	return delay
+}
+
+// block запрещает запросы в чат (0 - во все чаты) на время retry_after
+func (l *limiter) block(chatID int64, delay time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	until := time.Now().Add(delay)
+	if until.After(l.blocked[chatID]) {
+		l.blocked[chatID] = until
+	}
+}
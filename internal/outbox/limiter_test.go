@@ -0,0 +1,176 @@
+package outbox
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"hh-ru-auto-resume-raising/internal/telegramfake"
+)
+
+func TestBucketReserve(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		after []time.Duration // моменты запросов от start
+		want  time.Duration   // ожидание перед последним запросом
+	}{
+		{"first request", []time.Duration{0}, 0},
+		{"burst", []time.Duration{0, 0, 0}, 0},
+		{"over burst waits one token", []time.Duration{0, 0, 0, 0}, time.Second},
+		{"waits accumulate", []time.Duration{0, 0, 0, 0, 0}, 2 * time.Second},
+		{"token refilled", []time.Duration{0, 0, 0, time.Second}, 0},
+		{"partial refill", []time.Duration{0, 0, 0, 0, 1500 * time.Millisecond}, 500 * time.Millisecond},
+		{"refill capped at burst", []time.Duration{0, time.Hour, time.Hour, time.Hour, time.Hour}, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bucket
+			var got time.Duration
+			for _, after := range tt.after {
+				got = b.reserve(start.Add(after), chatRate, chatBurst)
+			}
+			if got != tt.want {
+				t.Errorf("reserve = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterPerChat(t *testing.T) {
+	l := newLimiter()
+	now := time.Now()
+
+	for i := 0; i < int(chatBurst); i++ {
+		if delay := l.reserve(1, now); delay != 0 {
+			t.Fatalf("request %d to chat within burst waits %s", i+1, delay)
+		}
+	}
+	if delay := l.reserve(1, now); delay != time.Second {
+		t.Errorf("request over chat burst waits %s, want 1s", delay)
+	}
+	// Другой чат и запросы не к чату не ждут
+	if delay := l.reserve(2, now); delay != 0 {
+		t.Errorf("request to another chat waits %s, want none", delay)
+	}
+	if delay := l.reserve(0, now); delay != 0 {
+		t.Errorf("request not to a chat waits %s, want none", delay)
+	}
+}
+
+func TestLimiterGlobal(t *testing.T) {
+	l := newLimiter()
+	now := time.Now()
+
+	for i := 0; i < int(globalBurst); i++ {
+		if delay := l.reserve(int64(i+1), now); delay != 0 {
+			t.Fatalf("request %d within global burst waits %s", i+1, delay)
+		}
+	}
+
+	want := time.Second / globalRate
+	if delay := l.reserve(1000, now); delay != want {
+		t.Errorf("request over global burst waits %s, want %s", delay, want)
+	}
+	if delay := l.reserve(0, now); delay != 2*want {
+		t.Errorf("request not to a chat over global burst waits %s, want %s", delay, 2*want)
+	}
+}
+
+func TestLimiterBlock(t *testing.T) {
+	l := newLimiter()
+
+	l.block(1, 5*time.Second)
+	l.block(1, time.Second) // более короткий запрет не сокращает действующий
+
+	now := time.Now()
+	if delay := l.reserve(1, now); delay < 4*time.Second || delay > 5*time.Second {
+		t.Errorf("blocked chat waits %s, want about 5s", delay)
+	}
+	if delay := l.reserve(2, now); delay != 0 {
+		t.Errorf("other chat waits %s, want none", delay)
+	}
+
+	// Запрет без чата действует на все запросы
+	l.block(0, 3*time.Second)
+	if delay := l.reserve(2, time.Now()); delay < 2*time.Second || delay > 3*time.Second {
+		t.Errorf("chat after global block waits %s, want about 3s", delay)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		attempt   int
+		wantDelay time.Duration
+		wantRetry bool
+		wantBlock bool
+	}{
+		{"too many requests waits retry_after",
+			&tgbotapi.Error{Code: http.StatusTooManyRequests, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}},
+			1, 7 * time.Second, true, true},
+		{"retry_after ignores attempt",
+			&tgbotapi.Error{Code: http.StatusTooManyRequests, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 2}},
+			5, 2 * time.Second, true, true},
+		{"bad request is not retried", &tgbotapi.Error{Code: http.StatusBadRequest, Message: "chat not found"}, 1, 0, false, false},
+		{"blocked by user is not retried", &tgbotapi.Error{Code: http.StatusForbidden}, 1, 0, false, false},
+		{"server error backs off", &tgbotapi.Error{Code: http.StatusBadGateway}, 3, 4 * time.Second, true, false},
+		{"network error backs off", errors.New("connection refused"), 2, 2 * time.Second, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Outbox{limiter: newLimiter()}
+			delay, retry := o.retryDelay(42, tt.err, time.Second, tt.attempt)
+			if delay != tt.wantDelay || retry != tt.wantRetry {
+				t.Errorf("retryDelay = %s, %v, want %s, %v", delay, retry, tt.wantDelay, tt.wantRetry)
+			}
+
+			blocked := o.limiter.reserve(42, time.Now()) > 0
+			if blocked != tt.wantBlock {
+				t.Errorf("chat blocked = %v, want %v", blocked, tt.wantBlock)
+			}
+		})
+	}
+}
+
+// memoryStore - хранилище очереди в памяти
+type memoryStore struct{}
+
+func (memoryStore) LoadOutbox() ([]Message, error)         { return nil, nil }
+func (memoryStore) SaveOutbox([]Message) error             { return nil }
+func (memoryStore) AppendOutboxFailure(Message) error      { return nil }
+func (memoryStore) LoadOutboxFailures() ([]Message, error) { return nil, nil }
+
+func TestSendWaitsRetryAfter(t *testing.T) {
+	telegram, err := telegramfake.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer telegram.Close()
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(telegramfake.Token, telegram.URL()+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := New(api, memoryStore{})
+
+	// Имитация отвечает 429 с retry_after в 1 секунду
+	telegram.FailNext("sendMessage", http.StatusTooManyRequests, "Too Many Requests: retry after 1")
+	if _, err := o.Send(tgbotapi.NewMessage(42, "Резюме поднято")); err != nil {
+		t.Fatalf("Send after 429 = %v, want success", err)
+	}
+
+	calls := telegram.Calls("sendMessage")
+	if len(calls) != 2 {
+		t.Fatalf("sendMessage calls = %d, want 2", len(calls))
+	}
+	if wait := calls[1].Time.Sub(calls[0].Time); wait < time.Second {
+		t.Errorf("retried after %s, want at least retry_after 1s", wait)
+	}
+}
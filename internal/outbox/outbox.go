@@ -0,0 +1,374 @@
+// Package outbox отправляет запросы к Telegram с учетом ограничений частоты: ждет
+// retry_after после ответа 429 и повторяет запрос при сетевых ошибках. Уведомления
+// проходят через очередь на диске и доставляются и после недоступности Telegram
+// или перезапуска; сообщения, которые так и не удалось отправить, сохраняются
+// для экрана диагностики.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Параметры повторов. Прямой запрос (ответ пользователю) повторяется недолго: если
+// Telegram недоступен дольше, вызывающий получает ошибку. В очереди задержка
+// растет вдвое после каждой неудачи.
+const (
+	directAttempts     = 3
+	directInitialDelay = time.Second
+	directMaxWait      = 10 * time.Second
+
+	queueMaxAttempts  = 10
+	queueInitialDelay = 5 * time.Second
+	queueMaxDelay     = 10 * time.Minute
+	// queueIdleWait - как долго ждать новых сообщений, когда очередь пуста
+	queueIdleWait = time.Hour
+)
+
+// State - состояние сообщения в очереди
+type State string
+
+const (
+	StatePending State = "pending"
+	StateFailed  State = "failed" // попытки исчерпаны или Telegram отклонил сообщение
+)
+
+// Message - сообщение, ожидающее отправки или не отправленное
+type Message struct {
+	ID          string          `json:"id"`
+	ChatID      int64           `json:"chat_id"`
+	Text        string          `json:"text"`
+	ParseMode   string          `json:"parse_mode,omitempty"`
+	Silent      bool            `json:"silent,omitempty"`
+	ReplyMarkup json.RawMessage `json:"reply_markup,omitempty"`
+	State       State           `json:"state"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// Store хранит очередь, чтобы сообщения не терялись при перезапуске,
+// и неотправленные сообщения
+type Store interface {
+	LoadOutbox() ([]Message, error)
+	SaveOutbox(queue []Message) error
+	AppendOutboxFailure(message Message) error
+	LoadOutboxFailures() ([]Message, error)
+}
+
+// Outbox - отправка запросов к Telegram. Send и Request выполняются сразу (с ожиданием
+// ограничений частоты) и возвращают ошибку, если Telegram недоступен; через очередь
+// (Enqueue) отправляются уведомления, результат которых никто не ждет.
+type Outbox struct {
+	api     *tgbotapi.BotAPI
+	store   Store
+	limiter *limiter
+
+	queue []Message
+	mutex sync.Mutex
+	wake  chan struct{}
+}
+
+// New создает отправку и возобновляет доставку сообщений, сохраненных в store до перезапуска
+func New(api *tgbotapi.BotAPI, store Store) *Outbox {
+	o := &Outbox{
+		api:     api,
+		store:   store,
+		limiter: newLimiter(),
+		wake:    make(chan struct{}, 1),
+	}
+
+	if queue, err := store.LoadOutbox(); err != nil {
+		log.Printf("Failed to load Telegram outbox: %v", err)
+	} else {
+		o.queue = queue
+		if len(queue) > 0 {
+			log.Printf("Resuming %d pending Telegram messages", len(queue))
+		}
+	}
+
+	go o.run()
+	return o
+}
+
+// Username возвращает имя бота в Telegram
+func (o *Outbox) Username() string {
+	return o.api.Self.UserName
+}
+
+// Send выполняет запрос и возвращает отправленное сообщение. Сообщение, которое не удалось
+// отправить, не ставится в очередь: вызывающий ждет его ID (например, чтобы заменить
+// заглушку результатом) и получает ошибку. Неотправленное сообщение сохраняется
+// для экрана диагностики.
+func (o *Outbox) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var message tgbotapi.Message
+	attempts, _, err := o.do(c, func() (err error) {
+		message, err = o.api.Send(c)
+		return err
+	})
+
+	if config, ok := c.(tgbotapi.MessageConfig); ok && err != nil {
+		failed := newMessage(config)
+		failed.Attempts, failed.LastError = attempts, err.Error()
+		o.fail(failed)
+	}
+	return message, err
+}
+
+// Request выполняет запрос, результат которого не сообщение (ответ на кнопку, удаление и т.п.)
+func (o *Outbox) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	var resp *tgbotapi.APIResponse
+	_, _, err := o.do(c, func() (err error) {
+		resp, err = o.api.Request(c)
+		return err
+	})
+	return resp, err
+}
+
+// do выполняет запрос сразу, повторяя его при 429 и сетевых ошибках, пока ожидание недолгое.
+// Возвращает число попыток, последнюю ошибку и то, имеет ли смысл повторить запрос позже.
+func (o *Outbox) do(c tgbotapi.Chattable, request func() error) (int, bool, error) {
+	chatID := chatOf(c)
+
+	for attempt := 1; ; attempt++ {
+		o.limiter.wait(chatID)
+		err := request()
+		if err == nil {
+			return attempt, false, nil
+		}
+
+		delay, retry := o.retryDelay(chatID, err, directInitialDelay, attempt)
+		if !retry || attempt >= directAttempts || delay > directMaxWait {
+			return attempt, retry, err
+		}
+		log.Printf("Telegram request to chat %d failed (attempt %d/%d), retrying in %s: %v", chatID, attempt, directAttempts, delay, err)
+		time.Sleep(delay)
+	}
+}
+
+// Enqueue ставит сообщение в очередь; оно будет отправлено вместе с разметкой, даже если
+// Telegram сейчас недоступен или бот перезапустится
+func (o *Outbox) Enqueue(config tgbotapi.MessageConfig) error {
+	return o.push(newMessage(config))
+}
+
+func (o *Outbox) push(message Message) error {
+	o.mutex.Lock()
+	o.queue = append(o.queue, message)
+	err := o.saveQueue()
+	o.mutex.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Pending возвращает сообщения в очереди
+func (o *Outbox) Pending() []Message {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]Message(nil), o.queue...)
+}
+
+// Failures возвращает неотправленные сообщения, от старых к новым
+func (o *Outbox) Failures() ([]Message, error) {
+	return o.store.LoadOutboxFailures()
+}
+
+func (o *Outbox) run() {
+	for {
+		select {
+		case <-o.wake:
+		case <-time.After(o.untilNext()):
+		}
+		o.deliverDue()
+	}
+}
+
+// untilNext возвращает время до ближайшей попытки отправки
+func (o *Outbox) untilNext() time.Duration {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	wait := queueIdleWait
+	for _, message := range o.queue {
+		if until := time.Until(message.NextAttempt); until < wait {
+			wait = until
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+// deliverDue отправляет все сообщения, время которых наступило, в порядке постановки в очередь
+func (o *Outbox) deliverDue() {
+	o.mutex.Lock()
+	var due []Message
+	now := time.Now()
+	for _, message := range o.queue {
+		if !message.NextAttempt.After(now) {
+			due = append(due, message)
+		}
+	}
+	o.mutex.Unlock()
+
+	for _, message := range due {
+		o.limiter.wait(message.ChatID)
+		_, err := o.api.Send(message.config())
+
+		message.Attempts++
+		message.UpdatedAt = time.Now()
+		if err == nil {
+			o.remove(message.ID)
+			continue
+		}
+
+		message.LastError = err.Error()
+		delay, retry := o.retryDelay(message.ChatID, err, queueInitialDelay, message.Attempts)
+		if !retry || message.Attempts >= queueMaxAttempts {
+			log.Printf("Telegram message %s to chat %d failed after %d attempts: %v", message.ID, message.ChatID, message.Attempts, err)
+			o.remove(message.ID)
+			o.fail(message)
+			continue
+		}
+		log.Printf("Telegram message %s to chat %d failed (attempt %d/%d): %v", message.ID, message.ChatID, message.Attempts, queueMaxAttempts, err)
+		message.NextAttempt = message.UpdatedAt.Add(delay)
+		o.update(message)
+	}
+}
+
+// update сохраняет результат неудачной попытки
+func (o *Outbox) update(message Message) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for i := range o.queue {
+		if o.queue[i].ID == message.ID {
+			o.queue[i] = message
+			break
+		}
+	}
+	if err := o.saveQueue(); err != nil {
+		log.Printf("Failed to save Telegram outbox: %v", err)
+	}
+}
+
+// remove убирает сообщение из очереди
+func (o *Outbox) remove(id string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for i := range o.queue {
+		if o.queue[i].ID == id {
+			o.queue = append(o.queue[:i], o.queue[i+1:]...)
+			break
+		}
+	}
+	if err := o.saveQueue(); err != nil {
+		log.Printf("Failed to save Telegram outbox: %v", err)
+	}
+}
+
+// fail сохраняет сообщение, которое не удалось отправить, для экрана диагностики
+func (o *Outbox) fail(message Message) {
+	message.State = StateFailed
+	message.UpdatedAt = time.Now()
+	if err := o.store.AppendOutboxFailure(message); err != nil {
+		log.Printf("Failed to save failed Telegram message: %v", err)
+	}
+}
+
+// saveQueue сохраняет очередь. Вызывается под блокировкой.
+func (o *Outbox) saveQueue() error {
+	return o.store.SaveOutbox(append([]Message(nil), o.queue...))
+}
+
+// retryDelay сообщает, стоит ли повторить запрос после ошибки и через сколько. При 429
+// запросы в чат приостанавливаются на retry_after; сетевые ошибки и ошибки сервера
+// Telegram повторяются с растущей задержкой, остальные (чат не найден, бот
+// заблокирован, неверная разметка) - нет.
+func (o *Outbox) retryDelay(chatID int64, err error, initial time.Duration, attempt int) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			delay := time.Duration(apiErr.RetryAfter) * time.Second
+			o.limiter.block(chatID, delay)
+			return delay, true
+		case apiErr.Code < http.StatusInternalServerError:
+			return 0, false
+		}
+	}
+	return backoff(initial, attempt), true
+}
+
+// backoff возвращает задержку перед повтором после неудачной попытки attempt (с 1)
+func backoff(initial time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < queueMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > queueMaxDelay {
+		return queueMaxDelay
+	}
+	return delay
+}
+
+func newMessage(config tgbotapi.MessageConfig) Message {
+	now := time.Now()
+	message := Message{
+		ID:          strconv.FormatInt(now.UnixNano(), 36),
+		ChatID:      config.ChatID,
+		Text:        config.Text,
+		ParseMode:   config.ParseMode,
+		Silent:      config.DisableNotification,
+		State:       StatePending,
+		NextAttempt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if config.ReplyMarkup != nil {
+		markup, err := json.Marshal(config.ReplyMarkup)
+		if err != nil {
+			log.Printf("Failed to encode reply markup of Telegram message to chat %d: %v", config.ChatID, err)
+		} else {
+			message.ReplyMarkup = markup
+		}
+	}
+	return message
+}
+
+// config собирает запрос на отправку сообщения из очереди
+func (m Message) config() tgbotapi.MessageConfig {
+	config := tgbotapi.NewMessage(m.ChatID, m.Text)
+	config.ParseMode = m.ParseMode
+	config.DisableNotification = m.Silent
+	if len(m.ReplyMarkup) > 0 {
+		config.ReplyMarkup = m.ReplyMarkup
+	}
+	return config
+}
+
+// chatOf возвращает чат запроса для ограничения частоты; 0 - запрос не к чату
+func chatOf(c tgbotapi.Chattable) int64 {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		return config.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return config.ChatID
+	}
+	return 0
+}
@@ -0,0 +1,625 @@
+// Package schedparse разбирает расписание подъема, записанное обычной фразой
+// на русском или английском: "по будням в 9 и 14", "каждые 6 часов с 8 до 22",
+// "weekdays at 9am", "в 10 с разбросом 15 минут".
+package schedparse
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+// maxInterval - интервал больше суток расписанием не задать
+const maxInterval = 24 * time.Hour
+
+// maxJitter - наибольший случайный разброс времени подъема
+const maxJitter = time.Hour
+
+// fillers - служебные слова, которые не влияют на смысл фразы
+var fillers = map[string]bool{
+	",": true, ";": true, "и": true, "а": true, "также": true, "в": true, "во": true, "по": true,
+	"поднимать": true, "поднимай": true, "подними": true, "резюме": true,
+	"and": true, "at": true, "on": true, "the": true, "also": true, "raise": true, "with": true,
+}
+
+var (
+	allDays     = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+	workDays    = allDays[:5]
+	weekendDays = allDays[5:]
+)
+
+// dayGroups - слова, обозначающие сразу несколько дней
+var dayGroups = map[string][]time.Weekday{
+	"ежедневно": allDays, "daily": allDays, "everyday": allDays,
+	"будни": workDays, "будням": workDays, "буднях": workDays, "weekdays": workDays, "weekday": workDays,
+	"выходные": weekendDays, "выходным": weekendDays, "выходных": weekendDays, "weekends": weekendDays, "weekend": weekendDays,
+}
+
+// dayNames - сокращения и основы названий дней недели
+var dayNames = []struct {
+	weekday time.Weekday
+	exact   []string
+	prefix  []string
+}{
+	{time.Monday, []string{"пн", "mon", "monday"}, []string{"понед"}},
+	{time.Tuesday, []string{"вт", "tue", "tues", "tuesday"}, []string{"вторн"}},
+	{time.Wednesday, []string{"ср", "wed", "wednesday"}, []string{"сред"}},
+	{time.Thursday, []string{"чт", "thu", "thur", "thurs", "thursday"}, []string{"четверг"}},
+	{time.Friday, []string{"пт", "fri", "friday"}, []string{"пятниц"}},
+	{time.Saturday, []string{"сб", "sat", "saturday"}, []string{"суббот"}},
+	{time.Sunday, []string{"вс", "sun", "sunday"}, []string{"воскресен"}},
+}
+
+var (
+	everyWords    = map[string]bool{"каждые": true, "каждый": true, "каждую": true, "каждое": true, "every": true}
+	hourUnits     = map[string]bool{"ч": true, "час": true, "часа": true, "часов": true, "h": true, "hr": true, "hrs": true, "hour": true, "hours": true}
+	minuteUnits   = map[string]bool{"мин": true, "минут": true, "минуты": true, "минуту": true, "m": true, "min": true, "mins": true, "minute": true, "minutes": true}
+	dayWords      = map[string]bool{"день": true, "дни": true, "дням": true, "day": true, "days": true}
+	rangeWords    = map[string]bool{"-": true, "до": true, "по": true, "to": true, "till": true, "until": true, "through": true, "and": true}
+	dayRangeWords = map[string]bool{"-": true, "до": true, "по": true, "to": true, "till": true, "until": true, "through": true}
+	windowStarts  = map[string]bool{"с": true, "со": true, "from": true, "between": true}
+	jitterWords   = map[string]bool{"±": true, "разброс": true, "разбросом": true, "jitter": true}
+	clockSuffixes = map[string]bool{"ч": true, "час": true, "часа": true, "часов": true, "o'clock": true, "h": true}
+)
+
+// Parse разбирает фразу с расписанием в настройки, которые принимает scheduler.AddResume.
+// Ошибки описывают проблему по-русски и подходят для показа пользователю.
+func Parse(text string) (scheduler.ScheduleSettings, error) {
+	p := &parser{tokens: tokenize(text), days: map[time.Weekday]bool{}}
+	if len(p.tokens) == 0 {
+		return scheduler.ScheduleSettings{}, fmt.Errorf("пустая фраза")
+	}
+
+	for !p.done() {
+		token := p.peek()
+		if fillers[token] {
+			p.pos++
+			continue
+		}
+
+		matched, err := p.parseDays()
+		if !matched && err == nil {
+			matched, err = p.parseInterval()
+		}
+		if !matched && err == nil {
+			matched, err = p.parseJitter()
+		}
+		if !matched && err == nil {
+			matched, err = p.parseWindow()
+		}
+		if !matched && err == nil {
+			matched, err = p.parseTime()
+		}
+		if err != nil {
+			return scheduler.ScheduleSettings{}, err
+		}
+		if !matched {
+			return scheduler.ScheduleSettings{}, fmt.Errorf("непонятное слово «%s»", token)
+		}
+	}
+
+	return p.settings()
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+
+	days      map[time.Weekday]bool
+	times     []int
+	intervals []time.Duration
+	windows   []scheduler.TimeWindow
+	jitters   []time.Duration
+}
+
+// tokenize приводит фразу к нижнему регистру и разбивает на слова,
+// отделяя запятые и дефисы диапазонов ("пн-пт", "8-22"). "+-" и "плюс-минус" заменяются на "±".
+func tokenize(text string) []string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("ё", "е", "плюс-минус", " ± ", "+/-", " ± ", "+-", " ± ", "±", " ± ",
+		"–", " - ", "—", " - ", ",", " , ", ";", " ; ", "-", " - ").Replace(text)
+
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		field = strings.TrimRight(field, ".!?")
+		if field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	return p.at(p.pos)
+}
+
+func (p *parser) at(index int) string {
+	if index < len(p.tokens) {
+		return p.tokens[index]
+	}
+	return ""
+}
+
+// parseDays разбирает дни недели: "по будням", "каждый день", "пн-пт", "с понедельника по пятницу"
+func (p *parser) parseDays() (bool, error) {
+	pos := p.pos
+	if windowStarts[p.at(pos)] {
+		if _, ok := dayByName(p.at(pos + 1)); !ok {
+			return false, nil
+		}
+		pos++
+	}
+
+	token := p.at(pos)
+	if everyWords[token] {
+		next := p.at(pos + 1)
+		if dayWords[next] {
+			p.addDays(allDays)
+			p.pos = pos + 2
+			return true, nil
+		}
+		if days, ok := dayGroups[next]; ok {
+			p.addDays(days)
+			p.pos = pos + 2
+			return true, nil
+		}
+		if weekday, ok := dayByName(next); ok {
+			p.addDays([]time.Weekday{weekday})
+			p.pos = pos + 2
+			return true, nil
+		}
+		return false, nil
+	}
+
+	if days, ok := dayGroups[token]; ok {
+		p.addDays(days)
+		p.pos = pos + 1
+		// "рабочие дни", "выходные дни"
+		if dayWords[p.peek()] {
+			p.pos++
+		}
+		return true, nil
+	}
+	if token == "рабочие" || token == "рабочим" || token == "рабочих" {
+		p.addDays(workDays)
+		p.pos = pos + 1
+		if dayWords[p.peek()] {
+			p.pos++
+		}
+		return true, nil
+	}
+
+	from, ok := dayByName(token)
+	if !ok {
+		return false, nil
+	}
+	p.pos = pos + 1
+
+	if dayRangeWords[p.peek()] {
+		if to, ok := dayByName(p.at(p.pos + 1)); ok {
+			p.pos += 2
+			for weekday := from; ; weekday = (weekday + 1) % 7 {
+				p.days[weekday] = true
+				if weekday == to {
+					break
+				}
+			}
+			return true, nil
+		}
+	}
+	p.days[from] = true
+	return true, nil
+}
+
+func (p *parser) addDays(days []time.Weekday) {
+	for _, weekday := range days {
+		p.days[weekday] = true
+	}
+}
+
+func dayByName(token string) (time.Weekday, bool) {
+	for _, day := range dayNames {
+		for _, name := range day.exact {
+			if token == name || token == name+"s" {
+				return day.weekday, true
+			}
+		}
+		for _, prefix := range day.prefix {
+			if strings.HasPrefix(token, prefix) {
+				return day.weekday, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// parseInterval разбирает интервал: "каждые 6 часов", "раз в 8 ч", "every 6h"
+func (p *parser) parseInterval() (bool, error) {
+	pos := p.pos
+	switch {
+	case everyWords[p.at(pos)]:
+		pos++
+	case p.at(pos) == "раз" && (p.at(pos+1) == "в" || p.at(pos+1) == "во"):
+		pos += 2
+	case p.at(pos) == "once" && p.at(pos+1) == "every":
+		pos += 2
+	default:
+		return false, nil
+	}
+
+	amount, unit := 1, p.at(pos)
+	if number, err := strconv.Atoi(unit); err == nil {
+		amount, unit = number, p.at(pos+1)
+		pos++
+	} else if number, glued := splitNumber(unit); glued != "" {
+		// "6ч", "6h", "90min"
+		amount, unit = number, glued
+	}
+
+	var interval time.Duration
+	switch {
+	case hourUnits[unit]:
+		interval = time.Duration(amount) * time.Hour
+	case minuteUnits[unit]:
+		interval = time.Duration(amount) * time.Minute
+	case unit == "сутки" || unit == "суток" || unit == "дня" || dayWords[unit]:
+		interval = time.Duration(amount) * 24 * time.Hour
+	default:
+		return false, fmt.Errorf("после «%s» укажите интервал, например «каждые 6 часов»", p.peek())
+	}
+
+	p.intervals = append(p.intervals, interval)
+	p.pos = pos + 1
+	return true, nil
+}
+
+// splitNumber разделяет слитно записанные число и единицу: "6ч" -> 6, "ч"
+func splitNumber(token string) (int, string) {
+	i := 0
+	for i < len(token) && token[i] >= '0' && token[i] <= '9' {
+		i++
+	}
+	if i == 0 || i == len(token) {
+		return 0, ""
+	}
+	number, err := strconv.Atoi(token[:i])
+	if err != nil {
+		return 0, ""
+	}
+	return number, token[i:]
+}
+
+// parseJitter разбирает случайный разброс времени подъема: "с разбросом до 15 минут",
+// "±10 мин", "jitter 15m". Подъем откладывается на случайное время до указанного.
+func (p *parser) parseJitter() (bool, error) {
+	pos := p.pos
+	if windowStarts[p.at(pos)] && jitterWords[p.at(pos+1)] {
+		pos++
+	}
+	if !jitterWords[p.at(pos)] {
+		return false, nil
+	}
+	start := p.at(pos)
+	pos++
+	switch {
+	case p.at(pos) == "до":
+		pos++
+	case p.at(pos) == "up" && p.at(pos+1) == "to":
+		pos += 2
+	}
+
+	amount, unit := splitNumber(p.at(pos))
+	if number, err := strconv.Atoi(p.at(pos)); err == nil {
+		amount, unit = number, p.at(pos+1)
+		pos++
+	}
+
+	var jitter time.Duration
+	switch {
+	case minuteUnits[unit]:
+		jitter = time.Duration(amount) * time.Minute
+	case hourUnits[unit]:
+		jitter = time.Duration(amount) * time.Hour
+	default:
+		return false, fmt.Errorf("после «%s» укажите разброс, например «с разбросом 15 минут»", start)
+	}
+
+	p.jitters = append(p.jitters, jitter)
+	p.pos = pos + 1
+	return true, nil
+}
+
+// parseWindow разбирает окно: "с 8 до 22", "from 8am to 10pm", "between 9 and 18"
+func (p *parser) parseWindow() (bool, error) {
+	if !windowStarts[p.peek()] {
+		return false, nil
+	}
+	start := p.peek()
+
+	p.pos++
+	from, _, err := p.parseClock(false)
+	if err != nil {
+		return false, fmt.Errorf("после «%s» укажите начало окна, например «с 8 до 22»", start)
+	}
+	if !rangeWords[p.peek()] {
+		return false, fmt.Errorf("не указан конец окна после «%s %s», например «с 8 до 22»", start, formatMinutes(from))
+	}
+	p.pos++
+	to, _, err := p.parseClock(true)
+	if err != nil {
+		return false, fmt.Errorf("не удалось разобрать конец окна: %v", err)
+	}
+
+	return true, p.addWindow(from, to)
+}
+
+// parseTime разбирает время подъема: "9", "9:30", "9am", "9 утра", "14 ч".
+// Время, за которым следует дефис, считается окном: "8-22".
+func (p *parser) parseTime() (bool, error) {
+	if !startsClock(p.peek()) {
+		return false, nil
+	}
+
+	minute, explicit, err := p.parseClock(false)
+	if err != nil {
+		return false, err
+	}
+
+	if p.peek() == "-" {
+		p.pos++
+		to, _, err := p.parseClock(true)
+		if err != nil {
+			return false, fmt.Errorf("не удалось разобрать конец окна: %v", err)
+		}
+		return true, p.addWindow(minute, to)
+	}
+
+	if !explicit && minute >= 60 && minute <= 6*60 {
+		hour := minute / 60
+		return false, fmt.Errorf("«%d» можно понять двояко: уточните «%d утра» или «%d вечера» (%02d:00)", hour, hour, hour, hour+12)
+	}
+	p.times = append(p.times, minute)
+	return true, nil
+}
+
+func (p *parser) addWindow(from, to int) error {
+	if from == to {
+		return fmt.Errorf("окно %s-%s пустое", formatMinutes(from), formatMinutes(to))
+	}
+	p.windows = append(p.windows, scheduler.TimeWindow{From: from, To: to})
+	return nil
+}
+
+func startsClock(token string) bool {
+	switch token {
+	case "полдень", "полночь", "noon", "midnight":
+		return true
+	}
+	return token != "" && token[0] >= '0' && token[0] <= '9'
+}
+
+// parseClock разбирает момент суток и возвращает минуты от начала суток.
+// explicit сообщает, что время однозначно: указаны минуты, 24-часовой формат или "утра/вечера".
+// windowEnd разрешает значение 24 (конец суток).
+func (p *parser) parseClock(windowEnd bool) (int, bool, error) {
+	token := p.peek()
+	switch token {
+	case "полдень", "noon":
+		p.pos++
+		return 12 * 60, true, nil
+	case "полночь", "midnight":
+		p.pos++
+		return 0, true, nil
+	}
+	if !startsClock(token) {
+		return 0, false, fmt.Errorf("ожидалось время, а не «%s»", token)
+	}
+	p.pos++
+
+	// Суффикс am/pm может быть записан слитно: "9am", "9:30pm"
+	clock, suffix := token, ""
+	for _, candidate := range []string{"a.m", "p.m", "am", "pm"} {
+		if strings.HasSuffix(clock, candidate) {
+			clock, suffix = strings.TrimSuffix(clock, candidate), candidate
+			break
+		}
+	}
+
+	hour, minute, hasMinutes, err := splitClock(clock)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if clockSuffixes[p.peek()] {
+		p.pos++
+	}
+	if suffix == "" {
+		switch p.peek() {
+		case "am", "a.m", "pm", "p.m", "утра", "дня", "вечера", "ночи":
+			suffix = p.peek()
+			p.pos++
+		}
+	}
+
+	explicit := hasMinutes || hour == 0 || hour > 12 || suffix != ""
+	switch suffix {
+	case "am", "a.m", "pm", "p.m":
+		if hour < 1 || hour > 12 {
+			return 0, false, fmt.Errorf("«%s» - в 12-часовом формате час должен быть от 1 до 12", token)
+		}
+		hour %= 12
+		if suffix == "pm" || suffix == "p.m" {
+			hour += 12
+		}
+	case "утра":
+		if hour > 12 {
+			return 0, false, fmt.Errorf("«%d утра» - такого времени нет", hour)
+		}
+		hour %= 12
+	case "дня", "вечера":
+		if hour > 12 && hour < 24 {
+			break
+		}
+		if hour < 1 || hour > 12 {
+			return 0, false, fmt.Errorf("«%d %s» - такого времени нет", hour, suffix)
+		}
+		hour = hour%12 + 12
+	case "ночи":
+		switch {
+		case hour == 12:
+			hour = 0
+		case hour >= 9 && hour < 12:
+			hour += 12
+		case hour > 6 && hour < 9:
+			return 0, false, fmt.Errorf("«%d ночи» - такого времени нет", hour)
+		}
+	}
+
+	if windowEnd && hour == 24 && minute == 0 {
+		return 0, true, nil
+	}
+	if hour > 23 {
+		return 0, false, fmt.Errorf("час «%d» вне диапазона 0-23", hour)
+	}
+	return hour*60 + minute, explicit, nil
+}
+
+// splitClock разбирает "9", "09:30" или "9.30"
+func splitClock(clock string) (int, int, bool, error) {
+	hourText, minuteText, hasMinutes := strings.Cut(strings.ReplaceAll(clock, ".", ":"), ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("не удалось разобрать время «%s»", clock)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteText)
+		if err != nil || len(minuteText) != 2 {
+			return 0, 0, false, fmt.Errorf("не удалось разобрать время «%s»", clock)
+		}
+		if minute > 59 {
+			return 0, 0, false, fmt.Errorf("минуты «%d» вне диапазона 0-59", minute)
+		}
+	}
+	return hour, minute, hasMinutes, nil
+}
+
+// settings проверяет разобранную фразу на противоречия и собирает настройки расписания
+func (p *parser) settings() (scheduler.ScheduleSettings, error) {
+	var settings scheduler.ScheduleSettings
+
+	if len(p.intervals) > 1 {
+		return settings, fmt.Errorf("интервал указан несколько раз - оставьте один")
+	}
+	times := uniqueSorted(p.times)
+
+	if len(p.intervals) == 1 {
+		interval := p.intervals[0]
+		switch {
+		case len(times) > 1:
+			return settings, fmt.Errorf("указано и время (%s), и интервал - непонятно, что из них использовать; оставьте что-то одно",
+				formatTimes(times))
+		case interval < scheduler.DefaultInterval:
+			return settings, fmt.Errorf("hh.ru разрешает поднимать резюме не чаще раза в %d часа", int(scheduler.DefaultInterval.Hours()))
+		case interval > maxInterval:
+			return settings, fmt.Errorf("интервал больше суток не поддерживается")
+		}
+		settings.Interval = interval
+
+		// Сетка подъемов отсчитывается от указанного времени, начала первого окна или полуночи
+		switch {
+		case len(times) == 1:
+			settings.Hour, settings.Minute = times[0]/60, times[0]%60
+		case len(p.windows) > 0:
+			settings.Hour, settings.Minute = p.windows[0].From/60, p.windows[0].From%60
+		}
+	} else {
+		if len(times) == 0 {
+			return settings, fmt.Errorf("не указано время подъема - например «в 9», «в 9 и 14» или «каждые 6 часов»")
+		}
+		for i := range times {
+			gap := times[(i+1)%len(times)] - times[i]
+			if gap <= 0 {
+				gap += 24 * 60
+			}
+			if len(times) > 1 && time.Duration(gap)*time.Minute < scheduler.DefaultInterval {
+				return settings, fmt.Errorf("между подъемами %s и %s меньше %d часов - hh.ru не даст поднять резюме так часто",
+					formatMinutes(times[i]), formatMinutes(times[(i+1)%len(times)]), int(scheduler.DefaultInterval.Hours()))
+			}
+		}
+		settings.Hour, settings.Minute = times[0]/60, times[0]%60
+		settings.Times = times
+	}
+
+	settings.Windows = p.windows
+	check := scheduler.ScheduleSettings{Windows: p.windows}
+	for _, minute := range times {
+		check.Times = []int{minute}
+		if len(check.DayPlan()) == 0 {
+			return settings, fmt.Errorf("время %s не попадает в окно %s", formatMinutes(minute), formatWindows(p.windows))
+		}
+	}
+
+	if len(p.jitters) > 1 {
+		return settings, fmt.Errorf("разброс указан несколько раз - оставьте один")
+	}
+	if len(p.jitters) == 1 {
+		switch jitter := p.jitters[0]; {
+		case jitter <= 0:
+			return settings, fmt.Errorf("разброс должен быть больше нуля")
+		case jitter > maxJitter:
+			return settings, fmt.Errorf("разброс больше %d минут не поддерживается", int(maxJitter.Minutes()))
+		default:
+			settings.Jitter = jitter
+		}
+	}
+
+	if len(p.days) < len(allDays) {
+		for _, weekday := range allDays {
+			if p.days[weekday] {
+				settings.Weekdays = append(settings.Weekdays, weekday)
+			}
+		}
+	}
+	return settings, nil
+}
+
+func uniqueSorted(values []int) []int {
+	var result []int
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	for i, value := range sorted {
+		if i == 0 || value != sorted[i-1] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func formatMinutes(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func formatTimes(times []int) string {
+	parts := make([]string, len(times))
+	for i, minute := range times {
+		parts[i] = formatMinutes(minute)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatWindows(windows []scheduler.TimeWindow) string {
+	parts := make([]string, len(windows))
+	for i, window := range windows {
+		parts[i] = window.String()
+	}
+	return strings.Join(parts, ", ")
+}
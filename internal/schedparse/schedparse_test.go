@@ -0,0 +1,128 @@
+package schedparse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+var (
+	weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	weekends = []time.Weekday{time.Saturday, time.Sunday}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		phrase string
+		want   scheduler.ScheduleSettings
+	}{
+		// Интервалы
+		{"каждые 6 часов", scheduler.ScheduleSettings{Interval: 6 * time.Hour}},
+		{"раз в 8 ч", scheduler.ScheduleSettings{Interval: 8 * time.Hour}},
+		{"every 6h", scheduler.ScheduleSettings{Interval: 6 * time.Hour}},
+		{"once every 240 minutes", scheduler.ScheduleSettings{Interval: 4 * time.Hour}},
+		{"раз в сутки", scheduler.ScheduleSettings{Interval: 24 * time.Hour}},
+		{"каждые 4 часа в 7:30", scheduler.ScheduleSettings{Hour: 7, Minute: 30, Interval: 4 * time.Hour}},
+
+		// Фиксированное время
+		{"в 9 и 14", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60, 14 * 60}}},
+		{"ежедневно в 10:30", scheduler.ScheduleSettings{Hour: 10, Minute: 30, Times: []int{10*60 + 30}}},
+		{"в 9 утра и 8 вечера", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60, 20 * 60}}},
+		{"в 18 и 9, в 18", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60, 18 * 60}}},
+		{"at 9am and 5:30pm", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60, 17*60 + 30}}},
+		{"в полдень", scheduler.ScheduleSettings{Hour: 12, Times: []int{12 * 60}}},
+		{"в 11 ночи", scheduler.ScheduleSettings{Hour: 23, Times: []int{23 * 60}}},
+
+		// Дни недели и диапазоны дней
+		{"по будням в 9 и 14", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60, 14 * 60}, Weekdays: weekdays}},
+		{"weekdays at 9am", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60}, Weekdays: weekdays}},
+		{"по выходным в 11", scheduler.ScheduleSettings{Hour: 11, Times: []int{11 * 60}, Weekdays: weekends}},
+		{"пн-пт в 10", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60}, Weekdays: weekdays}},
+		{"с понедельника по пятницу в 9:00", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60}, Weekdays: weekdays}},
+		{"пт-пн в 12", scheduler.ScheduleSettings{Hour: 12, Times: []int{12 * 60},
+			Weekdays: []time.Weekday{time.Monday, time.Friday, time.Saturday, time.Sunday}}},
+		{"вторник и четверг в 10", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60},
+			Weekdays: []time.Weekday{time.Tuesday, time.Thursday}}},
+		{"mon through wed at 10", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60},
+			Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday}}},
+		{"каждый день в 10", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60}}},
+
+		// Окна
+		{"каждые 6 часов с 8 до 22", scheduler.ScheduleSettings{Hour: 8, Interval: 6 * time.Hour,
+			Windows: []scheduler.TimeWindow{{From: 8 * 60, To: 22 * 60}}}},
+		{"8-22 каждые 4 часа", scheduler.ScheduleSettings{Hour: 8, Interval: 4 * time.Hour,
+			Windows: []scheduler.TimeWindow{{From: 8 * 60, To: 22 * 60}}}},
+		{"every 4 hours from 10pm to 6am", scheduler.ScheduleSettings{Hour: 22, Interval: 4 * time.Hour,
+			Windows: []scheduler.TimeWindow{{From: 22 * 60, To: 6 * 60}}}},
+		{"в 10 и 18 с 9 до 20", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60, 18 * 60},
+			Windows: []scheduler.TimeWindow{{From: 9 * 60, To: 20 * 60}}}},
+		{"каждые 4 часа с 18 до 24", scheduler.ScheduleSettings{Hour: 18, Interval: 4 * time.Hour,
+			Windows: []scheduler.TimeWindow{{From: 18 * 60, To: 0}}}},
+
+		// Разброс
+		{"в 10 с разбросом 15 минут", scheduler.ScheduleSettings{Hour: 10, Times: []int{10 * 60}, Jitter: 15 * time.Minute}},
+		{"каждые 6 часов ±10 мин", scheduler.ScheduleSettings{Interval: 6 * time.Hour, Jitter: 10 * time.Minute}},
+		{"в 9 плюс-минус 5 минут", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60}, Jitter: 5 * time.Minute}},
+		{"в 12, разброс до 1 часа", scheduler.ScheduleSettings{Hour: 12, Times: []int{12 * 60}, Jitter: time.Hour}},
+		{"weekdays at 9am with jitter 15m", scheduler.ScheduleSettings{Hour: 9, Times: []int{9 * 60},
+			Weekdays: weekdays, Jitter: 15 * time.Minute}},
+		{"every 6 hours +/- 20 min", scheduler.ScheduleSettings{Interval: 6 * time.Hour, Jitter: 20 * time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, err := Parse(tt.phrase)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.phrase, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.phrase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		phrase string
+		want   string // часть текста ошибки
+	}{
+		{"", "пустая фраза"},
+		{"пн-пт", "не указано время"},
+		{"завтра в 9", "непонятное слово «завтра»"},
+		{"в 5", "можно понять двояко"},
+		{"в 25", "вне диапазона 0-23"},
+		{"в 9:75", "вне диапазона 0-59"},
+		{"в 13 утра", "такого времени нет"},
+		{"at 13pm", "от 1 до 12"},
+		{"в 9 и 11", "меньше 4 часов"},
+		{"в 22 и 1 ночи", "меньше 4 часов"},
+		{"каждые", "укажите интервал"},
+		{"каждые 2 часа", "не чаще раза в 4 часа"},
+		{"каждые 2 дня", "больше суток"},
+		{"каждые 6 часов каждые 8 часов", "несколько раз"},
+		{"каждые 6 часов в 9 и 18", "и время"},
+		{"с 8", "не указан конец окна"},
+		{"в 10 с 10 до 10", "пустое"},
+		{"в 23 с 8 до 20", "не попадает в окно"},
+		{"в 9 с разбросом", "укажите разброс"},
+		{"в 9 с разбросом 2 часа", "больше 60 минут"},
+		{"в 9 ±0 мин", "больше нуля"},
+		{"в 9 ±5 мин ±10 мин", "разброс указан несколько раз"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, err := Parse(tt.phrase)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %+v, want error", tt.phrase, got)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error %q, want it to mention %q", tt.phrase, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+// ErrRaiseInProgress возвращается, если резюме уже поднимается в данный момент
+var ErrRaiseInProgress = errors.New("raise already in progress")
+
+// OneOffRaise - разовый подъем резюме в заданный момент.
+// Выполняется независимо от регулярного расписания и пауз, после чего удаляется.
+type OneOffRaise struct {
+	ID       string    `json:"id"`
+	Account  string    `json:"account,omitempty"`
+	Title    string    `json:"title"`
+	ResumeID string    `json:"resume_id"`
+	At       time.Time `json:"at"`
+}
+
+// RaiseNow немедленно поднимает резюме аккаунта, не затрагивая регулярное расписание
+func (s *Scheduler) RaiseNow(account, title, resumeID string) (int, error) {
+	s.mutex.Lock()
+	if s.running[title] {
+		s.mutex.Unlock()
+		return 0, ErrRaiseInProgress
+	}
+	s.running[title] = true
+	s.mutex.Unlock()
+
+	defer s.finishRaise(title)
+	return s.raise(account, title, resumeID, 1)
+}
+
+// AddOneOff планирует разовый подъем резюме
+func (s *Scheduler) AddOneOff(account, title, resumeID string, at time.Time) OneOffRaise {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	oneOff := OneOffRaise{
+		ID:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		Account:  account,
+		Title:    title,
+		ResumeID: resumeID,
+		At:       at,
+	}
+	s.oneOffs = append(s.oneOffs, oneOff)
+	return oneOff
+}
+
+// RestoreOneOff добавляет сохраненный разовый подъем
+func (s *Scheduler) RestoreOneOff(oneOff OneOffRaise) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.oneOffs = append(s.oneOffs, oneOff)
+}
+
+// RemoveOneOff отменяет разовый подъем
+func (s *Scheduler) RemoveOneOff(id string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, oneOff := range s.oneOffs {
+		if oneOff.ID == id {
+			s.oneOffs = append(s.oneOffs[:i], s.oneOffs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// GetOneOffs возвращает запланированные разовые подъемы по возрастанию времени
+func (s *Scheduler) GetOneOffs() []OneOffRaise {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := make([]OneOffRaise, len(s.oneOffs))
+	copy(result, s.oneOffs)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].At.Before(result[j].At)
+	})
+	return result
+}
+
+// checkOneOffs запускает наступившие разовые подъемы и удаляет их из списка.
+// Вызывается под блокировкой.
+func (s *Scheduler) checkOneOffs(now time.Time) {
+	pending := s.oneOffs[:0]
+	consumed := false
+
+	for _, oneOff := range s.oneOffs {
+		// Если резюме сейчас поднимается, откладываем до следующей проверки
+		if now.Before(oneOff.At) || s.running[oneOff.Title] {
+			pending = append(pending, oneOff)
+			continue
+		}
+
+		s.running[oneOff.Title] = true
+		consumed = true
+		go s.raiseOneOffAsync(oneOff)
+	}
+
+	s.oneOffs = pending
+	if consumed {
+		go s.save()
+	}
+}
+
+func (s *Scheduler) raiseOneOffAsync(oneOff OneOffRaise) {
+	defer s.finishRaise(oneOff.Title)
+
+	code, err := s.raise(oneOff.Account, oneOff.Title, oneOff.ResumeID, 1)
+	event := notify.Event{
+		Type:        notify.EventOneOffRaised,
+		Severity:    notify.SeverityInfo,
+		Account:     oneOff.Account,
+		Resume:      oneOff.Title,
+		ResumeID:    oneOff.ResumeID,
+		Status:      code,
+		ScheduledAt: oneOff.At,
+	}
+	if err != nil || (code != 200 && code != 409) {
+		event.Severity = notify.SeverityWarning
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.publish(event)
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+// PauseResume приостанавливает автоподъем резюме.
+// Нулевой until означает паузу до ручного возобновления.
+func (s *Scheduler) PauseResume(title string, until time.Time) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedule, exists := s.schedules[title]
+	if !exists {
+		return false
+	}
+	schedule.Paused = true
+	schedule.PausedUntil = until
+	schedule.Attempt = 0
+	schedule.RetryAt = time.Time{}
+	s.schedules[title] = schedule
+	return true
+}
+
+// UnpauseResume возобновляет автоподъем резюме
+func (s *Scheduler) UnpauseResume(title string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.schedules[title]; !exists {
+		return false
+	}
+	s.unpauseLocked(title, time.Now())
+	return true
+}
+
+// PauseAll включает глобальную паузу (режим отпуска).
+// Нулевой until означает паузу до ручного возобновления.
+func (s *Scheduler) PauseAll(until time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.paused = true
+	s.pausedUntil = until
+}
+
+// UnpauseAll снимает глобальную паузу
+func (s *Scheduler) UnpauseAll() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.unpauseAllLocked(time.Now())
+}
+
+// GetPause возвращает состояние глобальной паузы
+func (s *Scheduler) GetPause() (bool, time.Time) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.paused, s.pausedUntil
+}
+
+// checkGlobalPause сообщает, действует ли глобальная пауза, и снимает ее по истечении срока.
+// Вызывается под блокировкой.
+func (s *Scheduler) checkGlobalPause(now time.Time) bool {
+	if !s.paused {
+		return false
+	}
+	if s.pausedUntil.IsZero() || now.Before(s.pausedUntil) {
+		return true
+	}
+
+	s.unpauseAllLocked(now)
+	go s.save()
+	go s.publish(notify.Event{Type: notify.EventVacationEnded, Severity: notify.SeverityInfo})
+	return false
+}
+
+// checkResumePause сообщает, стоит ли резюме на паузе, и снимает ее по истечении срока.
+// Вызывается под блокировкой.
+func (s *Scheduler) checkResumePause(title string, now time.Time) bool {
+	schedule := s.schedules[title]
+	if !schedule.Paused {
+		return false
+	}
+	if schedule.PausedUntil.IsZero() || now.Before(schedule.PausedUntil) {
+		return true
+	}
+
+	s.unpauseLocked(title, now)
+	go s.save()
+	go s.publish(notify.Event{
+		Type:     notify.EventResumePauseEnded,
+		Severity: notify.SeverityInfo,
+		Account:  schedule.Account,
+		Resume:   title,
+		ResumeID: schedule.ResumeID,
+	})
+	return false
+}
+
+func (s *Scheduler) unpauseAllLocked(now time.Time) {
+	s.paused = false
+	s.pausedUntil = time.Time{}
+	for title := range s.schedules {
+		s.skipMissedRuns(title, now)
+	}
+}
+
+func (s *Scheduler) unpauseLocked(title string, now time.Time) {
+	schedule := s.schedules[title]
+	schedule.Paused = false
+	schedule.PausedUntil = time.Time{}
+	s.schedules[title] = schedule
+	s.skipMissedRuns(title, now)
+}
+
+// skipMissedRuns переносит пропущенный за время паузы запуск на ближайший
+// плановый и сбрасывает повторы, чтобы после возобновления не было внепланового подъема.
+// Перенесенный запуск учитывает окна, дни недели и разброс.
+func (s *Scheduler) skipMissedRuns(title string, now time.Time) {
+	schedule := s.schedules[title]
+	schedule.Attempt = 0
+	schedule.RetryAt = time.Time{}
+	if !schedule.NextRun.After(now) {
+		for !schedule.NextRun.After(now) {
+			schedule.NextRun = schedule.nextAfter(schedule.NextRun)
+		}
+		schedule.NextRun = schedule.adjust(schedule.NextRun)
+	}
+	s.schedules[title] = schedule
+}
@@ -8,41 +8,211 @@ import (
 
 	"github.com/robfig/cron/v3"
 	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
 )
 
 type ResumeSchedule struct {
-	ResumeID  string    `json:"resume_id"`
-	Hour      int       `json:"hour"`
-	Minute    int       `json:"minute"`
-	NextRun   time.Time `json:"next_run"`
-	LastRun   time.Time `json:"last_run"`
+	Account  string    `json:"account,omitempty"` // пустое значение - аккаунт по умолчанию
+	ResumeID string    `json:"resume_id"`
+	Hour     int       `json:"hour"`
+	Minute   int       `json:"minute"`
+	NextRun  time.Time `json:"next_run"`
+	LastRun  time.Time `json:"last_run"`
+	Attempt  int       `json:"attempt"`
+	RetryAt  time.Time `json:"retry_at"`
+	// Retry - политика повторов этого резюме; nil - общая политика из RETRY_*
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// LastStatus - код последнего ответа hh.ru на подъем, чтобы заметить его изменение
+	LastStatus int `json:"last_status,omitempty"`
+
+	Paused      bool      `json:"paused"`
+	PausedUntil time.Time `json:"paused_until"`
+
+	Interval time.Duration  `json:"interval,omitempty"`
+	Windows  []TimeWindow   `json:"windows,omitempty"`
+	Jitter   time.Duration  `json:"jitter,omitempty"`
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	Times    []int          `json:"times,omitempty"`
+}
+
+// RetryPolicy описывает повторные попытки подъема после неудачи.
+// Повторы не зависят от основного интервала подъема. Общая политика задается
+// переменными RETRY_*, у отдельного резюме ее можно переопределить.
+type RetryPolicy struct {
+	MaxAttempts  int           `json:"max_attempts"`
+	InitialDelay time.Duration `json:"initial_delay"`
+	MaxDelay     time.Duration `json:"max_delay"`
+	Multiplier   float64       `json:"multiplier"`
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: time.Minute,
+		MaxDelay:     30 * time.Minute,
+		Multiplier:   2,
+	}
+}
+
+// Delay возвращает задержку перед повтором после неудачной попытки attempt (с 1)
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := p.InitialDelay
+	for i := 1; i < attempt; i++ {
+		delay = time.Duration(float64(delay) * multiplier)
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// RaiseAttempt - запись об одной попытке подъема для журнала истории
+type RaiseAttempt struct {
+	Time     time.Time     `json:"time"`
+	Account  string        `json:"account,omitempty"`
+	Title    string        `json:"title"`
+	ResumeID string        `json:"resume_id"`
+	Status   int           `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Relogin  bool          `json:"relogin"`
+	Attempt  int           `json:"attempt"`
+}
+
+// Succeeded сообщает, что резюме поднято или уже было поднято недавно
+func (a RaiseAttempt) Succeeded() bool {
+	return a.Error == "" && (a.Status == 200 || a.Status == 409)
 }
 
-type NotificationHandler func(message string)
+// NotificationHandler получает события планировщика
+type NotificationHandler func(event notify.Event)
+
+type HistoryHandler func(attempt RaiseAttempt)
+
+// SaveHandler вызывается, когда планировщик сам изменил состояние,
+// которое нужно сохранить (подъем выполнен, пауза истекла и т.п.)
+type SaveHandler func()
 
 type Scheduler struct {
-	cron          *cron.Cron
-	schedules     map[string]ResumeSchedule
-	hhClient      *hh.Client
-	notifications bool
-	notifyHandler NotificationHandler
-	mutex         sync.RWMutex
+	cron           *cron.Cron
+	schedules      map[string]ResumeSchedule
+	running        map[string]bool
+	oneOffs        []OneOffRaise
+	paused         bool
+	pausedUntil    time.Time
+	retryPolicy    RetryPolicy
+	clients        map[string]*hh.Client
+	notifySettings notify.Settings
+	notifyHandler  NotificationHandler
+	historyHandler HistoryHandler
+	saveHandler    SaveHandler
+	sessionPolicy  SessionPolicy
+	sessions       map[string]*sessionState
+	sessionHandler SessionHandler
+	mutex          sync.RWMutex
 }
 
 func New(hhClient *hh.Client, timezone string) *Scheduler {
 	loc, _ := time.LoadLocation(timezone)
 	return &Scheduler{
-		cron:          cron.New(cron.WithLocation(loc)),
-		schedules:     make(map[string]ResumeSchedule),
-		hhClient:      hhClient,
-		notifications: true,
+		cron:           cron.New(cron.WithLocation(loc)),
+		schedules:      make(map[string]ResumeSchedule),
+		running:        make(map[string]bool),
+		retryPolicy:    DefaultRetryPolicy(),
+		sessionPolicy:  DefaultSessionPolicy(),
+		sessions:       make(map[string]*sessionState),
+		clients:        map[string]*hh.Client{"": hhClient},
+		notifySettings: notify.DefaultSettings(),
+	}
+}
+
+// AddAccount регистрирует клиент hh.ru дополнительного аккаунта.
+// Расписания с пустым Account поднимаются через клиент, переданный в New.
+func (s *Scheduler) AddAccount(name string, hhClient *hh.Client) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.clients[name] = hhClient
+}
+
+// ScheduleKey возвращает ключ расписания резюме: у аккаунта по умолчанию это название резюме,
+// у остальных - название с префиксом аккаунта, чтобы одинаковые названия не пересекались
+func ScheduleKey(account, title string) string {
+	if account == "" {
+		return title
+	}
+	return account + ": " + title
+}
+
+func (s *Scheduler) client(account string) (*hh.Client, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	hhClient, exists := s.clients[account]
+	if !exists {
+		return nil, fmt.Errorf("account %q is not configured", account)
+	}
+	return hhClient, nil
+}
+
+// SetRetryPolicy задает общую политику повторов для резюме без своей политики
+func (s *Scheduler) SetRetryPolicy(policy RetryPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.retryPolicy = policy
+}
+
+// GetRetryPolicy возвращает общую политику повторов
+func (s *Scheduler) GetRetryPolicy() RetryPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.retryPolicy
+}
+
+// RetryPolicyFor возвращает политику повторов расписания: его собственную или общую
+func (s *Scheduler) RetryPolicyFor(schedule ResumeSchedule) RetryPolicy {
+	if schedule.Retry != nil {
+		return *schedule.Retry
+	}
+	return s.GetRetryPolicy()
+}
+
+// SetResumeRetryPolicy задает политику повторов резюме; nil возвращает общую политику
+func (s *Scheduler) SetResumeRetryPolicy(title string, policy *RetryPolicy) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedule, exists := s.schedules[title]
+	if !exists {
+		return false
 	}
+	schedule.Retry = policy
+	s.schedules[title] = schedule
+	return true
 }
 
 func (s *Scheduler) SetNotificationHandler(handler NotificationHandler) {
 	s.notifyHandler = handler
 }
 
+// SetSaveHandler задает обработчик сохранения состояния планировщика
+func (s *Scheduler) SetSaveHandler(handler SaveHandler) {
+	s.saveHandler = handler
+}
+
+// SetHistoryHandler задает обработчик, получающий каждую попытку подъема
+func (s *Scheduler) SetHistoryHandler(handler HistoryHandler) {
+	s.historyHandler = handler
+}
+
 func (s *Scheduler) Start() {
 	// Добавляем задачу, которая выполняется каждую минуту
 	s.cron.AddFunc("* * * * *", func() {
@@ -55,23 +225,67 @@ func (s *Scheduler) Stop() {
 	s.cron.Stop()
 }
 
-func (s *Scheduler) AddResume(title, resumeID string, hour, minute int) {
+// AddResume добавляет расписание резюме аккаунта account под ключом title (см. ScheduleKey)
+func (s *Scheduler) AddResume(account, title, resumeID string, settings ScheduleSettings) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	now := time.Now()
-	nextRun := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
-	if nextRun.Before(now) {
-		nextRun = nextRun.Add(24 * time.Hour)
-	}
-
-	s.schedules[title] = ResumeSchedule{
+	schedule := ResumeSchedule{
+		Account:  account,
 		ResumeID: resumeID,
-		Hour:     hour,
-		Minute:   minute,
-		NextRun:  nextRun,
+		Hour:     settings.Hour,
+		Minute:   settings.Minute,
 		LastRun:  time.Time{},
+		Interval: settings.Interval,
+		Windows:  settings.Windows,
+		Jitter:   settings.Jitter,
+		Weekdays: settings.Weekdays,
+		Times:    settings.Times,
+	}
+	schedule.NextRun = schedule.firstRun(time.Now())
+
+	s.schedules[title] = schedule
+}
+
+// UpdateResume меняет параметры существующего расписания, сохраняя историю подъемов,
+// паузу и политику повторов резюме. Следующий запуск пересчитывается так, чтобы не вызвать
+// внеплановый подъем: он всегда в будущем и не раньше интервала после последнего подъема.
+func (s *Scheduler) UpdateResume(title string, settings ScheduleSettings) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedule, exists := s.schedules[title]
+	if !exists {
+		return false
+	}
+
+	schedule.Hour = settings.Hour
+	schedule.Minute = settings.Minute
+	schedule.Interval = settings.Interval
+	schedule.Windows = settings.Windows
+	schedule.Jitter = settings.Jitter
+	schedule.Weekdays = settings.Weekdays
+	schedule.Times = settings.Times
+	schedule.Attempt = 0
+	schedule.RetryAt = time.Time{}
+	schedule.NextRun = schedule.nextSlot(time.Now())
+
+	s.schedules[title] = schedule
+	return true
+}
+
+// Restore добавляет сохраненное расписание вместе с историей, паузой и идущей серией повторов.
+// Сохраненный следующий запуск остается, если он еще впереди; пропущенный за время простоя
+// переносится на ближайший слот расписания.
+func (s *Scheduler) Restore(title string, saved ResumeSchedule) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedule := saved
+	if now := time.Now(); !schedule.NextRun.After(now) {
+		schedule.NextRun = schedule.nextSlot(now)
 	}
+	s.schedules[title] = schedule
 }
 
 func (s *Scheduler) RemoveResume(title string) bool {
@@ -96,18 +310,24 @@ func (s *Scheduler) GetAll() map[string]ResumeSchedule {
 	return result
 }
 
-func (s *Scheduler) ToggleNotifications() bool {
+func (s *Scheduler) GetNotifySettings() notify.Settings {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.notifySettings.Clone()
+}
+
+// SetNotifySettings восстанавливает сохраненные настройки уведомлений
+func (s *Scheduler) SetNotifySettings(settings notify.Settings) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
-
-	s.notifications = !s.notifications
-	return s.notifications
+	s.notifySettings = settings.Clone()
 }
 
-func (s *Scheduler) GetNotificationsEnabled() bool {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	return s.notifications
+// UpdateNotifySettings изменяет настройки уведомлений
+func (s *Scheduler) UpdateNotifySettings(change func(settings *notify.Settings)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	change(&s.notifySettings)
 }
 
 func (s *Scheduler) checkAndRaiseResumes() {
@@ -116,38 +336,211 @@ func (s *Scheduler) checkAndRaiseResumes() {
 
 	now := time.Now()
 
-	for title, schedule := range s.schedules {
-		if now.After(schedule.NextRun) && now.Sub(schedule.LastRun) >= 4*time.Hour {
-			go s.raiseResumeAsync(title, schedule)
+	s.checkOneOffs(now)
+	s.checkSessions(now)
+
+	if s.checkGlobalPause(now) {
+		return
+	}
+
+	for title := range s.schedules {
+		if s.running[title] || s.checkResumePause(title, now) {
+			continue
 		}
+
+		schedule := s.schedules[title]
+
+		if schedule.Attempt > 0 {
+			// Идет серия повторов - ориентируемся только на RetryAt
+			if now.Before(schedule.RetryAt) {
+				continue
+			}
+		} else if !now.After(schedule.NextRun) || now.Sub(schedule.LastRun) < schedule.GetInterval() {
+			continue
+		}
+
+		s.running[title] = true
+		go s.raiseResumeAsync(title, schedule)
 	}
 }
 
 func (s *Scheduler) raiseResumeAsync(title string, schedule ResumeSchedule) {
-	code, err := s.hhClient.RaiseResume(schedule.ResumeID)
-	if err != nil {
-		log.Printf("Error raising resume %s: %v", title, err)
-		return
+	defer s.finishRaise(title)
+
+	policy := s.RetryPolicyFor(schedule)
+	attempt := schedule.Attempt + 1
+
+	code, err := s.raise(schedule.Account, title, schedule.ResumeID, attempt)
+	event := notify.Event{
+		Account:     schedule.Account,
+		Resume:      title,
+		ResumeID:    schedule.ResumeID,
+		Status:      code,
+		Attempt:     attempt,
+		MaxAttempts: policy.MaxAttempts,
 	}
+	s.checkStatusChange(title, event)
 
-	if code == 409 || code == 200 {
+	if err == nil && (code == 409 || code == 200) {
 		// Успешно или уже поднято недавно
 		s.updateScheduleNextRun(title)
-	} else {
-		// Попробуем переавторизоваться
-		if err := s.hhClient.Login(); err == nil {
-			code, err = s.hhClient.RaiseResume(schedule.ResumeID)
-			if err == nil && (code == 409 || code == 200) {
-				s.updateScheduleNextRun(title)
-			}
+		event.Type, event.Severity = notify.EventRaiseSucceeded, notify.SeverityInfo
+		s.publish(event)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error raising resume %s (attempt %d/%d): %v", title, attempt, policy.MaxAttempts, err)
+		event.Error = err.Error()
+	}
+
+	retryAt, outcome := s.scheduleRetry(title, attempt, policy)
+	switch outcome {
+	case retryGone:
+		log.Printf("Resume %s was removed from the schedule during the raise, no retry", title)
+		return
+	case retryExhausted:
+		event.Type, event.Severity = notify.EventRaiseFailed, notify.SeverityError
+		event.NextRun = s.getNextRun(title)
+		s.publish(event)
+		return
+	}
+
+	event.Type, event.Severity = notify.EventRaiseRetry, notify.SeverityWarning
+	event.RetryAt = retryAt
+	s.publish(event)
+}
+
+// checkStatusChange запоминает ответ hh.ru на подъем и сообщает, если он изменился
+func (s *Scheduler) checkStatusChange(title string, event notify.Event) {
+	if event.Status == 0 {
+		return
+	}
+
+	s.mutex.Lock()
+	schedule, exists := s.schedules[title]
+	previous := schedule.LastStatus
+	if exists {
+		schedule.LastStatus = event.Status
+		s.schedules[title] = schedule
+	}
+	s.mutex.Unlock()
+
+	if !exists || previous == 0 || previous == event.Status {
+		return
+	}
+
+	event.Type, event.Severity = notify.EventResumeStatusChanged, notify.SeverityInfo
+	if event.Status != 200 && event.Status != 409 {
+		event.Severity = notify.SeverityWarning
+	}
+	event.PreviousStatus = previous
+	event.Error = ""
+	s.publish(event)
+}
+
+// raise поднимает резюме, при отказе сервера переавторизуется и пробует еще раз.
+// Результат попытки передается в журнал истории.
+func (s *Scheduler) raise(account, title, resumeID string, attempt int) (code int, err error) {
+	record := RaiseAttempt{
+		Time:     time.Now(),
+		Account:  account,
+		Title:    title,
+		ResumeID: resumeID,
+		Attempt:  attempt,
+	}
+	defer func() {
+		record.Status = code
+		record.Latency = time.Since(record.Time)
+		if err != nil {
+			record.Error = err.Error()
 		}
+		if s.historyHandler != nil {
+			s.historyHandler(record)
+		}
+	}()
+
+	hhClient, err := s.client(account)
+	if err != nil {
+		return 0, err
+	}
+
+	code, err = hhClient.RaiseResume(resumeID)
+	if err != nil || code == 409 || code == 200 {
+		return code, err
 	}
 
-	if s.notifications && s.notifyHandler != nil {
-		statusText := s.getStatusText(code)
-		text := fmt.Sprintf("📄 <b>%s</b>\n%s\n🕐 %s",
-			title, statusText, time.Now().Format("15:04:05"))
-		s.notifyHandler(text)
+	// Попробуем переавторизоваться
+	record.Relogin = true
+	if code == 401 || code == 403 {
+		s.sessionExpired(account, fmt.Sprintf("raise rejected with status %d", code))
+	}
+	if err := s.relogin(account, hhClient); err != nil {
+		return code, fmt.Errorf("re-login failed: %w", err)
+	}
+	return hhClient.RaiseResume(resumeID)
+}
+
+// retryOutcome - чем закончилась обработка неудачной попытки подъема
+type retryOutcome int
+
+const (
+	retryScheduled retryOutcome = iota // назначен повтор
+	retryExhausted                     // попытки исчерпаны
+	retryGone                          // расписание удалено, пока шел подъем
+)
+
+// scheduleRetry фиксирует неудачную попытку и назначает следующую.
+// Если попытки исчерпаны, серия сбрасывается до следующего планового запуска.
+func (s *Scheduler) scheduleRetry(title string, attempt int, policy RetryPolicy) (time.Time, retryOutcome) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	schedule, exists := s.schedules[title]
+	if !exists {
+		return time.Time{}, retryGone
+	}
+
+	now := time.Now()
+	if attempt >= policy.MaxAttempts {
+		schedule.Attempt = 0
+		schedule.RetryAt = time.Time{}
+		for !schedule.NextRun.After(now) {
+			schedule.NextRun = schedule.nextAfter(schedule.NextRun)
+		}
+		schedule.NextRun = schedule.adjust(schedule.NextRun)
+		s.schedules[title] = schedule
+		go s.save()
+		return time.Time{}, retryExhausted
+	}
+
+	schedule.Attempt = attempt
+	schedule.RetryAt = now.Add(policy.Delay(attempt))
+	s.schedules[title] = schedule
+	return schedule.RetryAt, retryScheduled
+}
+
+func (s *Scheduler) finishRaise(title string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.running, title)
+}
+
+func (s *Scheduler) getNextRun(title string) time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.schedules[title].NextRun
+}
+
+// publish передает событие обработчику уведомлений. Настройки уведомлений
+// (уровни и тихие часы) применяет только канал Telegram, остальные каналы
+// получают все события, отобранные их фильтрами.
+func (s *Scheduler) publish(event notify.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if s.notifyHandler != nil {
+		s.notifyHandler(event)
 	}
 }
 
@@ -157,22 +550,22 @@ func (s *Scheduler) updateScheduleNextRun(title string) {
 
 	if schedule, exists := s.schedules[title]; exists {
 		schedule.LastRun = time.Now()
-		schedule.NextRun = time.Now().Add(4 * time.Hour)
+		schedule.NextRun = schedule.adjust(schedule.nextAfter(schedule.LastRun))
+		schedule.Attempt = 0
+		schedule.RetryAt = time.Time{}
 		s.schedules[title] = schedule
+		go s.save()
 	}
 }
 
-func (s *Scheduler) getStatusText(code int) string {
-	switch code {
-	case 200:
-		return "✅ Резюме успешно поднято"
-	case 409:
-		return "⏳ Резюме уже поднималось недавно"
-	case 403:
-		return "❌ Доступ запрещен"
-	case 429:
-		return "⏸️ Слишком много запросов"
-	default:
-		return fmt.Sprintf("❌ Ошибка: %d", code)
+// Save сохраняет состояние планировщика через обработчик сохранения. Изменения из бота
+// сохраняются тем же путем, что и изменения самого планировщика, и записи не обгоняют друг друга.
+func (s *Scheduler) Save() {
+	s.save()
+}
+
+func (s *Scheduler) save() {
+	if s.saveHandler != nil {
+		s.saveHandler()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialDelay: time.Minute, MaxDelay: 30 * time.Minute, Multiplier: 2}
+
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first retry", policy, 1, time.Minute},
+		{"second retry doubles", policy, 2, 2 * time.Minute},
+		{"third retry doubles again", policy, 3, 4 * time.Minute},
+		{"last retry below cap", policy, 5, 16 * time.Minute},
+		{"capped at max delay", policy, 6, 30 * time.Minute},
+		{"stays at max delay", policy, 10, 30 * time.Minute},
+		{"initial delay above cap", RetryPolicy{InitialDelay: time.Hour, MaxDelay: 30 * time.Minute, Multiplier: 2}, 1, 30 * time.Minute},
+		{"no cap", RetryPolicy{InitialDelay: time.Minute, Multiplier: 3}, 4, 27 * time.Minute},
+		{"multiplier below 1 keeps delay", RetryPolicy{InitialDelay: time.Minute, MaxDelay: time.Hour, Multiplier: 0.5}, 4, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Minute, MaxDelay: 3 * time.Minute, Multiplier: 2}
+
+	tests := []struct {
+		attempt     int
+		wantOutcome retryOutcome
+		wantDelay   time.Duration // задержка повтора; 0 - повтора нет
+	}{
+		{1, retryScheduled, time.Minute},
+		{2, retryScheduled, 2 * time.Minute},
+		{3, retryExhausted, 0},
+	}
+
+	s := New(nil, "UTC")
+	s.AddResume("", "Go", "a1", ScheduleSettings{Hour: 9})
+
+	for _, tt := range tests {
+		now := time.Now()
+		retryAt, outcome := s.scheduleRetry("Go", tt.attempt, policy)
+		if outcome != tt.wantOutcome {
+			t.Fatalf("attempt %d: outcome = %d, want %d", tt.attempt, outcome, tt.wantOutcome)
+		}
+
+		schedule := s.GetAll()["Go"]
+		if tt.wantDelay == 0 {
+			if !retryAt.IsZero() || schedule.Attempt != 0 || !schedule.RetryAt.IsZero() {
+				t.Errorf("attempt %d: retries not reset: retryAt %s, schedule attempt %d at %s",
+					tt.attempt, retryAt, schedule.Attempt, schedule.RetryAt)
+			}
+			if !schedule.NextRun.After(now) {
+				t.Errorf("attempt %d: next run %s is not in the future", tt.attempt, schedule.NextRun)
+			}
+			continue
+		}
+
+		if delay := retryAt.Sub(now); delay < tt.wantDelay || delay > tt.wantDelay+time.Second {
+			t.Errorf("attempt %d: retry in %s, want %s", tt.attempt, delay, tt.wantDelay)
+		}
+		if schedule.Attempt != tt.attempt || !schedule.RetryAt.Equal(retryAt) {
+			t.Errorf("attempt %d: schedule attempt %d at %s, want %d at %s",
+				tt.attempt, schedule.Attempt, schedule.RetryAt, tt.attempt, retryAt)
+		}
+	}
+}
+
+func TestScheduleRetryRemovedSchedule(t *testing.T) {
+	s := New(nil, "UTC")
+
+	retryAt, outcome := s.scheduleRetry("Go", 1, DefaultRetryPolicy())
+	if outcome != retryGone || !retryAt.IsZero() {
+		t.Errorf("scheduleRetry for removed schedule = %s, %d, want zero time and retryGone", retryAt, outcome)
+	}
+}
+
+func TestSuccessResetsRetries(t *testing.T) {
+	s := New(nil, "UTC")
+	s.AddResume("", "Go", "a1", ScheduleSettings{Hour: 9})
+	if _, outcome := s.scheduleRetry("Go", 1, DefaultRetryPolicy()); outcome != retryScheduled {
+		t.Fatalf("outcome = %d, want retryScheduled", outcome)
+	}
+
+	before := time.Now()
+	s.updateScheduleNextRun("Go")
+
+	schedule := s.GetAll()["Go"]
+	if schedule.Attempt != 0 || !schedule.RetryAt.IsZero() {
+		t.Errorf("retries after success: attempt %d at %s, want none", schedule.Attempt, schedule.RetryAt)
+	}
+	if schedule.LastRun.Before(before) {
+		t.Errorf("last run %s was not updated", schedule.LastRun)
+	}
+	if schedule.NextRun.Before(schedule.LastRun.Add(schedule.GetInterval())) {
+		t.Errorf("next run %s is earlier than an interval after the last run %s", schedule.NextRun, schedule.LastRun)
+	}
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	s := New(nil, "UTC")
+	global := RetryPolicy{MaxAttempts: 5, InitialDelay: time.Minute, MaxDelay: time.Hour, Multiplier: 2}
+	s.SetRetryPolicy(global)
+	s.AddResume("", "Go", "a1", ScheduleSettings{Hour: 9})
+
+	if got := s.RetryPolicyFor(s.GetAll()["Go"]); got != global {
+		t.Errorf("policy without override = %+v, want global %+v", got, global)
+	}
+
+	custom := global
+	custom.MaxAttempts = 2
+	if !s.SetResumeRetryPolicy("Go", &custom) {
+		t.Fatal("SetResumeRetryPolicy returned false")
+	}
+	s.UpdateResume("Go", ScheduleSettings{Hour: 10})
+	if got := s.RetryPolicyFor(s.GetAll()["Go"]); got != custom {
+		t.Errorf("policy with override = %+v, want %+v", got, custom)
+	}
+
+	s.SetResumeRetryPolicy("Go", nil)
+	if got := s.RetryPolicyFor(s.GetAll()["Go"]); got != global {
+		t.Errorf("policy after reset = %+v, want global %+v", got, global)
+	}
+}
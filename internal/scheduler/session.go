@@ -0,0 +1,293 @@
+package scheduler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/hh"
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+// SessionPolicy описывает наблюдение за сессиями hh.ru
+type SessionPolicy struct {
+	CheckInterval time.Duration // как часто проверять сессию легким запросом
+	MaxAge        time.Duration // возраст токенов, после которого вход выполняется заранее
+	Prewarm       time.Duration // за сколько до подъема проверить сессию
+}
+
+// DefaultSessionPolicy возвращает параметры наблюдения за сессиями по умолчанию
+func DefaultSessionPolicy() SessionPolicy {
+	return SessionPolicy{
+		CheckInterval: 30 * time.Minute,
+		MaxAge:        72 * time.Hour,
+		Prewarm:       5 * time.Minute,
+	}
+}
+
+// SessionHandler вызывается после успешного входа планировщика, чтобы сохранить новые токены
+type SessionHandler func(account string, hhClient *hh.Client)
+
+// sessionState - состояние наблюдения за сессией одного аккаунта
+type sessionState struct {
+	checkedAt time.Time
+	checking  bool
+	// expired - об истечении сессии уже сообщено, а вход еще не выполнен
+	expired bool
+	// failed - последний вход не удался и администратор уже предупрежден
+	failed bool
+	// logins - время автоматических входов за последние loginLogRetention
+	logins []time.Time
+}
+
+// SessionStatus - состояние сессии аккаунта по наблюдениям планировщика
+type SessionStatus struct {
+	CheckedAt time.Time // последняя проверка сессии
+	Expired   bool      // hh.ru отклонил токены, вход еще не выполнен
+	Failed    bool      // последний вход не удался
+}
+
+// loginLogRetention - сколько помнить автоматические входы (для сводок за неделю)
+const loginLogRetention = 8 * 24 * time.Hour
+
+// SetSessionPolicy задает параметры наблюдения за сессиями
+func (s *Scheduler) SetSessionPolicy(policy SessionPolicy) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessionPolicy = policy
+}
+
+// SetSessionHandler задает обработчик успешного входа
+func (s *Scheduler) SetSessionHandler(handler SessionHandler) {
+	s.sessionHandler = handler
+}
+
+// checkSessions запускает проверку сессий, которым она положена по периоду
+// или перед ближайшим подъемом. Вызывается под блокировкой.
+func (s *Scheduler) checkSessions(now time.Time) {
+	for account, hhClient := range s.clients {
+		state := s.session(account)
+		if state.checking || !s.sessionCheckDue(account, state, now) {
+			continue
+		}
+
+		state.checking = true
+		go s.checkSession(account, hhClient)
+	}
+}
+
+// sessionCheckDue сообщает, пора ли проверить сессию аккаунта. Перед подъемом сессия
+// проверяется один раз, когда до него остается не больше Prewarm. Вызывается под блокировкой.
+func (s *Scheduler) sessionCheckDue(account string, state *sessionState, now time.Time) bool {
+	policy := s.sessionPolicy
+
+	if next, exists := s.nextRaise(account, now); exists && policy.Prewarm > 0 {
+		if next.Sub(now) <= policy.Prewarm && state.checkedAt.Before(next.Add(-policy.Prewarm)) {
+			return true
+		}
+	}
+
+	return policy.CheckInterval > 0 && now.Sub(state.checkedAt) >= policy.CheckInterval
+}
+
+// nextRaise возвращает ближайший будущий подъем аккаунта: плановый, повтор или разовый.
+// Вызывается под блокировкой.
+func (s *Scheduler) nextRaise(account string, now time.Time) (time.Time, bool) {
+	var next time.Time
+	consider := func(at time.Time) {
+		if at.After(now) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+
+	if !s.paused {
+		for _, schedule := range s.schedules {
+			if schedule.Account != account || schedule.Paused {
+				continue
+			}
+			if schedule.Attempt > 0 {
+				consider(schedule.RetryAt)
+			} else {
+				consider(schedule.NextRun)
+			}
+		}
+	}
+	for _, oneOff := range s.oneOffs {
+		if oneOff.Account == account {
+			consider(oneOff.At)
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// checkSession проверяет сессию аккаунта и при необходимости входит заново.
+// Устаревшие токены обновляются заранее, не дожидаясь отказа hh.ru.
+func (s *Scheduler) checkSession(account string, hhClient *hh.Client) {
+	defer s.finishSessionCheck(account)
+
+	policy := s.getSessionPolicy()
+	if loggedInAt := hhClient.LoginTime(); !loggedInAt.IsZero() && policy.MaxAge > 0 {
+		if age := time.Since(loggedInAt); age >= policy.MaxAge {
+			log.Printf("Session of account %q is %s old, logging in again", account, age.Round(time.Minute))
+			s.relogin(account, hhClient)
+			return
+		}
+	}
+
+	if _, err := hhClient.GetResumes(); err != nil {
+		log.Printf("Session check for account %q failed: %v; logging in again", account, err)
+		if errors.Is(err, hh.ErrSessionExpired) {
+			s.sessionExpired(account, err.Error())
+		}
+		s.relogin(account, hhClient)
+		return
+	}
+	s.sessionRestored(account)
+}
+
+// relogin входит в аккаунт заново; о неудаче администратор узнает один раз,
+// пока вход снова не пройдет успешно
+func (s *Scheduler) relogin(account string, hhClient *hh.Client) error {
+	if err := hhClient.Login(); err != nil {
+		log.Printf("Re-login for account %q failed: %v", account, err)
+		if s.markSessionFailed(account) {
+			s.publish(notify.Event{
+				Type:     notify.EventLoginFailed,
+				Severity: notify.SeverityError,
+				Account:  account,
+				Error:    err.Error(),
+			})
+		}
+		return err
+	}
+	s.loggedIn(account, hhClient)
+	return nil
+}
+
+// Relogin входит в аккаунт заново по просьбе пользователя (/login или кнопкой из уведомления)
+func (s *Scheduler) Relogin(account string) error {
+	hhClient, err := s.client(account)
+	if err != nil {
+		return err
+	}
+	return s.relogin(account, hhClient)
+}
+
+// sessionExpired сообщает, что hh.ru отклонил токены аккаунта; одновременные отказы
+// (например, нескольких подъемов в одну минуту) дают одно событие
+func (s *Scheduler) sessionExpired(account, reason string) {
+	s.mutex.Lock()
+	state := s.session(account)
+	reported := state.expired
+	state.expired = true
+	s.mutex.Unlock()
+
+	if !reported {
+		s.publish(notify.Event{
+			Type:     notify.EventSessionExpired,
+			Severity: notify.SeverityWarning,
+			Account:  account,
+			Error:    reason,
+		})
+	}
+}
+
+// loggedIn сохраняет токены после успешного входа планировщика
+func (s *Scheduler) loggedIn(account string, hhClient *hh.Client) {
+	log.Printf("Logged in to account %q", account)
+	s.recordLogin(account, time.Now())
+	s.sessionRestored(account)
+	if s.sessionHandler != nil {
+		s.sessionHandler(account, hhClient)
+	}
+}
+
+func (s *Scheduler) recordLogin(account string, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.session(account)
+	for len(state.logins) > 0 && at.Sub(state.logins[0]) > loginLogRetention {
+		state.logins = state.logins[1:]
+	}
+	state.logins = append(state.logins, at)
+}
+
+// SessionStatus возвращает состояние сессии аккаунта, не обращаясь к hh.ru
+func (s *Scheduler) SessionStatus(account string) SessionStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	state, exists := s.sessions[account]
+	if !exists {
+		return SessionStatus{}
+	}
+	return SessionStatus{CheckedAt: state.checkedAt, Expired: state.expired, Failed: state.failed}
+}
+
+// LoginCount возвращает число автоматических входов во все аккаунты после since.
+// Входы помнятся с запуска бота, но не дольше loginLogRetention.
+func (s *Scheduler) LoginCount(since time.Time) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, state := range s.sessions {
+		for _, at := range state.logins {
+			if at.After(since) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// sessionRestored снимает отметку о неудачном входе и сообщает о восстановлении
+func (s *Scheduler) sessionRestored(account string) {
+	s.mutex.Lock()
+	state := s.session(account)
+	failed := state.failed
+	state.failed, state.expired = false, false
+	s.mutex.Unlock()
+
+	if failed {
+		s.publish(notify.Event{Type: notify.EventLoginRestored, Severity: notify.SeverityInfo, Account: account})
+	}
+}
+
+// markSessionFailed отмечает неудачный вход; возвращает true, если это первая неудача подряд
+func (s *Scheduler) markSessionFailed(account string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.session(account)
+	first := !state.failed
+	state.failed = true
+	return first
+}
+
+func (s *Scheduler) finishSessionCheck(account string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := s.session(account)
+	state.checking = false
+	state.checkedAt = time.Now()
+}
+
+// session возвращает состояние сессии аккаунта. Вызывается под блокировкой.
+func (s *Scheduler) session(account string) *sessionState {
+	state, exists := s.sessions[account]
+	if !exists {
+		state = &sessionState{}
+		s.sessions[account] = state
+	}
+	return state
+}
+
+func (s *Scheduler) getSessionPolicy() SessionPolicy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.sessionPolicy
+}
@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultInterval - интервал подъема по умолчанию; чаще hh.ru поднимать не дает
+const DefaultInterval = 4 * time.Hour
+
+// TimeWindow - промежуток суток, в который разрешен подъем.
+// Границы задаются в минутах от начала суток, окно может переходить через полночь.
+type TimeWindow struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// ParseTimeWindow разбирает окно в формате ЧЧ:ММ-ЧЧ:ММ
+func ParseTimeWindow(text string) (TimeWindow, error) {
+	parts := strings.Split(strings.ReplaceAll(text, "–", "-"), "-")
+	if len(parts) != 2 {
+		return TimeWindow{}, fmt.Errorf("invalid time window: %s", text)
+	}
+
+	from, err := parseClock(parts[0])
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	to, err := parseClock(parts[1])
+	if err != nil {
+		return TimeWindow{}, err
+	}
+	if from == to {
+		return TimeWindow{}, fmt.Errorf("empty time window: %s", text)
+	}
+	return TimeWindow{From: from, To: to}, nil
+}
+
+func parseClock(text string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time: %s", strings.TrimSpace(text))
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains сообщает, попадает ли момент t в окно
+func (w TimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.From < w.To {
+		return minute >= w.From && minute < w.To
+	}
+	return minute >= w.From || minute < w.To
+}
+
+func (w TimeWindow) String() string {
+	return fmt.Sprintf("%02d:%02d–%02d:%02d", w.From/60, w.From%60, w.To/60, w.To%60)
+}
+
+// ScheduleSettings - настраиваемые пользователем параметры расписания резюме
+type ScheduleSettings struct {
+	Hour     int
+	Minute   int
+	Interval time.Duration
+	Windows  []TimeWindow
+	Jitter   time.Duration
+	Weekdays []time.Weekday // пустой список - каждый день
+	Times    []int          // фиксированное время подъемов (минуты от начала суток) вместо интервала
+}
+
+// TimesOfDay возвращает фиксированное время подъемов или время первого подъема
+func (ss ScheduleSettings) TimesOfDay() []int {
+	if len(ss.Times) > 0 {
+		return ss.Times
+	}
+	return []int{ss.Hour*60 + ss.Minute}
+}
+
+// GetInterval возвращает интервал подъема с учетом значения по умолчанию
+func (ss ScheduleSettings) GetInterval() time.Duration {
+	if ss.Interval <= 0 {
+		return DefaultInterval
+	}
+	return ss.Interval
+}
+
+// DayPlan возвращает время подъемов в течение суток (в минутах от начала суток):
+// от времени первого подъема с шагом интервала, с учетом окон
+func (ss ScheduleSettings) DayPlan() []int {
+	step := int(ss.GetInterval() / time.Minute)
+	if step <= 0 {
+		step = int(DefaultInterval / time.Minute)
+	}
+
+	candidates := ss.Times
+	if len(candidates) == 0 {
+		for offset := 0; offset < 24*60; offset += step {
+			candidates = append(candidates, (ss.Hour*60+ss.Minute+offset)%(24*60))
+		}
+	}
+
+	schedule := ResumeSchedule{Windows: ss.Windows}
+	var plan []int
+	for _, minute := range candidates {
+		at := time.Date(2000, 1, 1, minute/60, minute%60, 0, 0, time.UTC)
+		if schedule.inWindows(at) {
+			plan = append(plan, minute)
+		}
+	}
+	sort.Ints(plan)
+	return plan
+}
+
+// Settings возвращает настраиваемые параметры расписания
+func (rs ResumeSchedule) Settings() ScheduleSettings {
+	return ScheduleSettings{
+		Hour:     rs.Hour,
+		Minute:   rs.Minute,
+		Interval: rs.Interval,
+		Windows:  rs.Windows,
+		Jitter:   rs.Jitter,
+		Weekdays: rs.Weekdays,
+		Times:    rs.Times,
+	}
+}
+
+// GetInterval возвращает интервал подъема с учетом значения по умолчанию
+func (rs ResumeSchedule) GetInterval() time.Duration {
+	return rs.Settings().GetInterval()
+}
+
+// firstRun возвращает ближайшее наступление времени первого подъема (ЧЧ:ММ)
+// или ближайшее из фиксированных времен
+func (rs ResumeSchedule) firstRun(now time.Time) time.Time {
+	if len(rs.Times) > 0 {
+		return rs.adjust(rs.nextTimeOfDay(now.Add(-time.Nanosecond)))
+	}
+
+	nextRun := time.Date(now.Year(), now.Month(), now.Day(), rs.Hour, rs.Minute, 0, 0, now.Location())
+	if nextRun.Before(now) {
+		nextRun = nextRun.Add(24 * time.Hour)
+	}
+	return rs.adjust(nextRun)
+}
+
+// nextAfter возвращает следующий плановый момент после t без учета окон и дней недели:
+// ближайшее фиксированное время или t плюс интервал
+func (rs ResumeSchedule) nextAfter(t time.Time) time.Time {
+	if len(rs.Times) > 0 {
+		return rs.nextTimeOfDay(t)
+	}
+	return t.Add(rs.GetInterval())
+}
+
+// nextTimeOfDay возвращает ближайшее из фиксированных времен строго после t
+func (rs ResumeSchedule) nextTimeOfDay(t time.Time) time.Time {
+	var nearest time.Time
+	for _, minute := range rs.Times {
+		at := time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+		if !at.After(t) {
+			at = at.AddDate(0, 0, 1)
+		}
+		if nearest.IsZero() || at.Before(nearest) {
+			nearest = at
+		}
+	}
+	return nearest
+}
+
+// nextSlot возвращает ближайший после now слот сетки "время первого подъема + k*интервал"
+// (или ближайшее фиксированное время), не раньше чем через интервал после последнего подъема
+func (rs ResumeSchedule) nextSlot(now time.Time) time.Time {
+	interval := rs.GetInterval()
+	if len(rs.Times) > 0 {
+		slot := rs.nextTimeOfDay(now)
+		for !rs.LastRun.IsZero() && slot.Before(rs.LastRun.Add(interval)) {
+			slot = rs.nextTimeOfDay(slot)
+		}
+		return rs.adjust(slot)
+	}
+
+	anchor := time.Date(now.Year(), now.Month(), now.Day(), rs.Hour, rs.Minute, 0, 0, now.Location())
+
+	slot := anchor
+	if slot.After(now) {
+		steps := (slot.Sub(now) - 1) / interval
+		slot = slot.Add(-steps * interval)
+	} else {
+		steps := now.Sub(slot)/interval + 1
+		slot = slot.Add(steps * interval)
+	}
+
+	if !rs.LastRun.IsZero() {
+		for slot.Before(rs.LastRun.Add(interval)) {
+			slot = slot.Add(interval)
+		}
+	}
+	return rs.adjust(slot)
+}
+
+// adjust переносит момент подъема в ближайшее разрешенное окно и добавляет случайный разброс.
+// Если день недели не разрешен, подъем переносится на время первого подъема ближайшего разрешенного дня.
+func (rs ResumeSchedule) adjust(t time.Time) time.Time {
+	t = rs.fitWindows(t)
+	for i := 0; i < 7 && !rs.onWeekday(t); i++ {
+		nextDay := time.Date(t.Year(), t.Month(), t.Day()+1, rs.Hour, rs.Minute, 0, 0, t.Location())
+		t = rs.fitWindows(nextDay)
+	}
+
+	if rs.Jitter > 0 {
+		jittered := t.Add(time.Duration(rand.Int63n(int64(rs.Jitter) + 1)))
+		if rs.inWindows(jittered) && rs.onWeekday(jittered) {
+			return jittered
+		}
+	}
+	return t
+}
+
+func (rs ResumeSchedule) onWeekday(t time.Time) bool {
+	if len(rs.Weekdays) == 0 {
+		return true
+	}
+	for _, weekday := range rs.Weekdays {
+		if t.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (rs ResumeSchedule) inWindows(t time.Time) bool {
+	if len(rs.Windows) == 0 {
+		return true
+	}
+	for _, window := range rs.Windows {
+		if window.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitWindows возвращает t, если оно попадает в окно, иначе начало ближайшего окна
+func (rs ResumeSchedule) fitWindows(t time.Time) time.Time {
+	if rs.inWindows(t) {
+		return t
+	}
+
+	var nearest time.Time
+	for _, window := range rs.Windows {
+		start := time.Date(t.Year(), t.Month(), t.Day(), window.From/60, window.From%60, 0, 0, t.Location())
+		if !start.After(t) {
+			start = start.Add(24 * time.Hour)
+		}
+		if nearest.IsZero() || start.Before(nearest) {
+			nearest = start
+		}
+	}
+	return nearest
+}
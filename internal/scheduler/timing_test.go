@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// at возвращает момент января 2024 года в UTC; 1 января 2024 - понедельник
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.January, day, hour, minute, 0, 0, time.UTC)
+}
+
+var workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+func TestNextSlot(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule ResumeSchedule
+		now      time.Time
+		want     time.Time
+	}{
+		{"next grid slot after first raise time", ResumeSchedule{Hour: 9}, at(1, 12, 30), at(1, 13, 0)},
+		{"grid slot before first raise time", ResumeSchedule{Hour: 21}, at(1, 12, 30), at(1, 13, 0)},
+		{"exactly on a slot takes the next one", ResumeSchedule{Hour: 9}, at(1, 13, 0), at(1, 17, 0)},
+		{"grid keeps minutes", ResumeSchedule{Hour: 7, Minute: 15, Interval: 6 * time.Hour}, at(1, 8, 0), at(1, 13, 15)},
+		{"grid crosses midnight", ResumeSchedule{Hour: 10, Interval: 8 * time.Hour}, at(1, 19, 0), at(2, 2, 0)},
+		{"slot pushed past last run plus interval",
+			ResumeSchedule{Hour: 9, LastRun: at(1, 12, 0)}, at(1, 12, 30), at(1, 17, 0)},
+		{"recent last run with long interval",
+			ResumeSchedule{Hour: 0, Interval: 12 * time.Hour, LastRun: at(1, 11, 0)}, at(1, 11, 30), at(2, 0, 0)},
+		{"next fixed time", ResumeSchedule{Times: []int{9 * 60, 18 * 60}}, at(1, 10, 0), at(1, 18, 0)},
+		{"fixed time too close to last run",
+			ResumeSchedule{Times: []int{9 * 60, 18 * 60}, LastRun: at(1, 15, 0)}, at(1, 16, 0), at(2, 9, 0)},
+		{"slot moved into window", ResumeSchedule{Hour: 8, Windows: []TimeWindow{{From: 22 * 60, To: 6 * 60}}},
+			at(1, 12, 30), at(1, 22, 0)},
+		{"slot moved to allowed weekday", ResumeSchedule{Hour: 9, Interval: 24 * time.Hour, Weekdays: workdays},
+			at(5, 10, 0), at(8, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.nextSlot(tt.now); !got.Equal(tt.want) {
+				t.Errorf("nextSlot(%s) = %s, want %s", tt.now.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	dayWindow := []TimeWindow{{From: 8 * 60, To: 20 * 60}}
+	nightWindow := []TimeWindow{{From: 22 * 60, To: 6 * 60}}
+
+	tests := []struct {
+		name     string
+		schedule ResumeSchedule
+		t        time.Time
+		want     time.Time
+	}{
+		{"no restrictions", ResumeSchedule{Hour: 9}, at(1, 3, 0), at(1, 3, 0)},
+		{"inside window", ResumeSchedule{Windows: dayWindow}, at(1, 10, 0), at(1, 10, 0)},
+		{"window end is exclusive", ResumeSchedule{Windows: dayWindow}, at(1, 20, 0), at(2, 8, 0)},
+		{"after window moves past midnight", ResumeSchedule{Windows: dayWindow}, at(1, 21, 0), at(2, 8, 0)},
+		{"before window", ResumeSchedule{Windows: dayWindow}, at(1, 6, 0), at(1, 8, 0)},
+		{"inside overnight window after midnight", ResumeSchedule{Windows: nightWindow}, at(2, 3, 0), at(2, 3, 0)},
+		{"before overnight window", ResumeSchedule{Windows: nightWindow}, at(1, 7, 0), at(1, 22, 0)},
+		{"gap between windows", ResumeSchedule{Windows: []TimeWindow{{From: 8 * 60, To: 12 * 60}, {From: 14 * 60, To: 18 * 60}}},
+			at(1, 12, 30), at(1, 14, 0)},
+		{"weekend skipped", ResumeSchedule{Hour: 9, Weekdays: workdays}, at(6, 10, 0), at(8, 9, 0)},
+		{"window pushes onto weekend", ResumeSchedule{Hour: 9, Weekdays: workdays, Windows: dayWindow}, at(5, 21, 0), at(8, 9, 0)},
+		{"weekday and window together", ResumeSchedule{Hour: 9, Weekdays: []time.Weekday{time.Monday},
+			Windows: []TimeWindow{{From: 10 * 60, To: 12 * 60}}}, at(2, 10, 30), at(8, 10, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.adjust(tt.t); !got.Equal(tt.want) {
+				t.Errorf("adjust(%s) = %s, want %s", tt.t.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestAdjustJitter(t *testing.T) {
+	schedule := ResumeSchedule{Hour: 9, Jitter: 15 * time.Minute, Windows: []TimeWindow{{From: 8 * 60, To: 20 * 60}}}
+
+	for i := 0; i < 100; i++ {
+		got := schedule.adjust(at(1, 10, 0))
+		if got.Before(at(1, 10, 0)) || got.After(at(1, 10, 15)) {
+			t.Fatalf("adjust with jitter = %s, want within 15 minutes after 10:00", got.Format(time.RFC3339))
+		}
+		// Разброс не выводит подъем за окно
+		if got := schedule.adjust(at(1, 19, 55)); !got.Before(at(1, 20, 0)) {
+			t.Fatalf("jitter moved raise out of window: %s", got.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestUpdateResumeKeepsLastRun(t *testing.T) {
+	now := time.Now()
+	lastRun := now.Add(-time.Hour)
+
+	s := New(nil, "UTC")
+	s.Restore("Go", ResumeSchedule{ResumeID: "a1", Hour: 9, LastRun: lastRun, NextRun: now.Add(3 * time.Hour)})
+	if !s.UpdateResume("Go", ScheduleSettings{Hour: 9, Interval: 6 * time.Hour}) {
+		t.Fatal("UpdateResume returned false")
+	}
+
+	schedule := s.GetAll()["Go"]
+	if !schedule.LastRun.Equal(lastRun) {
+		t.Errorf("last run = %s, want %s", schedule.LastRun, lastRun)
+	}
+	if !schedule.NextRun.After(now) || schedule.NextRun.Before(lastRun.Add(6*time.Hour)) {
+		t.Errorf("next run = %s, want after now and not before %s", schedule.NextRun, lastRun.Add(6*time.Hour))
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"hh-ru-auto-resume-raising/internal/dialog"
+)
+
+const (
+	dialogsFile    = "dialogs.json"
+	onboardingFile = "onboarding.json"
+)
+
+// LoadDialogs загружает незавершенные диалоги бота
+func (s *Storage) LoadDialogs() (map[int64]dialog.Dialog, error) {
+	return s.loadDialogs(dialogsFile)
+}
+
+func (s *Storage) SaveDialogs(dialogs map[int64]dialog.Dialog) error {
+	return s.saveDialogs(dialogsFile, dialogs)
+}
+
+// LoadOnboarding загружает незавершенные подключения пользователей многопользовательского режима
+func (s *Storage) LoadOnboarding() (map[int64]dialog.Dialog, error) {
+	return s.loadDialogs(onboardingFile)
+}
+
+func (s *Storage) SaveOnboarding(dialogs map[int64]dialog.Dialog) error {
+	return s.saveDialogs(onboardingFile, dialogs)
+}
+
+func (s *Storage) loadDialogs(file string) (map[int64]dialog.Dialog, error) {
+	dialogsPath := filepath.Join(s.configPath, file)
+
+	if _, err := os.Stat(dialogsPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(dialogsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialogs map[int64]dialog.Dialog
+	if err := json.Unmarshal(data, &dialogs); err != nil {
+		return nil, err
+	}
+
+	return dialogs, nil
+}
+
+func (s *Storage) saveDialogs(file string, dialogs map[int64]dialog.Dialog) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(dialogs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Данные диалогов могут содержать логин hh.ru - доступ только владельцу
+	dialogsPath := filepath.Join(s.configPath, file)
+	return s.writeFile(dialogsPath, data, 0600)
+}
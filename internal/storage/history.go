@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"hh-ru-auto-resume-raising/internal/scheduler"
+)
+
+const (
+	historyFile      = "history.jsonl"
+	historyMaxSize   = 1 << 20 // 1 МБ на файл
+	historyMaxFiles  = 3       // сколько ротированных файлов хранить
+	historyRetention = 90 * 24 * time.Hour
+)
+
+// AppendHistory дописывает попытку подъема в журнал (по одной JSON записи на строку)
+func (s *Storage) AppendHistory(attempt scheduler.RaiseAttempt) error {
+	s.historyMutex.Lock()
+	defer s.historyMutex.Unlock()
+
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	if err := s.rotateHistory(); err != nil {
+		return fmt.Errorf("failed to rotate history: %w", err)
+	}
+
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.historyPath(0), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// LoadHistory возвращает попытки подъема за период хранения в хронологическом порядке.
+// Пустой resumeID означает все резюме, limit <= 0 - без ограничения.
+func (s *Storage) LoadHistory(resumeID string, limit int) ([]scheduler.RaiseAttempt, error) {
+	s.historyMutex.Lock()
+	defer s.historyMutex.Unlock()
+
+	cutoff := time.Now().Add(-historyRetention)
+	var attempts []scheduler.RaiseAttempt
+
+	// Читаем от самого старого ротированного файла к текущему
+	for i := historyMaxFiles; i >= 0; i-- {
+		file, err := os.Open(s.historyPath(i))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var attempt scheduler.RaiseAttempt
+			if err := json.Unmarshal(scanner.Bytes(), &attempt); err != nil {
+				continue
+			}
+			if attempt.Time.Before(cutoff) {
+				continue
+			}
+			if resumeID != "" && attempt.ResumeID != resumeID {
+				continue
+			}
+			attempts = append(attempts, attempt)
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if limit > 0 && len(attempts) > limit {
+		attempts = attempts[len(attempts)-limit:]
+	}
+	return attempts, nil
+}
+
+// rotateHistory сдвигает файлы журнала, когда текущий превышает лимит,
+// и удаляет ротированные файлы старше срока хранения
+func (s *Storage) rotateHistory() error {
+	for i := 1; i <= historyMaxFiles; i++ {
+		if info, err := os.Stat(s.historyPath(i)); err == nil && time.Since(info.ModTime()) > historyRetention {
+			if err := os.Remove(s.historyPath(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	info, err := os.Stat(s.historyPath(0))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() < historyMaxSize {
+		return nil
+	}
+
+	if err := os.Remove(s.historyPath(historyMaxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := historyMaxFiles - 1; i >= 0; i-- {
+		if err := os.Rename(s.historyPath(i), s.historyPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// historyPath возвращает путь к файлу журнала: 0 - текущий, N - N-й ротированный
+func (s *Storage) historyPath(index int) string {
+	if index == 0 {
+		return filepath.Join(s.configPath, historyFile)
+	}
+	return filepath.Join(s.configPath, fmt.Sprintf("%s.%d", historyFile, index))
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"hh-ru-auto-resume-raising/internal/outbox"
+)
+
+const (
+	outboxFile         = "telegram_outbox.json"
+	outboxFailuresFile = "telegram_failed.json"
+	// outboxFailuresMax - сколько неотправленных сообщений хранить
+	outboxFailuresMax = 100
+)
+
+// LoadOutbox загружает очередь сообщений Telegram
+func (s *Storage) LoadOutbox() ([]outbox.Message, error) {
+	s.outboxMutex.Lock()
+	defer s.outboxMutex.Unlock()
+	return s.loadOutboxMessages(outboxFile)
+}
+
+func (s *Storage) SaveOutbox(queue []outbox.Message) error {
+	s.outboxMutex.Lock()
+	defer s.outboxMutex.Unlock()
+	return s.saveOutboxMessages(outboxFile, queue)
+}
+
+// AppendOutboxFailure сохраняет неотправленное сообщение, оставляя последние записи
+func (s *Storage) AppendOutboxFailure(message outbox.Message) error {
+	s.outboxMutex.Lock()
+	defer s.outboxMutex.Unlock()
+
+	failures, err := s.loadOutboxMessages(outboxFailuresFile)
+	if err != nil {
+		return err
+	}
+	failures = append(failures, message)
+	if len(failures) > outboxFailuresMax {
+		failures = failures[len(failures)-outboxFailuresMax:]
+	}
+	return s.saveOutboxMessages(outboxFailuresFile, failures)
+}
+
+// LoadOutboxFailures возвращает неотправленные сообщения, от старых к новым
+func (s *Storage) LoadOutboxFailures() ([]outbox.Message, error) {
+	s.outboxMutex.Lock()
+	defer s.outboxMutex.Unlock()
+	return s.loadOutboxMessages(outboxFailuresFile)
+}
+
+func (s *Storage) loadOutboxMessages(file string) ([]outbox.Message, error) {
+	data, err := os.ReadFile(filepath.Join(s.configPath, file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []outbox.Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (s *Storage) saveOutboxMessages(file string, messages []outbox.Message) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(filepath.Join(s.configPath, file), data, 0644)
+}
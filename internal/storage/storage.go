@@ -2,9 +2,14 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"hh-ru-auto-resume-raising/internal/digest"
+	"hh-ru-auto-resume-raising/internal/notify"
 	"hh-ru-auto-resume-raising/internal/scheduler"
 )
 
@@ -12,15 +17,48 @@ const (
 	configDir     = "config"
 	tokensFile    = "tokens.json"
 	scheduleFile  = "schedule.json"
+	settingsFile  = "settings.json"
+	oneOffFile    = "oneoff.json"
+
+	// deferredFile - уведомления, отложенные до конца тихих часов
+	deferredFile = "notify_deferred.json"
 )
 
 type TokenData struct {
-	XSRF    string `json:"xsrf"`
-	HHToken string `json:"hhtoken"`
+	XSRF       string    `json:"xsrf"`
+	HHToken    string    `json:"hhtoken"`
+	LoggedInAt time.Time `json:"logged_in_at"` // когда получены токены; нужно для проактивного входа
+}
+
+// Settings - настройки бота, которые должны переживать перезапуск
+type Settings struct {
+	Paused        bool      `json:"paused"`
+	PausedUntil   time.Time `json:"paused_until"`
+	ActiveAccount string    `json:"active_account,omitempty"`
+
+	// Notifications - настройки уведомлений; nil в файлах старых версий - настройки по умолчанию
+	Notifications *notify.Settings `json:"notifications,omitempty"`
+	Digest        *digest.Settings `json:"digest,omitempty"`
+
+	Dashboard *Dashboard `json:"dashboard,omitempty"`
+}
+
+// Dashboard - режим живого статуса: в каждом чате одно закрепленное сообщение,
+// которое редактируется вместо отправки уведомлений
+type Dashboard struct {
+	Enabled  bool          `json:"enabled"`
+	Messages map[int64]int `json:"messages,omitempty"` // ID сообщения статуса по ID чата
 }
 
 type Storage struct {
-	configPath string
+	configPath    string
+	historyMutex  sync.Mutex
+	settingsMutex sync.Mutex
+	webhookMutex  sync.Mutex
+	outboxMutex   sync.Mutex
+
+	// fileMutex упорядочивает запись файлов хранилища
+	fileMutex sync.Mutex
 }
 
 func New() *Storage {
@@ -33,8 +71,47 @@ func (s *Storage) Init() error {
 	return os.MkdirAll(s.configPath, 0755)
 }
 
-func (s *Storage) LoadTokens() (*TokenData, error) {
-	tokensPath := filepath.Join(s.configPath, tokensFile)
+// writeFile атомарно заменяет файл хранилища: данные пишутся во временный файл рядом,
+// который затем переименовывается. Параллельные сохранения не перемешиваются, а при сбое
+// на диске остается прежняя версия файла целиком.
+func (s *Storage) writeFile(path string, data []byte, perm os.FileMode) error {
+	s.fileMutex.Lock()
+	defer s.fileMutex.Unlock()
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	defer os.Remove(tempPath) // после переименования файла уже нет, ошибка не важна
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+// tokensPath возвращает файл сессии аккаунта; у аккаунта по умолчанию (пустое имя) это tokens.json
+func (s *Storage) tokensPath(account string) string {
+	if account == "" {
+		return filepath.Join(s.configPath, tokensFile)
+	}
+	return filepath.Join(s.configPath, fmt.Sprintf("tokens.%s.json", account))
+}
+
+func (s *Storage) LoadTokens(account string) (*TokenData, error) {
+	tokensPath := s.tokensPath(account)
 	
 	if _, err := os.Stat(tokensPath); os.IsNotExist(err) {
 		return &TokenData{}, nil
@@ -53,14 +130,15 @@ func (s *Storage) LoadTokens() (*TokenData, error) {
 	return &tokens, nil
 }
 
-func (s *Storage) SaveTokens(xsrf, hhtoken string) error {
+func (s *Storage) SaveTokens(account, xsrf, hhtoken string, loggedInAt time.Time) error {
 	if err := s.Init(); err != nil {
 		return err
 	}
 
 	tokens := TokenData{
-		XSRF:    xsrf,
-		HHToken: hhtoken,
+		XSRF:       xsrf,
+		HHToken:    hhtoken,
+		LoggedInAt: loggedInAt,
 	}
 
 	data, err := json.Marshal(tokens)
@@ -68,8 +146,7 @@ func (s *Storage) SaveTokens(xsrf, hhtoken string) error {
 		return err
 	}
 
-	tokensPath := filepath.Join(s.configPath, tokensFile)
-	return os.WriteFile(tokensPath, data, 0644)
+	return s.writeFile(s.tokensPath(account), data, 0644)
 }
 
 func (s *Storage) LoadSchedule() (map[string]scheduler.ResumeSchedule, error) {
@@ -107,5 +184,122 @@ func (s *Storage) SaveSchedule(schedules map[string]scheduler.ResumeSchedule) er
 	}
 
 	schedulePath := filepath.Join(s.configPath, scheduleFile)
-	return os.WriteFile(schedulePath, data, 0644)
-}
\ No newline at end of file
+	return s.writeFile(schedulePath, data, 0644)
+}
+
+func (s *Storage) LoadSettings() (*Settings, error) {
+	settingsPath := filepath.Join(s.configPath, settingsFile)
+
+	if _, err := os.Stat(settingsPath); os.IsNotExist(err) {
+		return &Settings{}, nil
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+func (s *Storage) SaveSettings(settings *Settings) error {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	return s.saveSettings(settings)
+}
+
+func (s *Storage) saveSettings(settings *Settings) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	settingsPath := filepath.Join(s.configPath, settingsFile)
+	return s.writeFile(settingsPath, data, 0644)
+}
+
+// UpdateSettings атомарно читает настройки, применяет change и сохраняет результат,
+// чтобы параллельные изменения разных полей не затирали друг друга
+func (s *Storage) UpdateSettings(change func(settings *Settings)) error {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+
+	settings, err := s.LoadSettings()
+	if err != nil {
+		return err
+	}
+	change(settings)
+	return s.saveSettings(settings)
+}
+
+func (s *Storage) LoadOneOffs() ([]scheduler.OneOffRaise, error) {
+	oneOffPath := filepath.Join(s.configPath, oneOffFile)
+
+	if _, err := os.Stat(oneOffPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(oneOffPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var oneOffs []scheduler.OneOffRaise
+	if err := json.Unmarshal(data, &oneOffs); err != nil {
+		return nil, err
+	}
+
+	return oneOffs, nil
+}
+
+func (s *Storage) SaveOneOffs(oneOffs []scheduler.OneOffRaise) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(oneOffs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	oneOffPath := filepath.Join(s.configPath, oneOffFile)
+	return s.writeFile(oneOffPath, data, 0644)
+}
+
+// LoadDeferredNotifications загружает уведомления, отложенные до конца тихих часов
+func (s *Storage) LoadDeferredNotifications() ([]notify.Event, error) {
+	data, err := os.ReadFile(filepath.Join(s.configPath, deferredFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []notify.Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (s *Storage) SaveDeferredNotifications(events []notify.Event) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(filepath.Join(s.configPath, deferredFile), data, 0644)
+}
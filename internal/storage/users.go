@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	usersDir    = "users"
+	usersFile   = "users.json"
+	invitesFile = "invites.json"
+)
+
+// User - пользователь многопользовательского режима со своим аккаунтом hh.ru.
+// Пароль hh.ru хранится только зашифрованным (SealSecret) ключом USERS_KEY.
+type User struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Login          string    `json:"login"`
+	SealedPassword string    `json:"sealed_password"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+// Invite - одноразовый код приглашения
+type Invite struct {
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewForUser возвращает хранилище пользователя многопользовательского режима
+// (config/users/<id>), изолированное от данных остальных пользователей
+func NewForUser(userID int64) *Storage {
+	return &Storage{
+		configPath: filepath.Join(configDir, usersDir, strconv.FormatInt(userID, 10)),
+	}
+}
+
+func (s *Storage) LoadUsers() ([]User, error) {
+	usersPath := filepath.Join(s.configPath, usersFile)
+
+	if _, err := os.Stat(usersPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(usersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *Storage) SaveUsers(users []User) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Файл содержит логины и зашифрованные пароли hh.ru - доступ только владельцу
+	usersPath := filepath.Join(s.configPath, usersFile)
+	return s.writeFile(usersPath, data, 0600)
+}
+
+func (s *Storage) LoadInvites() ([]Invite, error) {
+	invitesPath := filepath.Join(s.configPath, invitesFile)
+
+	if _, err := os.Stat(invitesPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(invitesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var invites []Invite
+	if err := json.Unmarshal(data, &invites); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func (s *Storage) SaveInvites(invites []Invite) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(invites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	invitesPath := filepath.Join(s.configPath, invitesFile)
+	return s.writeFile(invitesPath, data, 0644)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"hh-ru-auto-resume-raising/internal/notify"
+)
+
+const (
+	webhookQueueFile      = "webhook_queue.json"
+	webhookDeliveriesFile = "webhook_deliveries.json"
+	// webhookDeliveriesMax - сколько завершенных доставок хранить в истории
+	webhookDeliveriesMax = 100
+)
+
+// LoadWebhookQueue загружает недоставленные события вебхука
+func (s *Storage) LoadWebhookQueue() ([]notify.Delivery, error) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+	return s.loadDeliveries(webhookQueueFile)
+}
+
+func (s *Storage) SaveWebhookQueue(queue []notify.Delivery) error {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+	return s.saveDeliveries(webhookQueueFile, queue)
+}
+
+// AppendWebhookDelivery добавляет завершенную доставку в историю, оставляя последние записи
+func (s *Storage) AppendWebhookDelivery(delivery notify.Delivery) error {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+
+	deliveries, err := s.loadDeliveries(webhookDeliveriesFile)
+	if err != nil {
+		return err
+	}
+	deliveries = append(deliveries, delivery)
+	if len(deliveries) > webhookDeliveriesMax {
+		deliveries = deliveries[len(deliveries)-webhookDeliveriesMax:]
+	}
+	return s.saveDeliveries(webhookDeliveriesFile, deliveries)
+}
+
+// LoadWebhookDeliveries возвращает историю доставок, от старых к новым
+func (s *Storage) LoadWebhookDeliveries() ([]notify.Delivery, error) {
+	s.webhookMutex.Lock()
+	defer s.webhookMutex.Unlock()
+	return s.loadDeliveries(webhookDeliveriesFile)
+}
+
+func (s *Storage) loadDeliveries(file string) ([]notify.Delivery, error) {
+	data, err := os.ReadFile(filepath.Join(s.configPath, file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []notify.Delivery
+	if err := json.Unmarshal(data, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *Storage) saveDeliveries(file string, deliveries []notify.Delivery) error {
+	if err := s.Init(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(deliveries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(filepath.Join(s.configPath, file), data, 0644)
+}
@@ -0,0 +1,227 @@
+package telegramfake
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// SendText подставляет сообщение пользователя в личный чат chatID. Текст, начинающийся
+// с "/", размечается как команда. Возвращает ID сообщения.
+func (s *Server) SendText(chatID int64, text string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	message := &Message{
+		ID:       s.nextMessageID,
+		ChatID:   chatID,
+		Text:     text,
+		Incoming: true,
+		Time:     time.Now(),
+	}
+	s.nextMessageID++
+	s.messages = append(s.messages, message)
+
+	update := message.tgMessage()
+	if strings.HasPrefix(text, "/") {
+		command := strings.SplitN(text, " ", 2)[0]
+		update.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Length: len(utf16.Encode([]rune(command)))}}
+	}
+	s.push(chatID, tgbotapi.Update{Message: &update}, message)
+	return message.ID
+}
+
+// Press подставляет нажатие кнопки с данными data под сообщением messageID.
+// Возвращает ID нажатия, по которому можно найти ответ бота в Answers.
+func (s *Server) Press(chatID int64, messageID int, data string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var target *Message
+	for _, message := range s.messages {
+		if message.ChatID == chatID && message.ID == messageID && !message.Deleted {
+			target = message
+		}
+	}
+	if target == nil {
+		return "", fmt.Errorf("message %d not found in chat %d", messageID, chatID)
+	}
+	return s.press(target, data), nil
+}
+
+// PressButton нажимает кнопку, в тексте которой есть text, под последним сообщением
+// бота в чате, где такая кнопка есть
+func (s *Server) PressButton(chatID int64, text string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		message := s.messages[i]
+		if message.ChatID != chatID || message.Deleted || message.Keyboard == nil {
+			continue
+		}
+		for _, row := range message.Keyboard.InlineKeyboard {
+			for _, button := range row {
+				if button.CallbackData != nil && strings.Contains(button.Text, text) {
+					return s.press(message, *button.CallbackData), nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("button %q not found in chat %d", text, chatID)
+}
+
+// press ставит в очередь нажатие кнопки. Вызывается под mutex.
+func (s *Server) press(message *Message, data string) string {
+	s.nextCallback++
+	id := fmt.Sprintf("callback-%d", s.nextCallback)
+	from := userOf(message.ChatID)
+	original := message.tgMessage()
+	s.push(message.ChatID, tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:           id,
+		From:         &from,
+		Message:      &original,
+		ChatInstance: fmt.Sprint(message.ChatID),
+		Data:         data,
+	}}, nil)
+	return id
+}
+
+// Inject ставит в очередь произвольное обновление; chatID - чат, ответы в котором
+// ждет WaitMessage
+func (s *Server) Inject(chatID int64, update tgbotapi.Update) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.push(chatID, update, nil)
+}
+
+// push нумерует обновление и отдает его боту. Вызывается под mutex.
+func (s *Server) push(chatID int64, update tgbotapi.Update, message *Message) {
+	update.UpdateID = s.nextUpdateID
+	s.nextUpdateID++
+	s.updates = append(s.updates, update)
+	s.touch(message)
+	s.injected[chatID] = s.version
+}
+
+// WaitMessage ждет сообщение бота в чате, отправленное или измененное после последнего
+// подставленного в этот чат обновления, для которого match возвращает true (nil - любое)
+func (s *Server) WaitMessage(chatID int64, timeout time.Duration, match func(Message) bool) (Message, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		s.mutex.Lock()
+		since := s.injected[chatID]
+		for _, message := range s.messages {
+			if message.ChatID != chatID || message.Incoming || message.Deleted || message.version <= since {
+				continue
+			}
+			if match == nil || match(*message) {
+				found := *message
+				s.mutex.Unlock()
+				return found, nil
+			}
+		}
+		changed := s.changed
+		s.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return Message{}, fmt.Errorf("no matching message in chat %d within %s", chatID, timeout)
+		case <-s.closed:
+			return Message{}, fmt.Errorf("server closed")
+		}
+	}
+}
+
+// WaitText ждет сообщение бота, в тексте которого есть text
+func (s *Server) WaitText(chatID int64, timeout time.Duration, text string) (Message, error) {
+	return s.WaitMessage(chatID, timeout, func(message Message) bool {
+		return strings.Contains(message.Text, text)
+	})
+}
+
+// Messages возвращает сообщения чата по порядку, включая удаленные и сообщения пользователя
+func (s *Server) Messages(chatID int64) []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var messages []Message
+	for _, message := range s.messages {
+		if message.ChatID == chatID {
+			messages = append(messages, *message)
+		}
+	}
+	return messages
+}
+
+// LastMessage возвращает последнее неудаленное сообщение бота в чате
+func (s *Server) LastMessage(chatID int64) (Message, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		message := s.messages[i]
+		if message.ChatID == chatID && !message.Incoming && !message.Deleted {
+			return *message, true
+		}
+	}
+	return Message{}, false
+}
+
+// Calls возвращает запросы бота к методу method; пустой method - все запросы
+func (s *Server) Calls(method string) []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Answers возвращает ответы бота на нажатия кнопок
+func (s *Server) Answers() []Answer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Answer(nil), s.answers...)
+}
+
+// Buttons возвращает тексты кнопок под сообщением построчно через " | "
+func (m Message) Buttons() []string {
+	if m.Keyboard == nil {
+		return nil
+	}
+	var rows []string
+	for _, row := range m.Keyboard.InlineKeyboard {
+		var texts []string
+		for _, button := range row {
+			texts = append(texts, button.Text)
+		}
+		rows = append(rows, strings.Join(texts, " | "))
+	}
+	return rows
+}
+
+// Button возвращает данные кнопки, в тексте которой есть text
+func (m Message) Button(text string) (string, bool) {
+	if m.Keyboard == nil {
+		return "", false
+	}
+	for _, row := range m.Keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil && strings.Contains(button.Text, text) {
+				return *button.CallbackData, true
+			}
+		}
+	}
+	return "", false
+}
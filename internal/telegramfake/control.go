@@ -0,0 +1,130 @@
+package telegramfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Управление имитацией по HTTP для сценариев вне Go (curl, скрипты):
+//
+//	POST /fake/send    {"chat_id": 1, "text": "/start"}          - сообщение пользователя
+//	POST /fake/press   {"chat_id": 1, "button": "Добавить"}      - нажатие кнопки по тексту
+//	POST /fake/press   {"chat_id": 1, "message_id": 5, "data": "x"} - нажатие по данным
+//	GET  /fake/wait?chat_id=1&text=Готово&timeout=10s            - ждать ответа бота
+//	GET  /fake/messages?chat_id=1                                 - сообщения чата
+//	GET  /fake/calls?method=setMyCommands                         - запросы бота
+//	POST /fake/fail    {"method": "sendMessage", "code": 429}     - ошибка следующего вызова
+
+// controlRequest - тело POST-запросов управления
+type controlRequest struct {
+	ChatID      int64  `json:"chat_id"`
+	Text        string `json:"text"`
+	Button      string `json:"button"`
+	MessageID   int    `json:"message_id"`
+	Data        string `json:"data"`
+	Method      string `json:"method"`
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+}
+
+// controlMessage - сообщение в ответах управления
+type controlMessage struct {
+	ID        int      `json:"id"`
+	Text      string   `json:"text"`
+	ParseMode string   `json:"parse_mode,omitempty"`
+	Buttons   []string `json:"buttons,omitempty"`
+	Keyboard  []string `json:"keyboard,omitempty"`
+	Incoming  bool     `json:"incoming,omitempty"`
+	Edits     int      `json:"edits,omitempty"`
+	Deleted   bool     `json:"deleted,omitempty"`
+	Pinned    bool     `json:"pinned,omitempty"`
+}
+
+func (s *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	var request controlRequest
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		request.ChatID, _ = strconv.ParseInt(r.URL.Query().Get("chat_id"), 10, 64)
+		request.Text = r.URL.Query().Get("text")
+		request.Method = r.URL.Query().Get("method")
+	}
+
+	switch r.URL.Path {
+	case "/fake/send":
+		writeControl(w, map[string]int{"message_id": s.SendText(request.ChatID, request.Text)})
+
+	case "/fake/press":
+		var id string
+		var err error
+		if request.Button != "" {
+			id, err = s.PressButton(request.ChatID, request.Button)
+		} else {
+			id, err = s.Press(request.ChatID, request.MessageID, request.Data)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeControl(w, map[string]string{"callback_id": id})
+
+	case "/fake/wait":
+		timeout, err := time.ParseDuration(r.URL.Query().Get("timeout"))
+		if err != nil {
+			timeout = 10 * time.Second
+		}
+		message, err := s.WaitText(request.ChatID, timeout, request.Text)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestTimeout)
+			return
+		}
+		writeControl(w, newControlMessage(message))
+
+	case "/fake/messages":
+		messages := []controlMessage{}
+		for _, message := range s.Messages(request.ChatID) {
+			messages = append(messages, newControlMessage(message))
+		}
+		writeControl(w, messages)
+
+	case "/fake/calls":
+		writeControl(w, s.Calls(request.Method))
+
+	case "/fake/fail":
+		s.FailNext(request.Method, request.Code, request.Description)
+		writeControl(w, map[string]bool{"ok": true})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newControlMessage(message Message) controlMessage {
+	result := controlMessage{
+		ID:        message.ID,
+		Text:      message.Text,
+		ParseMode: message.ParseMode,
+		Buttons:   message.Buttons(),
+		Incoming:  message.Incoming,
+		Edits:     message.Edits,
+		Deleted:   message.Deleted,
+		Pinned:    message.Pinned,
+	}
+	if message.ReplyKeyboard != nil {
+		for _, row := range message.ReplyKeyboard.Keyboard {
+			for _, button := range row {
+				result.Keyboard = append(result.Keyboard, button.Text)
+			}
+		}
+	}
+	return result
+}
+
+func writeControl(w http.ResponseWriter, value interface{}) {
+	writeJSON(w, http.StatusOK, value)
+}
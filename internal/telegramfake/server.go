@@ -0,0 +1,457 @@
+// Package telegramfake - имитация Telegram Bot API для проверки бота без Telegram.
+// Сервер запоминает отправленные ботом сообщения и клавиатуры, отдает через getUpdates
+// подставленные сообщения и нажатия кнопок и позволяет дождаться ответа бота:
+// так сценарии разговора проверяются целиком без сети.
+package telegramfake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Token - токен, с которым удобно запускать бота против имитации. Сервер принимает любой токен.
+const Token = "123456:fake"
+
+// BotUser - пользователь, которого getMe возвращает как бота
+var BotUser = tgbotapi.User{ID: 123456, IsBot: true, FirstName: "Fake", UserName: "fake_bot"}
+
+// maxPollTimeout - дольше этого getUpdates не ждет обновлений, какой бы timeout ни попросили
+const maxPollTimeout = 60 * time.Second
+
+// Message - сообщение в чате: отправленное ботом или подставленное от пользователя
+type Message struct {
+	ID            int
+	ChatID        int64
+	Text          string
+	ParseMode     string
+	Keyboard      *tgbotapi.InlineKeyboardMarkup // кнопки под сообщением
+	ReplyKeyboard *tgbotapi.ReplyKeyboardMarkup  // клавиатура вместо поля ввода
+	Silent        bool
+	Incoming      bool // сообщение пользователя, а не бота
+	Edits         int
+	Deleted       bool
+	Pinned        bool
+	Time          time.Time
+
+	version int // номер последнего изменения сервера, затронувшего сообщение
+}
+
+// Call - запрос бота к Bot API
+type Call struct {
+	Method string            `json:"method"`
+	Params map[string]string `json:"params"`
+	Time   time.Time         `json:"time"`
+}
+
+// Answer - ответ бота на нажатие кнопки
+type Answer struct {
+	CallbackID string
+	Text       string
+	ShowAlert  bool
+}
+
+// apiError - ошибка, которую сервер вернет на следующий вызов метода
+type apiError struct {
+	code        int
+	description string
+}
+
+// Server - имитация Bot API. Адрес из URL передается боту в TELEGRAM_API_URL.
+type Server struct {
+	server   *http.Server
+	listener net.Listener
+
+	messages      []*Message
+	nextMessageID int
+	updates       []tgbotapi.Update
+	nextUpdateID  int
+	nextCallback  int
+	calls         []Call
+	answers       []Answer
+	failures      map[string][]apiError
+	webhook       string
+
+	version  int           // растет при каждом изменении
+	injected map[int64]int // номер изменения на момент последнего обновления в чат
+	changed  chan struct{} // закрывается и заменяется при каждом изменении
+	closed   chan struct{}
+	mutex    sync.Mutex
+}
+
+// Start запускает сервер на адресе addr, например "127.0.0.1:0" - свободный порт
+func Start(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		listener:      listener,
+		nextMessageID: 1,
+		nextUpdateID:  1,
+		failures:      make(map[string][]apiError),
+		injected:      make(map[int64]int),
+		changed:       make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fake/", s.handleControl)
+	mux.HandleFunc("/", s.handleAPI)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Fake Telegram server stopped: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+// URL - адрес сервера для TELEGRAM_API_URL
+func (s *Server) URL() string {
+	return "http://" + s.listener.Addr().String()
+}
+
+// Close останавливает сервер и отпускает ждущие getUpdates
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+	s.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// FailNext заставляет следующий вызов method вернуть ошибку Bot API с кодом code.
+// Для кода 429 ответ содержит retry_after в 1 секунду.
+func (s *Server) FailNext(method string, code int, description string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures[method] = append(s.failures[method], apiError{code: code, description: description})
+}
+
+// handleAPI обрабатывает запросы /bot<token>/<method>
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "bot") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	method := parts[1]
+
+	if err := r.ParseMultipartForm(32 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		writeError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+		return
+	}
+	params := make(map[string]string, len(r.Form))
+	for key := range r.Form {
+		params[key] = r.Form.Get(key)
+	}
+
+	if method == "getUpdates" {
+		s.getUpdates(w, params)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls = append(s.calls, Call{Method: method, Params: params, Time: time.Now()})
+	if failures := s.failures[method]; len(failures) > 0 {
+		s.failures[method] = failures[1:]
+		writeFailure(w, failures[0])
+		return
+	}
+
+	result, err := s.call(method, params)
+	if err != nil {
+		writeFailure(w, *err)
+		return
+	}
+	writeResult(w, result)
+}
+
+// call выполняет метод Bot API. Вызывается под mutex.
+func (s *Server) call(method string, params map[string]string) (interface{}, *apiError) {
+	switch method {
+	case "getMe":
+		return BotUser, nil
+
+	case "sendMessage":
+		chatID, err := strconv.ParseInt(params["chat_id"], 10, 64)
+		if err != nil {
+			return nil, badRequest("chat not found")
+		}
+		if params["text"] == "" {
+			return nil, badRequest("message text is empty")
+		}
+		message := &Message{
+			ID:        s.nextMessageID,
+			ChatID:    chatID,
+			Text:      params["text"],
+			ParseMode: params["parse_mode"],
+			Silent:    params["disable_notification"] == "true",
+			Time:      time.Now(),
+		}
+		s.nextMessageID++
+		if err := setMarkup(message, params["reply_markup"]); err != nil {
+			return nil, err
+		}
+		s.messages = append(s.messages, message)
+		s.touch(message)
+		return message.tgMessage(), nil
+
+	case "editMessageText", "editMessageReplyMarkup":
+		message, err := s.target(params)
+		if err != nil {
+			return nil, err
+		}
+		text, parseMode := message.Text, message.ParseMode
+		if method == "editMessageText" {
+			text, parseMode = params["text"], params["parse_mode"]
+		}
+		// Как и Telegram, правка без reply_markup убирает кнопки
+		var keyboard tgbotapi.InlineKeyboardMarkup
+		if params["reply_markup"] != "" {
+			if err := json.Unmarshal([]byte(params["reply_markup"]), &keyboard); err != nil {
+				return nil, badRequest("can't parse reply keyboard markup JSON object")
+			}
+		}
+		if text == message.Text && sameKeyboard(message.Keyboard, &keyboard) {
+			return nil, badRequest("message is not modified: specified new message content and reply markup are exactly the same as a current content and reply markup of the message")
+		}
+		message.Text, message.ParseMode = text, parseMode
+		message.Keyboard = nil
+		if len(keyboard.InlineKeyboard) > 0 {
+			message.Keyboard = &keyboard
+		}
+		message.Edits++
+		s.touch(message)
+		return message.tgMessage(), nil
+
+	case "deleteMessage":
+		message, err := s.target(params)
+		if err != nil {
+			return nil, badRequest("message to delete not found")
+		}
+		message.Deleted = true
+		s.touch(message)
+		return true, nil
+
+	case "pinChatMessage", "unpinChatMessage":
+		message, err := s.target(params)
+		if err != nil {
+			return nil, err
+		}
+		message.Pinned = method == "pinChatMessage"
+		s.touch(message)
+		return true, nil
+
+	case "answerCallbackQuery":
+		s.answers = append(s.answers, Answer{
+			CallbackID: params["callback_query_id"],
+			Text:       params["text"],
+			ShowAlert:  params["show_alert"] == "true",
+		})
+		s.touch(nil)
+		return true, nil
+
+	case "setWebhook":
+		s.webhook = params["url"]
+		return true, nil
+
+	case "deleteWebhook":
+		s.webhook = ""
+		return true, nil
+
+	case "getWebhookInfo":
+		return tgbotapi.WebhookInfo{URL: s.webhook, PendingUpdateCount: len(s.updates)}, nil
+	}
+
+	// setMyCommands, deleteMyCommands, sendChatAction и прочее только записываются
+	return true, nil
+}
+
+// getUpdates отдает обновления начиная с offset и подтверждает предыдущие.
+// Если обновлений нет, ждет их до timeout секунд.
+func (s *Server) getUpdates(w http.ResponseWriter, params map[string]string) {
+	offset, _ := strconv.Atoi(params["offset"])
+	limit, _ := strconv.Atoi(params["limit"])
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	timeout, _ := strconv.Atoi(params["timeout"])
+	wait := time.Duration(timeout) * time.Second
+	if wait > maxPollTimeout {
+		wait = maxPollTimeout
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+
+	for {
+		s.mutex.Lock()
+		if s.webhook != "" {
+			s.mutex.Unlock()
+			writeFailure(w, apiError{code: http.StatusConflict, description: "Conflict: can't use getUpdates method while webhook is active; use deleteWebhook to delete the webhook first"})
+			return
+		}
+		pending := s.updates[:0]
+		for _, update := range s.updates {
+			if update.UpdateID >= offset {
+				pending = append(pending, update)
+			}
+		}
+		s.updates = pending
+		if len(pending) > 0 || wait <= 0 {
+			if len(pending) > limit {
+				pending = pending[:limit]
+			}
+			result := append([]tgbotapi.Update{}, pending...)
+			s.mutex.Unlock()
+			writeResult(w, result)
+			return
+		}
+		changed := s.changed
+		s.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			wait = 0
+		case <-s.closed:
+			writeResult(w, []tgbotapi.Update{})
+			return
+		}
+	}
+}
+
+// target находит сообщение по chat_id и message_id запроса. Вызывается под mutex.
+func (s *Server) target(params map[string]string) (*Message, *apiError) {
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	messageID, _ := strconv.Atoi(params["message_id"])
+	for _, message := range s.messages {
+		if message.ChatID == chatID && message.ID == messageID && !message.Deleted {
+			return message, nil
+		}
+	}
+	return nil, badRequest("message to edit not found")
+}
+
+// touch отмечает изменение и будит ожидающих. Вызывается под mutex.
+func (s *Server) touch(message *Message) {
+	s.version++
+	if message != nil {
+		message.version = s.version
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// setMarkup разбирает reply_markup нового сообщения: кнопки или клавиатуру
+func setMarkup(message *Message, markup string) *apiError {
+	if markup == "" {
+		return nil
+	}
+	var inline tgbotapi.InlineKeyboardMarkup
+	if err := json.Unmarshal([]byte(markup), &inline); err != nil {
+		return badRequest("can't parse reply keyboard markup JSON object")
+	}
+	if len(inline.InlineKeyboard) > 0 {
+		message.Keyboard = &inline
+		return nil
+	}
+	var reply tgbotapi.ReplyKeyboardMarkup
+	if err := json.Unmarshal([]byte(markup), &reply); err == nil && len(reply.Keyboard) > 0 {
+		message.ReplyKeyboard = &reply
+	}
+	return nil
+}
+
+// sameKeyboard сравнивает кнопки; nil и пустая клавиатура одинаковы
+func sameKeyboard(current, next *tgbotapi.InlineKeyboardMarkup) bool {
+	encode := func(keyboard *tgbotapi.InlineKeyboardMarkup) string {
+		if keyboard == nil || len(keyboard.InlineKeyboard) == 0 {
+			return ""
+		}
+		data, _ := json.Marshal(keyboard)
+		return string(data)
+	}
+	return encode(current) == encode(next)
+}
+
+func badRequest(description string) *apiError {
+	return &apiError{code: http.StatusBadRequest, description: "Bad Request: " + description}
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, tgbotapi.APIResponse{Ok: true, Result: data})
+}
+
+func writeFailure(w http.ResponseWriter, failure apiError) {
+	response := tgbotapi.APIResponse{ErrorCode: failure.code, Description: failure.description}
+	if failure.code == http.StatusTooManyRequests {
+		response.Parameters = &tgbotapi.ResponseParameters{RetryAfter: 1}
+		if response.Description == "" {
+			response.Description = "Too Many Requests: retry after 1"
+		}
+	}
+	writeJSON(w, failure.code, response)
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	writeJSON(w, code, tgbotapi.APIResponse{ErrorCode: code, Description: description})
+}
+
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write fake Telegram response: %v", err)
+	}
+}
+
+// tgMessage - сообщение в том виде, в каком его возвращает Bot API
+func (m *Message) tgMessage() tgbotapi.Message {
+	from := BotUser
+	if m.Incoming {
+		from = userOf(m.ChatID)
+	}
+	message := tgbotapi.Message{
+		MessageID:   m.ID,
+		From:        &from,
+		Date:        int(m.Time.Unix()),
+		Chat:        &tgbotapi.Chat{ID: m.ChatID, Type: "private"},
+		Text:        m.Text,
+		ReplyMarkup: m.Keyboard,
+	}
+	if m.Edits > 0 {
+		message.EditDate = int(time.Now().Unix())
+	}
+	return message
+}
+
+// userOf - пользователь личного чата chatID
+func userOf(chatID int64) tgbotapi.User {
+	return tgbotapi.User{ID: chatID, FirstName: "User", UserName: fmt.Sprintf("user%d", chatID), LanguageCode: "ru"}
+}
@@ -3,26 +3,216 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultAccount - имя аккаунта, заданного через HH_LOGIN/HH_PASSWORD
+const DefaultAccount = "main"
+
+// Account - учетные данные одного аккаунта hh.ru
+type Account struct {
+	Name     string
+	Login    string
+	Password string
+	Proxy    string
+}
+
+// Role - уровень доступа пользователя Telegram к боту
+type Role int
+
+const (
+	RoleNone     Role = iota
+	RoleViewer        // просмотр статуса, расписания и истории
+	RoleOperator      // управление расписанием и подъем резюме
+	RoleOwner         // все, включая авторизацию и учетные данные
+)
+
+// ParseRole разбирает название роли: owner, operator или viewer
+func ParseRole(name string) (Role, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "owner":
+		return RoleOwner, true
+	case "operator":
+		return RoleOperator, true
+	case "viewer":
+		return RoleViewer, true
+	}
+	return RoleNone, false
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleOwner:
+		return "owner"
+	case RoleOperator:
+		return "operator"
+	case RoleViewer:
+		return "viewer"
+	}
+	return "none"
+}
+
+// Allows сообщает, достаточно ли роли для действия, требующего роль required
+func (r Role) Allows(required Role) bool {
+	return r != RoleNone && r >= required
+}
+
 type Config struct {
-	TelegramToken string
-	AdminTG       int64
-	HHLogin       string
-	HHPassword    string
-	Timezone      string
-	Proxy         string
+	TelegramToken     string
+	AdminTG           int64 // владелец бота
+	Admins            map[int64]Role
+	HHLogin           string
+	HHPassword        string
+	Timezone          string
+	Proxy             string
+	RetryMaxAttempts  int
+	RetryInitialDelay time.Duration
+	RetryMaxDelay     time.Duration
+	Accounts          []Account // первый аккаунт - аккаунт по умолчанию
+	MultiUser         bool      // каждый разрешенный пользователь подключает свой аккаунт hh.ru
+	AllowedUsers      []int64   // пользователи, которым не нужен код приглашения
+	UsersKey          string    // ключ шифрования паролей hh.ru пользователей в config/users.json
+
+	// Наблюдение за сессией hh.ru: период проверки, возраст токенов для
+	// проактивного входа и сколько заранее проверять сессию перед подъемом
+	SessionCheckInterval time.Duration
+	SessionMaxAge        time.Duration
+	SessionPrewarm       time.Duration
+
+	Notify NotifyConfig // каналы уведомлений и их фильтры
+
+	Telegram TelegramConfig // адрес Bot API и режим вебхука
 }
 
 func Load() *Config {
-	return &Config{
-		TelegramToken: getEnv("TELEGRAM_TOKEN", ""),
-		AdminTG:       getEnvInt64("ADMIN_TG", 0),
-		HHLogin:       getEnv("HH_LOGIN", ""),
-		HHPassword:    getEnv("HH_PASSWORD", ""),
-		Timezone:      getEnv("TZ", "Europe/Moscow"),
-		Proxy:         getEnv("PROXY", "None"),
+	cfg := &Config{
+		TelegramToken:     getEnv("TELEGRAM_TOKEN", ""),
+		AdminTG:           getEnvInt64("ADMIN_TG", 0),
+		HHLogin:           getEnv("HH_LOGIN", ""),
+		HHPassword:        getEnv("HH_PASSWORD", ""),
+		Timezone:          getEnv("TZ", "Europe/Moscow"),
+		Proxy:             getEnv("PROXY", "None"),
+		RetryMaxAttempts:  int(getEnvInt64("RETRY_MAX_ATTEMPTS", 5)),
+		RetryInitialDelay: getEnvDuration("RETRY_INITIAL_DELAY", time.Minute),
+		RetryMaxDelay:     getEnvDuration("RETRY_MAX_DELAY", 30*time.Minute),
 	}
+	cfg.SessionCheckInterval = getEnvDuration("SESSION_CHECK_INTERVAL", 30*time.Minute)
+	cfg.SessionMaxAge = getEnvDuration("SESSION_MAX_AGE", 72*time.Hour)
+	cfg.SessionPrewarm = getEnvDuration("SESSION_PREWARM", 5*time.Minute)
+	cfg.Notify = loadNotify()
+	cfg.Telegram = loadTelegram()
+	cfg.Accounts = loadAccounts(cfg)
+	cfg.Admins = loadAdmins(getEnv("ADMINS", ""))
+	cfg.MultiUser = getEnvBool("MULTI_USER", false)
+	cfg.AllowedUsers = getEnvInt64List("ALLOWED_USERS")
+	cfg.UsersKey = getEnv("USERS_KEY", "")
+	return cfg
+}
+
+// loadAdmins разбирает список ADMINS=123:owner,456:operator,789:viewer.
+// Записи с неизвестной ролью пропускаются.
+func loadAdmins(value string) map[int64]Role {
+	admins := make(map[int64]Role)
+	for _, entry := range strings.Split(value, ",") {
+		idText, roleName, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSpace(idText), 10, 64)
+		if err != nil {
+			continue
+		}
+		if role, ok := ParseRole(roleName); ok {
+			admins[id] = role
+		}
+	}
+	return admins
+}
+
+// RoleOf возвращает роль пользователя Telegram; ADMIN_TG всегда владелец
+func (c *Config) RoleOf(userID int64) Role {
+	if c.AdminTG != 0 && userID == c.AdminTG {
+		return RoleOwner
+	}
+	return c.Admins[userID]
+}
+
+// AdminIDs возвращает всех пользователей с доступом к боту, начиная с владельца
+func (c *Config) AdminIDs() []int64 {
+	var ids []int64
+	if c.AdminTG != 0 {
+		ids = append(ids, c.AdminTG)
+	}
+	for id := range c.Admins {
+		if id != c.AdminTG {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// IsAllowed сообщает, может ли пользователь подключиться без кода приглашения
+func (c *Config) IsAllowed(userID int64) bool {
+	if c.RoleOf(userID) != RoleNone {
+		return true
+	}
+	for _, allowed := range c.AllowedUsers {
+		if allowed == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// loadAccounts читает аккаунты из HH_ACCOUNTS=main,partner.
+// Для каждого имени берутся HH_ACCOUNT_<ИМЯ>_LOGIN, _PASSWORD и _PROXY;
+// аккаунт main по умолчанию использует HH_LOGIN, HH_PASSWORD и PROXY.
+func loadAccounts(cfg *Config) []Account {
+	names := []string{DefaultAccount}
+	if value := getEnv("HH_ACCOUNTS", ""); value != "" {
+		names = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var accounts []Account
+	for _, name := range names {
+		prefix := "HH_ACCOUNT_" + strings.ToUpper(name) + "_"
+		account := Account{
+			Name:     name,
+			Login:    getEnv(prefix+"LOGIN", ""),
+			Password: getEnv(prefix+"PASSWORD", ""),
+			Proxy:    getEnv(prefix+"PROXY", cfg.Proxy),
+		}
+		if name == DefaultAccount && account.Login == "" {
+			account.Login, account.Password = cfg.HHLogin, cfg.HHPassword
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// FindAccount возвращает аккаунт по имени
+func (c *Config) FindAccount(name string) (Account, bool) {
+	for _, account := range c.Accounts {
+		if account.Name == name {
+			return account, true
+		}
+	}
+	return Account{}, false
+}
+
+// SchedulerAccount возвращает имя аккаунта для планировщика и хранилища:
+// у аккаунта по умолчанию (первого в списке) оно пустое, чтобы старые данные оставались его
+func (c *Config) SchedulerAccount(name string) string {
+	if len(c.Accounts) > 0 && c.Accounts[0].Name == name {
+		return ""
+	}
+	return name
 }
 
 func getEnv(key, defaultValue string) string {
@@ -39,4 +229,32 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 		}
 	}
 	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvInt64List(key string) []int64 {
+	var values []int64
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if intValue, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			values = append(values, intValue)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// NotifyFilter - какие события получает канал уведомлений
+type NotifyFilter struct {
+	Events []string // типы событий или категории (raise, pause, session); пусто - все
+	Level  string   // минимальная важность: info, warning или error
+}
+
+// SMTPConfig - отправка уведомлений письмом; канал включен, если задан Host
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+	Filter   NotifyFilter
+}
+
+// EndpointConfig - канал, которому нужен только адрес и, возможно, токен:
+// вебхук, Slack, ntfy или Gotify. Канал включен, если задан URL.
+type EndpointConfig struct {
+	URL    string
+	Token  string
+	Secret string // ключ подписи HMAC-SHA256 (только для вебхука)
+	Filter NotifyFilter
+}
+
+// NotifyConfig - каналы уведомлений; Telegram включен всегда
+type NotifyConfig struct {
+	Telegram NotifyFilter
+	SMTP     SMTPConfig
+	Webhook  EndpointConfig
+	Slack    EndpointConfig
+	Ntfy     EndpointConfig
+	Gotify   EndpointConfig
+}
+
+// loadNotify читает каналы уведомлений из NOTIFY_<КАНАЛ>_*.
+// Фильтр канала задается через NOTIFY_<КАНАЛ>_EVENTS и NOTIFY_<КАНАЛ>_LEVEL.
+func loadNotify() NotifyConfig {
+	return NotifyConfig{
+		Telegram: loadNotifyFilter("TELEGRAM"),
+		SMTP: SMTPConfig{
+			Host:     getEnv("NOTIFY_SMTP_HOST", ""),
+			Port:     int(getEnvInt64("NOTIFY_SMTP_PORT", 587)),
+			Username: getEnv("NOTIFY_SMTP_USERNAME", ""),
+			Password: getEnv("NOTIFY_SMTP_PASSWORD", ""),
+			From:     getEnv("NOTIFY_SMTP_FROM", ""),
+			To:       getEnvList("NOTIFY_SMTP_TO"),
+			Filter:   loadNotifyFilter("SMTP"),
+		},
+		Webhook: loadEndpoint("WEBHOOK"),
+		Slack:   loadEndpoint("SLACK"),
+		Ntfy:    loadEndpoint("NTFY"),
+		Gotify:  loadEndpoint("GOTIFY"),
+	}
+}
+
+func loadEndpoint(channel string) EndpointConfig {
+	return EndpointConfig{
+		URL:    getEnv("NOTIFY_"+channel+"_URL", ""),
+		Token:  getEnv("NOTIFY_"+channel+"_TOKEN", ""),
+		Secret: getEnv("NOTIFY_"+channel+"_SECRET", ""),
+		Filter: loadNotifyFilter(channel),
+	}
+}
+
+func loadNotifyFilter(channel string) NotifyFilter {
+	return NotifyFilter{
+		Events: getEnvList("NOTIFY_" + channel + "_EVENTS"),
+		Level:  getEnv("NOTIFY_"+channel+"_LEVEL", "info"),
+	}
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,31 @@
+package config
+
+// TelegramConfig - подключение к Telegram Bot API и способ получения обновлений
+type TelegramConfig struct {
+	APIURL  string // адрес Bot API, например локального сервера; пусто - https://api.telegram.org
+	Webhook TelegramWebhook
+}
+
+// TelegramWebhook - получение обновлений через вебхук вместо long polling.
+// Вебхук включен, если задан URL.
+type TelegramWebhook struct {
+	URL      string // публичный адрес, на который Telegram отправляет обновления
+	Listen   string // адрес HTTP-сервера бота
+	Secret   string // секретный токен запросов Telegram; пусто - создается при запуске
+	CertFile string // сертификат и ключ для HTTPS; без них сервер работает по HTTP
+	KeyFile  string // (TLS завершается на ingress)
+}
+
+// loadTelegram читает TELEGRAM_API_URL и TELEGRAM_WEBHOOK_*
+func loadTelegram() TelegramConfig {
+	return TelegramConfig{
+		APIURL: getEnv("TELEGRAM_API_URL", ""),
+		Webhook: TelegramWebhook{
+			URL:      getEnv("TELEGRAM_WEBHOOK_URL", ""),
+			Listen:   getEnv("TELEGRAM_WEBHOOK_LISTEN", ":8443"),
+			Secret:   getEnv("TELEGRAM_WEBHOOK_SECRET", ""),
+			CertFile: getEnv("TELEGRAM_WEBHOOK_CERT", ""),
+			KeyFile:  getEnv("TELEGRAM_WEBHOOK_KEY", ""),
+		},
+	}
+}